@@ -4,5 +4,8 @@ go 1.21
 
 require (
 	github.com/joho/godotenv v1.5.1
+	golang.org/x/term v0.15.0
 	gopkg.in/telebot.v3 v3.1.4
 )
+
+require golang.org/x/sys v0.15.0 // indirect
@@ -0,0 +1,130 @@
+// Package api содержит выпуск ключей API Yougile по логину и паролю и
+// обнаружение отозванных ключей.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"yougile_bot4/internal/models"
+)
+
+// authPathPrefix — эндпоинты авторизации по логину и паролю. Ответ 401 на них
+// означает неверные учётные данные, а не отозванный ключ бота.
+const authPathPrefix = "/api-v2/auth/"
+
+// authPageSize — размер страницы списка компаний пользователя.
+const authPageSize = 100
+
+// Credentials — логин и пароль пользователя Yougile.
+type Credentials struct {
+	Login    string
+	Password string
+}
+
+// payload возвращает тело запроса к эндпоинтам авторизации.
+func (cr Credentials) payload(companyID string) map[string]string {
+	p := map[string]string{"login": cr.Login, "password": cr.Password}
+	if companyID != "" {
+		p["companyId"] = companyID
+	}
+	return p
+}
+
+// ListCompanies возвращает компании, доступные пользователю cr. Непустой name
+// оставляет только компании с таким названием.
+func (c *Client) ListCompanies(ctx context.Context, cr Credentials, name string) ([]models.Company, error) {
+	payload := cr.payload("")
+	if name != "" {
+		payload["name"] = name
+	}
+	var companies []models.Company
+	for offset := 0; ; offset += authPageSize {
+		var page struct {
+			Paging  Paging           `json:"paging"`
+			Content []models.Company `json:"content"`
+		}
+		path := fmt.Sprintf("%scompanies?limit=%d&offset=%d", authPathPrefix, authPageSize, offset)
+		if err := c.requestJSON(ctx, http.MethodPost, path, payload, &page); err != nil {
+			return nil, fmt.Errorf("ошибка получения списка компаний: %w", err)
+		}
+		companies = append(companies, page.Content...)
+		if !page.Paging.Next || len(page.Content) == 0 {
+			return companies, nil
+		}
+	}
+}
+
+// ListAuthKeys возвращает действующие ключи API пользователя cr в компании companyID.
+func (c *Client) ListAuthKeys(ctx context.Context, cr Credentials, companyID string) ([]models.AuthKey, error) {
+	var list []models.AuthKey
+	if err := c.requestJSON(ctx, http.MethodPost, authPathPrefix+"keys/get", cr.payload(companyID), &list); err != nil {
+		return nil, fmt.Errorf("ошибка получения ключей: %w", err)
+	}
+	keys := make([]models.AuthKey, 0, len(list))
+	for _, k := range list {
+		if !k.Deleted {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+// CreateAuthKey выпускает новый ключ API пользователя cr для компании companyID.
+func (c *Client) CreateAuthKey(ctx context.Context, cr Credentials, companyID string) (string, error) {
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := c.requestJSON(ctx, http.MethodPost, authPathPrefix+"keys", cr.payload(companyID), &created); err != nil {
+		return "", fmt.Errorf("ошибка создания ключа: %w", err)
+	}
+	if created.Key == "" {
+		return "", fmt.Errorf("ошибка создания ключа: пустой ключ в ответе")
+	}
+	return created.Key, nil
+}
+
+// DeleteAuthKey отзывает ключ API key.
+func (c *Client) DeleteAuthKey(ctx context.Context, key string) error {
+	if err := c.requestJSON(ctx, http.MethodDelete, authPathPrefix+"keys/"+url.PathEscape(key), nil, nil); err != nil {
+		return fmt.Errorf("ошибка удаления ключа: %w", err)
+	}
+	return nil
+}
+
+// CheckKey проверяет ключ клиента запросом данных компании. Отозванный ключ
+// возвращает ошибку, сопоставимую с ErrUnauthorized.
+func (c *Client) CheckKey(ctx context.Context) error {
+	var company json.RawMessage
+	if err := c.requestJSON(ctx, http.MethodGet, "/api-v2/companies", nil, &company); err != nil {
+		return fmt.Errorf("ошибка проверки ключа: %w", err)
+	}
+	return nil
+}
+
+// SetUnauthorizedHandler задаёт функцию, вызываемую при каждом ответе 401 на
+// запрос с ключом клиента (кроме эндпоинтов авторизации). Функция вызывается
+// в отдельной горутине и не задерживает запрос; nil отключает уведомления.
+func (c *Client) SetUnauthorizedHandler(h func(error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onUnauthorized = h
+}
+
+// reportUnauthorized передаёт обработчику отказ Yougile в доступе по ключу.
+func (c *Client) reportUnauthorized(req *http.Request) {
+	if strings.HasPrefix(req.URL.Path, authPathPrefix) {
+		return
+	}
+	c.mu.RLock()
+	h := c.onUnauthorized
+	c.mu.RUnlock()
+	if h == nil {
+		return
+	}
+	go h(fmt.Errorf("%w: %s %s", ErrUnauthorized, req.Method, req.URL.Path))
+}
@@ -0,0 +1,143 @@
+// Package api содержит автоматический выключатель (circuit breaker) клиента Yougile.
+package api
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen возвращается без обращения к сети, пока выключатель разомкнут.
+// Такая ошибка также сопоставляется с ErrUnavailable.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// Параметры выключателя по умолчанию.
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+// breakerState — состояние выключателя.
+type breakerState int
+
+const (
+	breakerClosed   breakerState = iota // запросы выполняются
+	breakerOpen                         // запросы отклоняются до истечения cooldown
+	breakerHalfOpen                     // выполняется пробный запрос
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+// circuitBreaker размыкается после threshold подряд неудачных обращений
+// (сетевые ошибки и ответы 5xx). Через cooldown пропускается один пробный
+// запрос: успех замыкает выключатель, неудача снова размыкает его.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     breakerState
+	failures  int
+	openedAt  time.Time
+	now       func() time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// allow сообщает, можно ли выполнить запрос, и при необходимости переводит
+// выключатель в полуоткрытое состояние.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		// пробный запрос уже выполняется
+		return false
+	}
+	return true
+}
+
+// success фиксирует успешное обращение к серверу.
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = breakerClosed
+	b.failures = 0
+}
+
+// failure фиксирует недоступность сервера.
+func (b *circuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.state = breakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// release возвращает выключатель из полуоткрытого состояния, если пробный
+// запрос был отменён вызывающим и не дал результата.
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == breakerHalfOpen {
+		b.state = breakerOpen
+	}
+}
+
+// current возвращает состояние и момент размыкания.
+func (b *circuitBreaker) current() (breakerState, time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state, b.openedAt
+}
+
+// SetCircuitBreaker настраивает выключатель: threshold подряд неудачных обращений
+// размыкают его на cooldown. threshold <= 0 отключает выключатель.
+func (c *Client) SetCircuitBreaker(threshold int, cooldown time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if threshold <= 0 {
+		c.breaker = nil
+		return
+	}
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCooldown
+	}
+	c.breaker = newCircuitBreaker(threshold, cooldown)
+}
+
+// BreakerState возвращает состояние выключателя ("closed", "open", "half-open").
+func (c *Client) BreakerState() string {
+	c.mu.RLock()
+	b := c.breaker
+	c.mu.RUnlock()
+	if b == nil {
+		return breakerClosed.String()
+	}
+	state, _ := b.current()
+	return state.String()
+}
+
+// IsUnavailable сообщает, что Yougile временно не принимает запросы
+// (сеть, 5xx, разомкнутый выключатель или превышение лимита) и операцию
+// имеет смысл отложить и повторить позже.
+func IsUnavailable(err error) bool {
+	return errors.Is(err, ErrUnavailable) || errors.Is(err, ErrRateLimit)
+}
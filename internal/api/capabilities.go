@@ -0,0 +1,391 @@
+// Package api содержит механизм согласования эндпоинтов списка задач Yougile.
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"yougile_bot4/internal/models"
+)
+
+// CapabilityStore сохраняет обнаруженные возможности API между перезапусками бота.
+// Реализуется хранилищем приложения (storage.Storage).
+type CapabilityStore interface {
+	GetAPICapabilities() models.APICapabilities
+	SetAPICapabilities(caps models.APICapabilities)
+}
+
+// listStrategy описывает один вариант запроса списка задач.
+// Разные инсталляции Yougile поддерживают разные пути и имена параметров,
+// поэтому клиент перебирает стратегии по порядку и запоминает первую рабочую.
+type listStrategy struct {
+	name string
+	// applicable сообщает, имеет ли смысл стратегия для заданных доски и колонки
+	applicable func(board, column string) bool
+	// build формирует HTTP-запрос для стратегии
+	build func(ctx context.Context, c *Client, q listQuery) (*http.Request, error)
+}
+
+// listQuery описывает параметры одного запроса страницы списка задач.
+type listQuery struct {
+	board  string
+	column string
+	// title — фильтр по названию; передаётся только эндпоинтам, которые его поддерживают
+	title  string
+	limit  int
+	offset int
+}
+
+// pageParams добавляет в запрос параметры размера страницы и смещения.
+// offset передаётся только для страниц после первой, чтобы первый запрос
+// оставался совместимым с инсталляциями, не знающими этого параметра.
+func (q listQuery) pageParams(v url.Values) {
+	v.Set("limit", strconv.Itoa(q.limit))
+	if q.offset > 0 {
+		v.Set("offset", strconv.Itoa(q.offset))
+	}
+}
+
+// titleParam добавляет в запрос фильтр по названию, если он задан.
+func (q listQuery) titleParam(v url.Values) {
+	if q.title != "" {
+		v.Set("title", q.title)
+	}
+}
+
+// listStrategies перечисляет варианты запроса списка задач в порядке предпочтения.
+var listStrategies = []listStrategy{
+	{
+		// Основной эндпоинт. При заданной колонке boardId не передаём:
+		// некоторые инсталляции отвечают 400 на комбинацию boardId+columnId.
+		name:       "tasks_get",
+		applicable: func(string, string) bool { return true },
+		build: func(ctx context.Context, c *Client, q listQuery) (*http.Request, error) {
+			v := url.Values{}
+			q.pageParams(v)
+			if q.column != "" {
+				v.Set("columnId", q.column)
+			} else if q.board != "" {
+				v.Set("boardId", q.board)
+			}
+			q.titleParam(v)
+			return c.newListRequest(ctx, "GET", "/api-v2/tasks", v, nil)
+		},
+	},
+	{
+		// Новые инсталляции используют /api-v2/task-list
+		name:       "task_list_get",
+		applicable: func(string, string) bool { return true },
+		build: func(ctx context.Context, c *Client, q listQuery) (*http.Request, error) {
+			v := url.Values{}
+			if q.column != "" {
+				v.Set("columnId", q.column)
+			} else if q.board != "" {
+				v.Set("boardId", q.board)
+			}
+			q.pageParams(v)
+			q.titleParam(v)
+			return c.newListRequest(ctx, "GET", "/api-v2/task-list", v, nil)
+		},
+	},
+	{
+		// Путь в рамках доски; с колонкой не комбинируется
+		name:       "board_tasks_get",
+		applicable: func(board, column string) bool { return board != "" && column == "" },
+		build: func(ctx context.Context, c *Client, q listQuery) (*http.Request, error) {
+			v := url.Values{}
+			q.pageParams(v)
+			return c.newListRequest(ctx, "GET", "/api-v2/board/"+url.PathEscape(q.board)+"/tasks", v, nil)
+		},
+	},
+	{
+		// Некоторые инсталляции ожидают параметры в теле POST-запроса
+		name:       "task_list_post",
+		applicable: func(string, string) bool { return true },
+		build: func(ctx context.Context, c *Client, q listQuery) (*http.Request, error) {
+			body := map[string]interface{}{"limit": q.limit}
+			if q.offset > 0 {
+				body["offset"] = q.offset
+			}
+			if q.column != "" {
+				body["columnId"] = q.column
+			} else if q.board != "" {
+				body["boardId"] = q.board
+			}
+			if q.title != "" {
+				body["title"] = q.title
+			}
+			data, err := json.Marshal(body)
+			if err != nil {
+				return nil, fmt.Errorf("ошибка сериализации тела запроса: %w", err)
+			}
+			return c.newListRequest(ctx, "POST", "/api-v2/task-list", nil, data)
+		},
+	},
+	legacyStrategy("tasks_get_unscoped", "/api-v2/tasks", ""),
+	legacyStrategy("tasks_get_board_param", "/api-v2/tasks", "board"),
+	legacyStrategy("legacy_tasks", "/api/tasks", ""),
+	legacyStrategy("legacy_tasks_board_id", "/api/tasks", "boardId"),
+	legacyStrategy("legacy_tasks_board", "/api/tasks", "board"),
+	legacyStrategy("legacy_task_list_board_id", "/api/task-list", "boardId"),
+	legacyStrategy("legacy_task_list_board", "/api/task-list", "board"),
+	legacyStrategy("legacy_tasks_list_board_id", "/api/tasks/list", "boardId"),
+}
+
+// legacyStrategy создаёт стратегию для устаревших GET-эндпоинтов.
+// boardParam — имя параметра доски; пустое значение означает запрос без доски.
+func legacyStrategy(name, path, boardParam string) listStrategy {
+	return listStrategy{
+		name: name,
+		applicable: func(board, _ string) bool {
+			return boardParam == "" || board != ""
+		},
+		build: func(ctx context.Context, c *Client, q listQuery) (*http.Request, error) {
+			v := url.Values{}
+			if boardParam != "" {
+				v.Set(boardParam, q.board)
+			}
+			q.pageParams(v)
+			return c.newListRequest(ctx, "GET", path, v, nil)
+		},
+	}
+}
+
+// findListStrategy возвращает стратегию по имени.
+func findListStrategy(name string) (listStrategy, bool) {
+	for _, s := range listStrategies {
+		if s.name == name {
+			return s, true
+		}
+	}
+	return listStrategy{}, false
+}
+
+// listStatusError означает, что стратегия не подходит для данной инсталляции
+// (ответ 4xx или неожиданный формат) и стоит попробовать следующую.
+// err — исходная ошибка, обычно *APIError.
+type listStatusError struct {
+	strategy string
+	err      error
+}
+
+func (e *listStatusError) Error() string {
+	return fmt.Sprintf("стратегия %s: %v", e.strategy, e.err)
+}
+
+func (e *listStatusError) Unwrap() error {
+	return e.err
+}
+
+// capabilityScope возвращает ключ области, для которой кэшируется стратегия.
+func capabilityScope(board, column string) string {
+	return "board=" + board + ";column=" + column
+}
+
+// newListRequest создаёт запрос к API со стандартными заголовками.
+func (c *Client) newListRequest(ctx context.Context, method, path string, query url.Values, body []byte) (*http.Request, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var rdr io.Reader
+	if body != nil {
+		rdr = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, rdr)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+// SetCapabilityStore подключает хранилище возможностей API и загружает из него
+// ранее обнаруженные стратегии, чтобы не повторять опрос после перезапуска.
+func (c *Client) SetCapabilityStore(store CapabilityStore) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.capStore = store
+	if store == nil {
+		return
+	}
+	c.caps = make(models.APICapabilities)
+	for k, v := range store.GetAPICapabilities() {
+		if _, ok := findListStrategy(v.Strategy); ok {
+			c.caps[k] = v
+		}
+	}
+}
+
+// Capabilities возвращает копию обнаруженных возможностей API.
+func (c *Client) Capabilities() models.APICapabilities {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	result := make(models.APICapabilities, len(c.caps))
+	for k, v := range c.caps {
+		result[k] = v
+	}
+	return result
+}
+
+// ResetCapabilities сбрасывает обнаруженные стратегии; следующий запрос списка
+// задач выполнит опрос эндпоинтов заново.
+func (c *Client) ResetCapabilities() {
+	c.mu.Lock()
+	c.caps = make(models.APICapabilities)
+	c.mu.Unlock()
+	c.persistCapabilities()
+}
+
+// persistCapabilities передаёт текущие возможности в хранилище (если оно подключено).
+func (c *Client) persistCapabilities() {
+	c.mu.RLock()
+	store := c.capStore
+	snapshot := make(models.APICapabilities, len(c.caps))
+	for k, v := range c.caps {
+		snapshot[k] = v
+	}
+	c.mu.RUnlock()
+	if store != nil {
+		store.SetAPICapabilities(snapshot)
+	}
+}
+
+// listPage получает страницу списка задач, используя закэшированную стратегию для
+// области q.board/q.column. Если стратегия ещё не известна или перестала работать
+// (ответ 4xx или неожиданный формат), выполняется повторный опрос всех стратегий
+// по порядку. Временные ошибки (сеть, 5xx, 429) не приводят к повторному опросу.
+func (c *Client) listPage(ctx context.Context, q listQuery) ([]models.Task, *Paging, error) {
+	scope := capabilityScope(q.board, q.column)
+	c.mu.RLock()
+	cached, hasCached := c.caps[scope]
+	c.mu.RUnlock()
+
+	if hasCached {
+		if s, ok := findListStrategy(cached.Strategy); ok {
+			tasks, paging, err := c.tryListStrategy(ctx, s, q)
+			if err == nil {
+				return tasks, paging, nil
+			}
+			var se *listStatusError
+			if !errors.As(err, &se) {
+				return nil, nil, err
+			}
+			log.Printf("GetTasks: стратегия %s перестала работать (%v), повторяем опрос эндпоинтов", s.name, err)
+			c.mu.Lock()
+			delete(c.caps, scope)
+			c.mu.Unlock()
+		}
+	}
+
+	return c.probeListStrategies(ctx, q)
+}
+
+// probeListStrategies перебирает стратегии и сохраняет первую успешную.
+func (c *Client) probeListStrategies(ctx context.Context, q listQuery) ([]models.Task, *Paging, error) {
+	var lastErr error
+	probes := 0
+	for _, s := range listStrategies {
+		if !s.applicable(q.board, q.column) {
+			continue
+		}
+		probes++
+		if c.verbose {
+			log.Printf("GetTasks: проверяем стратегию %s", s.name)
+		}
+		tasks, paging, err := c.tryListStrategy(ctx, s, q)
+		if err == nil {
+			log.Printf("GetTasks: выбрана стратегия %s для доски %q колонки %q (проб: %d)", s.name, q.board, q.column, probes)
+			c.mu.Lock()
+			if c.caps == nil {
+				c.caps = make(models.APICapabilities)
+			}
+			c.caps[capabilityScope(q.board, q.column)] = models.ListCapability{
+				Strategy:     s.name,
+				BoardID:      q.board,
+				ColumnID:     q.column,
+				DiscoveredAt: time.Now(),
+				Probes:       probes,
+			}
+			c.mu.Unlock()
+			c.persistCapabilities()
+			return tasks, paging, nil
+		}
+		var se *listStatusError
+		if !errors.As(err, &se) {
+			// временная ошибка после всех повторов или недействительный ключ: опрос не продолжаем
+			return nil, nil, err
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("нет подходящих стратегий получения списка задач")
+	}
+	return nil, nil, lastErr
+}
+
+// tryListStrategy выполняет запрос по одной стратегии с повторами для временных ошибок.
+// Ответы 4xx (кроме 401) и ответы неожиданного формата возвращаются как *listStatusError;
+// 401 и временные ошибки после всех повторов — как *APIError.
+func (c *Client) tryListStrategy(ctx context.Context, s listStrategy, q listQuery) ([]models.Task, *Paging, error) {
+	var tasks []models.Task
+	var paging *Paging
+	err := c.retryOperation(ctx, func() (bool, error) {
+		req, err := s.build(ctx, c, q)
+		if err != nil {
+			return true, err
+		}
+		if c.verbose {
+			log.Printf("GetTasks request %s %s", req.Method, req.URL.String())
+		}
+		resp, err := c.do(req)
+		if err != nil {
+			return false, fmt.Errorf("ошибка выполнения запроса: %w", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if cerr := resp.Body.Close(); cerr != nil {
+			log.Printf("Ошибка закрытия тела ответа в GetTasks: %v", cerr)
+		}
+
+		if resp.StatusCode == http.StatusOK {
+			parsed, pg, derr := decodeTaskList(body)
+			if derr != nil {
+				return true, &listStatusError{strategy: s.name, err: derr}
+			}
+			tasks, paging = parsed, pg
+			return true, nil
+		}
+		apiErr := newAPIError(req, resp, body)
+		if apiErr.Retryable {
+			return false, apiErr
+		}
+		if resp.StatusCode == http.StatusUnauthorized {
+			// ключ недействителен: другие стратегии ответят так же, опрос не продолжаем
+			return true, apiErr
+		}
+
+		if c.verbose {
+			// полный обмен для офлайн-разбора сохраняет запись кассеты (YOUGILE_CASSETTE_RECORD)
+			log.Printf("GetTasks: стратегия %s вернула status=%d body=%s", s.name, resp.StatusCode, strings.TrimSpace(string(body)))
+		}
+		return true, &listStatusError{strategy: s.name, err: apiErr}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return tasks, paging, nil
+}
@@ -0,0 +1,324 @@
+// Package api содержит запись HTTP-обмена клиента с Yougile в кассеты и их
+// воспроизведение без сети — для диагностики и регрессионных тестов.
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// redacted заменяет в кассете токены и прочие секреты.
+const redacted = "REDACTED"
+
+// ErrNoInteraction возвращается при воспроизведении, если в кассете не осталось
+// записи для запроса.
+var ErrNoInteraction = errors.New("в кассете нет записи для запроса")
+
+// sensitiveHeaders — заголовки, значения которых не попадают в кассету.
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Webhook-Token"}
+
+// RecordedRequest — запрос в кассете. URL хранится без схемы и хоста.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+	// BodyBase64 — тело в base64, если оно не является текстом UTF-8 (например, файл).
+	BodyBase64 string `json:"body_base64,omitempty"`
+}
+
+// RecordedResponse — ответ в кассете.
+type RecordedResponse struct {
+	Status     int         `json:"status"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	BodyBase64 string      `json:"body_base64,omitempty"`
+}
+
+// Interaction — пара запрос/ответ.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// Cassette — последовательность обменов с Yougile в порядке выполнения.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// LoadCassette читает кассету из файла: целиком сохранённую (Save) или
+// записанную Recorder, где каждый обмен — отдельная строка JSON.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения кассеты: %w", err)
+	}
+	var cassette Cassette
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var value struct {
+			Interactions []Interaction `json:"interactions"`
+			Interaction
+		}
+		if err := dec.Decode(&value); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("ошибка разбора кассеты %s: %w", path, err)
+		}
+		if value.Interactions != nil {
+			cassette.Interactions = append(cassette.Interactions, value.Interactions...)
+		} else {
+			cassette.Interactions = append(cassette.Interactions, value.Interaction)
+		}
+	}
+	return &cassette, nil
+}
+
+// Save записывает кассету в файл.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации кассеты: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("ошибка записи кассеты: %w", err)
+	}
+	return nil
+}
+
+// Recorder — http.RoundTripper, который выполняет запросы через next и
+// дописывает каждый обмен в кассету отдельной строкой JSON сразу после ответа,
+// чтобы запись не терялась при аварийном завершении, а стоимость записи не росла
+// с размером кассеты. Секреты (заголовок Authorization и переданные строки)
+// заменяются на REDACTED.
+type Recorder struct {
+	path    string
+	next    http.RoundTripper
+	secrets []string
+
+	mu      sync.Mutex
+	file    *os.File
+	started bool // файл уже очищен для этой записи
+}
+
+// NewRecorder создаёт запись в файл path. next — транспорт для реальных запросов
+// (nil — http.DefaultTransport); secrets — строки, вырезаемые из URL, заголовков
+// и тел (обычно ключ API).
+func NewRecorder(path string, next http.RoundTripper, secrets ...string) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	r := &Recorder{path: path, next: next}
+	for _, s := range secrets {
+		if s != "" {
+			r.secrets = append(r.secrets, s)
+		}
+	}
+	return r
+}
+
+// RoundTrip выполняет запрос и записывает обмен.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if reqBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		// сетевые ошибки не записываются: воспроизводить нечего
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	in := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    r.redact(req.URL.RequestURI()),
+			Header: r.redactHeader(req.Header),
+		},
+		Response: RecordedResponse{
+			Status: resp.StatusCode,
+			Header: r.redactHeader(resp.Header),
+		},
+	}
+	in.Request.Body, in.Request.BodyBase64 = r.encodeBody(reqBody)
+	in.Response.Body, in.Response.BodyBase64 = r.encodeBody(respBody)
+
+	if err := r.append(in); err != nil {
+		// запись кассеты — диагностика: её сбой не должен ломать запрос
+		log.Printf("cassette recorder: %v", err)
+	}
+	return resp, nil
+}
+
+// append дописывает обмен в файл кассеты. Файл создаётся (или очищается) при
+// первой записи и остаётся открытым до Close.
+func (r *Recorder) append(in Interaction) error {
+	line, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации кассеты: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		flag := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		if !r.started {
+			flag |= os.O_TRUNC
+		}
+		f, err := os.OpenFile(r.path, flag, 0600)
+		if err != nil {
+			return fmt.Errorf("ошибка записи кассеты: %w", err)
+		}
+		r.file, r.started = f, true
+	}
+	if _, err := r.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("ошибка записи кассеты: %w", err)
+	}
+	return nil
+}
+
+// Close закрывает файл кассеты; следующий обмен снова откроет его и допишет в конец.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// redact заменяет секреты в строке.
+func (r *Recorder) redact(s string) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	return s
+}
+
+// redactHeader копирует заголовки, скрывая чувствительные значения.
+func (r *Recorder) redactHeader(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	out := make(http.Header, len(h))
+	for k, values := range h {
+		for _, v := range values {
+			out.Add(k, r.redact(v))
+		}
+	}
+	for _, k := range sensitiveHeaders {
+		if out.Get(k) != "" {
+			out.Set(k, redacted)
+		}
+	}
+	return out
+}
+
+// encodeBody возвращает тело как текст или, для двоичных данных, как base64.
+func (r *Recorder) encodeBody(body []byte) (string, string) {
+	if len(body) == 0 {
+		return "", ""
+	}
+	if utf8.Valid(body) {
+		return r.redact(string(body)), ""
+	}
+	return "", base64.StdEncoding.EncodeToString(body)
+}
+
+// Replayer — http.RoundTripper, отвечающий из кассеты без обращения к сети.
+// Запрос сопоставляется с первой неиспользованной записью с тем же методом
+// и путём с параметрами (хост не учитывается); каждая запись отвечает один раз,
+// поэтому повторы одного запроса воспроизводятся в записанном порядке.
+type Replayer struct {
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// NewReplayer создаёт воспроизведение кассеты.
+func NewReplayer(cassette *Cassette) *Replayer {
+	return &Replayer{cassette: cassette, used: make([]bool, len(cassette.Interactions))}
+}
+
+// RoundTrip возвращает записанный ответ или ErrNoInteraction.
+func (p *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+	uri := req.URL.RequestURI()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, in := range p.cassette.Interactions {
+		if p.used[i] || in.Request.Method != req.Method || in.Request.URL != uri {
+			continue
+		}
+		p.used[i] = true
+		body := []byte(in.Response.Body)
+		if in.Response.BodyBase64 != "" {
+			decoded, err := base64.StdEncoding.DecodeString(in.Response.BodyBase64)
+			if err != nil {
+				return nil, fmt.Errorf("ошибка декодирования тела в кассете: %w", err)
+			}
+			body = decoded
+		}
+		header := in.Response.Header.Clone()
+		if header == nil {
+			header = make(http.Header)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.Status, http.StatusText(in.Response.Status)),
+			StatusCode:    in.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, uri)
+}
+
+// Remaining возвращает число записей кассеты, которые ещё не воспроизведены.
+func (p *Replayer) Remaining() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for _, used := range p.used {
+		if !used {
+			n++
+		}
+	}
+	return n
+}
+
+// SetTransport заменяет транспорт HTTP-клиента (например, на Recorder или Replayer).
+// nil возвращает http.DefaultTransport. Вызывается до первого запроса.
+func (c *Client) SetTransport(rt http.RoundTripper) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.httpClient.Transport = rt
+}
@@ -0,0 +1,89 @@
+// Package api содержит чтение и отправку сообщений чатов задач Yougile.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"yougile_bot4/internal/models"
+)
+
+// ListChatMessages возвращает сообщения чата chatID (для задачи — её ID),
+// созданные позже since (нулевое время — вся история), в порядке создания.
+// Удалённые и системные сообщения не возвращаются.
+func (c *Client) ListChatMessages(ctx context.Context, chatID string, since time.Time) ([]models.ChatMessage, error) {
+	params := url.Values{}
+	if !since.IsZero() {
+		params.Set("since", strconv.FormatInt(since.UnixMilli(), 10))
+	}
+	var messages []models.ChatMessage
+	err := c.listAll(ctx, "/api-v2/chats/"+url.PathEscape(chatID)+"/messages", params, func(content json.RawMessage) (int, error) {
+		var page []models.ChatMessage
+		if err := json.Unmarshal(content, &page); err != nil {
+			return 0, err
+		}
+		for _, m := range page {
+			if !m.Deleted {
+				messages = append(messages, m)
+			}
+		}
+		return len(page), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения сообщений чата %s: %w", chatID, err)
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].ID < messages[j].ID })
+	return messages, nil
+}
+
+// SendChatMessage отправляет текстовое сообщение в чат chatID и возвращает ID сообщения.
+func (c *Client) SendChatMessage(ctx context.Context, chatID, text string) (int64, error) {
+	return c.sendChatHTML(ctx, chatID, text, strings.ReplaceAll(html.EscapeString(text), "\n", "<br>"))
+}
+
+// sendChatHTML отправляет в чат сообщение с текстом text и его HTML-представлением textHTML.
+func (c *Client) sendChatHTML(ctx context.Context, chatID, text, textHTML string) (int64, error) {
+	path := "/api-v2/chats/" + url.PathEscape(chatID) + "/messages"
+	data, err := json.Marshal(map[string]string{"text": text, "textHtml": textHTML, "label": ""})
+	if err != nil {
+		return 0, fmt.Errorf("ошибка сериализации сообщения: %w", err)
+	}
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	err = c.retryOperation(ctx, func() (bool, error) {
+		req, err := c.newListRequest(ctx, http.MethodPost, path, nil, data)
+		if err != nil {
+			return true, err
+		}
+		resp, err := c.do(req)
+		if err != nil {
+			return false, fmt.Errorf("ошибка выполнения запроса: %w", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if cerr := resp.Body.Close(); cerr != nil {
+			log.Printf("Ошибка закрытия тела ответа в sendChatHTML: %v", cerr)
+		}
+		if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+			apiErr := newAPIError(req, resp, body)
+			return !apiErr.Retryable, apiErr
+		}
+		// ID сообщения необязателен для вызывающего: ответ без него не считаем ошибкой
+		_ = json.Unmarshal(body, &created)
+		return true, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return created.ID, nil
+}
@@ -0,0 +1,139 @@
+// Package api содержит модель ошибок Yougile API.
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	// ErrUnauthorized возвращается при ошибке авторизации с API Yougile
+	// (ключ недействителен или отозван).
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrNotFound возвращается, когда запрашиваемый ресурс не найден в Yougile API.
+	ErrNotFound = errors.New("not found")
+	// ErrRateLimit возвращается при превышении лимита запросов к API.
+	ErrRateLimit = errors.New("rate limit exceeded")
+	// ErrUnavailable возвращается, когда Yougile отвечает ошибкой сервера (5xx).
+	ErrUnavailable = errors.New("service unavailable")
+	// ErrConflict возвращается, когда изменяемые поля задачи уже изменены в Yougile
+	// после того, как задача была прочитана (см. TaskUpdate.Base).
+	ErrConflict = errors.New("conflict")
+	// ErrUncertain возвращается, когда запрос на изменение отправлен, но ответ
+	// не получен за отведённое время: сервер мог его уже выполнить, поэтому
+	// повторять запрос небезопасно.
+	ErrUncertain = errors.New("result unknown")
+)
+
+// ConflictError перечисляет поля задачи, изменённые в Yougile после чтения.
+// Через errors.Is сопоставляется с ErrConflict.
+type ConflictError struct {
+	TaskID string
+	Fields []string
+}
+
+// Error возвращает описание конфликта для логов.
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("задача %s изменена в Yougile после чтения: %s", e.TaskID, strings.Join(e.Fields, ", "))
+}
+
+// Unwrap возвращает ErrConflict.
+func (e *ConflictError) Unwrap() error {
+	return ErrConflict
+}
+
+// maxErrorBody ограничивает размер тела ответа, сохраняемого в APIError.
+const maxErrorBody = 512
+
+// APIError описывает неуспешный ответ Yougile API.
+// Через errors.Is ошибка сопоставляется с ErrUnauthorized, ErrNotFound,
+// ErrRateLimit или ErrUnavailable в зависимости от кода ответа.
+type APIError struct {
+	StatusCode int    // HTTP-код ответа
+	Method     string // метод запроса
+	Endpoint   string // путь запроса без хоста и параметров
+	RequestID  string // идентификатор запроса из заголовков ответа, если есть
+	Body       string // тело ответа, усечённое до maxErrorBody байт
+	Retryable  bool   // имеет ли смысл повторить запрос
+	// RetryAfter — пауза до повтора из заголовка Retry-After (0, если не указана)
+	RetryAfter time.Duration
+}
+
+// newAPIError строит APIError по запросу, ответу и прочитанному телу ответа.
+func newAPIError(req *http.Request, resp *http.Response, body []byte) *APIError {
+	e := &APIError{
+		StatusCode: resp.StatusCode,
+		Body:       truncateBody(body),
+		Retryable:  isRetryableStatus(resp.StatusCode),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+	if req != nil {
+		e.Method = req.Method
+		if req.URL != nil {
+			e.Endpoint = req.URL.Path
+		}
+	}
+	for _, h := range []string{"X-Request-Id", "Request-Id", "X-Correlation-Id"} {
+		if v := resp.Header.Get(h); v != "" {
+			e.RequestID = v
+			break
+		}
+	}
+	return e
+}
+
+// Error возвращает описание ошибки для логов.
+func (e *APIError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "неверный код ответа %d на %s %s", e.StatusCode, e.Method, e.Endpoint)
+	if e.RequestID != "" {
+		fmt.Fprintf(&sb, " (request id %s)", e.RequestID)
+	}
+	if e.Body != "" {
+		fmt.Fprintf(&sb, ", тело: %s", e.Body)
+	}
+	return sb.String()
+}
+
+// Unwrap возвращает соответствующую коду ответа сигнальную ошибку (или nil).
+func (e *APIError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimit
+	case e.StatusCode >= 500:
+		return ErrUnavailable
+	}
+	return nil
+}
+
+// IsRetryable сообщает, является ли err временной ошибкой API (5xx или 429).
+func IsRetryable(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Retryable
+}
+
+// isRetryableStatus сообщает, стоит ли повторять запрос с данным кодом ответа.
+func isRetryableStatus(code int) bool {
+	return code >= 500 || code == http.StatusTooManyRequests
+}
+
+// truncateBody обрезает тело ответа до maxErrorBody байт, не разрывая символы UTF-8.
+func truncateBody(body []byte) string {
+	s := strings.TrimSpace(string(body))
+	if len(s) <= maxErrorBody {
+		return s
+	}
+	cut := maxErrorBody
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…"
+}
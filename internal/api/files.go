@@ -0,0 +1,116 @@
+// Package api содержит загрузку файлов в Yougile и прикрепление их к задачам.
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+
+	"yougile_bot4/internal/models"
+)
+
+// UploadFile загружает файл name через POST /api-v2/upload-file и возвращает
+// полный URL файла в Yougile.
+func (c *Client) UploadFile(ctx context.Context, name string, data []byte) (string, error) {
+	var result struct {
+		Result  string `json:"result"`
+		URL     string `json:"url"`
+		FullURL string `json:"fullUrl"`
+	}
+	err := c.retryOperation(ctx, func() (bool, error) {
+		// тело собирается заново на каждую попытку: буфер вычитывается запросом
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, filepath.Base(name)))
+		contentType := mime.TypeByExtension(filepath.Ext(name))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		header.Set("Content-Type", contentType)
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return true, fmt.Errorf("ошибка создания части file: %w", err)
+		}
+		if _, err := part.Write(data); err != nil {
+			return true, fmt.Errorf("ошибка записи файла: %w", err)
+		}
+		if err := writer.Close(); err != nil {
+			return true, fmt.Errorf("ошибка закрытия writer: %w", err)
+		}
+
+		req, err := c.newListRequest(ctx, http.MethodPost, "/api-v2/upload-file", nil, body.Bytes())
+		if err != nil {
+			return true, err
+		}
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		resp, err := c.do(req)
+		if err != nil {
+			return false, fmt.Errorf("ошибка выполнения запроса: %w", err)
+		}
+		respBody, _ := io.ReadAll(resp.Body)
+		if cerr := resp.Body.Close(); cerr != nil {
+			log.Printf("Ошибка закрытия тела ответа в UploadFile: %v", cerr)
+		}
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+			apiErr := newAPIError(req, resp, respBody)
+			return !apiErr.Retryable, apiErr
+		}
+		if err := json.Unmarshal(respBody, &result); err != nil {
+			return true, fmt.Errorf("ошибка декодирования ответа: %w", err)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case result.FullURL != "":
+		return result.FullURL, nil
+	case result.URL != "":
+		return c.baseURL + "/" + strings.TrimPrefix(result.URL, "/"), nil
+	}
+	return "", fmt.Errorf("Yougile не вернул URL загруженного файла")
+}
+
+// UploadAttachment загружает вложение в Yougile и публикует ссылку на него в чате
+// задачи taskID. URL файла записывается в attachment.URL.
+func (c *Client) UploadAttachment(ctx context.Context, taskID string, attachment *models.Attachment, data []byte) error {
+	fileURL, err := c.UploadFile(ctx, attachment.ID, data)
+	if err != nil {
+		return err
+	}
+	attachment.URL = fileURL
+	if _, err := c.sendChatHTML(ctx, taskID, AttachmentText(attachment), attachmentHTML(attachment)); err != nil {
+		return fmt.Errorf("файл загружен (%s), но ссылка не добавлена в задачу: %w", fileURL, err)
+	}
+	return nil
+}
+
+// AttachmentText возвращает текст со ссылкой на вложение для описания или чата задачи.
+func AttachmentText(attachment *models.Attachment) string {
+	if attachment.Type == models.AttachmentTypeImage {
+		return "📷 Фотография: " + attachment.URL
+	}
+	return "📎 Файл: " + attachment.URL
+}
+
+// attachmentHTML возвращает HTML-представление вложения для чата: изображение
+// показывается в сообщении, остальные файлы — ссылкой.
+func attachmentHTML(attachment *models.Attachment) string {
+	u := html.EscapeString(attachment.URL)
+	if attachment.Type == models.AttachmentTypeImage {
+		return fmt.Sprintf(`<img src="%s"><br><a href="%s">%s</a>`, u, u, u)
+	}
+	return fmt.Sprintf(`📎 <a href="%s">%s</a>`, u, html.EscapeString(filepath.Base(attachment.URL)))
+}
@@ -0,0 +1,198 @@
+// Package api содержит постраничный обход списка задач Yougile.
+package api
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"yougile_bot4/internal/models"
+)
+
+// defaultPageSize — размер страницы по умолчанию (совпадает со значением Yougile API).
+const defaultPageSize = 50
+
+// maxIteratorPages ограничивает число страниц за один обход на случай,
+// если сервер некорректно сообщает о наличии следующей страницы.
+const maxIteratorPages = 1000
+
+// Paging описывает метаданные страницы из ответа Yougile (PagingMetadata).
+type Paging struct {
+	Count  int  `json:"count"`  // количество элементов в результате
+	Limit  int  `json:"limit"`  // количество элементов на страницу
+	Offset int  `json:"offset"` // индекс первого элемента страницы
+	Next   bool `json:"next"`   // есть ли элементы после данной страницы
+}
+
+// TaskFilter задаёт область и условия выборки задач при обходе.
+type TaskFilter struct {
+	BoardID  string
+	ColumnID string
+	// Completed ограничивает выборку по статусу выполнения; nil — любые задачи.
+	// API не умеет фильтровать по этому признаку, поэтому фильтр применяется локально.
+	Completed *bool
+	// Title — подстрока названия (без учёта регистра). Передаётся в API параметром
+	// title и дополнительно проверяется локально: не все эндпоинты его понимают.
+	Title string
+	// CreatedAfter оставляет задачи, созданные позже этого момента (проверяется локально);
+	// нулевое время — без ограничения.
+	CreatedAfter time.Time
+	// PageSize — размер страницы; 0 означает значение по умолчанию.
+	PageSize int
+}
+
+// Matches сообщает, подходит ли задача под фильтр. Колонка сверяется, только
+// если она известна у задачи: устаревшие эндпоинты не возвращают columnId.
+func (f TaskFilter) Matches(t models.Task) bool {
+	if f.Completed != nil && t.Done != *f.Completed {
+		return false
+	}
+	if f.ColumnID != "" && t.ColumnID != "" && t.ColumnID != f.ColumnID {
+		return false
+	}
+	if title := strings.TrimSpace(f.Title); title != "" &&
+		!strings.Contains(strings.ToLower(t.Title), strings.ToLower(title)) {
+		return false
+	}
+	if !f.CreatedAfter.IsZero() && !t.CreatedAt.After(f.CreatedAfter) {
+		return false
+	}
+	return true
+}
+
+// DefaultFilter возвращает фильтр по доске и колонке, настроенным в клиенте.
+func (c *Client) DefaultFilter() TaskFilter {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return TaskFilter{BoardID: c.boardID, ColumnID: c.columnID}
+}
+
+// TaskIterator последовательно возвращает задачи, загружая их постранично.
+// Использование:
+//
+//	it := client.IterateTasks(ctx, filter)
+//	for it.Next() {
+//		task := it.Task()
+//	}
+//	if err := it.Err(); err != nil { ... }
+type TaskIterator struct {
+	c      *Client
+	ctx    context.Context
+	filter TaskFilter
+
+	page   []models.Task
+	pos    int
+	offset int
+	pages  int
+	done   bool
+	err    error
+	cur    models.Task
+	// firstKey — ключ первой задачи первой страницы; позволяет распознать
+	// инсталляции, игнорирующие offset и возвращающие одну и ту же страницу.
+	firstKey string
+}
+
+// IterateTasks создаёт итератор по всем задачам, подходящим под фильтр.
+// Страницы запрашиваются по мере продвижения итератора, следуя метаданным paging
+// из ответа Yougile; если метаданных нет, обход завершается на неполной странице.
+func (c *Client) IterateTasks(ctx context.Context, filter TaskFilter) *TaskIterator {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = defaultPageSize
+	}
+	return &TaskIterator{c: c, ctx: ctx, filter: filter}
+}
+
+// Next переходит к следующей задаче. Возвращает false, когда задачи закончились,
+// произошла ошибка или контекст отменён.
+func (it *TaskIterator) Next() bool {
+	for {
+		for it.pos < len(it.page) {
+			t := it.page[it.pos]
+			it.pos++
+			if !it.filter.Matches(t) {
+				continue
+			}
+			it.cur = t
+			return true
+		}
+		if it.done || it.err != nil {
+			return false
+		}
+		it.fetch()
+	}
+}
+
+// Task возвращает текущую задачу.
+func (it *TaskIterator) Task() models.Task {
+	return it.cur
+}
+
+// Err возвращает ошибку, прервавшую обход (включая отмену контекста).
+func (it *TaskIterator) Err() error {
+	return it.err
+}
+
+// fetch загружает следующую страницу.
+func (it *TaskIterator) fetch() {
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return
+	}
+	if it.pages >= maxIteratorPages {
+		it.done = true
+		return
+	}
+
+	q := listQuery{
+		board:  it.filter.BoardID,
+		column: it.filter.ColumnID,
+		title:  strings.TrimSpace(it.filter.Title),
+		limit:  it.filter.PageSize,
+		offset: it.offset,
+	}
+	tasks, paging, err := it.c.listPage(it.ctx, q)
+	if err != nil {
+		it.err = err
+		return
+	}
+	it.pages++
+	it.page, it.pos = tasks, 0
+
+	if len(tasks) == 0 {
+		it.done = true
+		return
+	}
+	key := taskIdentity(tasks[0])
+	if it.offset == 0 {
+		it.firstKey = key
+	} else if key != "" && key == it.firstKey {
+		// сервер проигнорировал offset — повторно ту же страницу не отдаём
+		it.page = nil
+		it.done = true
+		return
+	}
+
+	it.offset += len(tasks)
+	if paging != nil {
+		it.done = !paging.Next
+	} else {
+		it.done = len(tasks) < it.filter.PageSize
+	}
+}
+
+// taskIdentity возвращает идентификатор задачи для сравнения страниц.
+func taskIdentity(t models.Task) string {
+	switch {
+	case t.ExternalID != "":
+		return t.ExternalID
+	case t.Key != "":
+		return t.Key
+	case t.ID != 0:
+		return strconv.FormatInt(t.ID, 10)
+	}
+	return ""
+}
@@ -0,0 +1,182 @@
+// Package api содержит ограничитель частоты запросов к Yougile API.
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"yougile_bot4/internal/metrics"
+)
+
+// backgroundKey помечает контекст запросов фоновых задач.
+type backgroundKey struct{}
+
+// Background помечает ctx как контекст фоновой задачи (сканеры, fullscan).
+// Такие запросы не расходуют резерв токенов, оставляя его запросам пользователей,
+// поэтому длительное сканирование не задерживает создание задач.
+func Background(ctx context.Context) context.Context {
+	return context.WithValue(ctx, backgroundKey{}, true)
+}
+
+func isBackground(ctx context.Context) bool {
+	v, _ := ctx.Value(backgroundKey{}).(bool)
+	return v
+}
+
+// rateLimiter — token bucket, общий для всех запросов клиента.
+// Кроме равномерного пополнения учитывает Retry-After: после ответа 429
+// запросы не выполняются до указанного сервером момента.
+type rateLimiter struct {
+	mu      sync.Mutex
+	rate    float64 // токенов в секунду
+	burst   float64 // ёмкость корзины
+	reserve float64 // токены, недоступные фоновым запросам
+	tokens  float64
+	last    time.Time
+	// blockedUntil — момент, до которого сервер попросил не отправлять запросы
+	blockedUntil time.Time
+	metrics      *metrics.Metrics
+	now          func() time.Time
+}
+
+// newRateLimiter создаёт ограничитель на perMinute запросов в минуту.
+// Ёмкость корзины — десятая часть минутного лимита, половина её зарезервирована
+// для запросов, не помеченных Background.
+func newRateLimiter(perMinute int, m *metrics.Metrics) *rateLimiter {
+	burst := float64(perMinute) / 10
+	if burst < 1 {
+		burst = 1
+	}
+	l := &rateLimiter{
+		rate:    float64(perMinute) / 60,
+		burst:   burst,
+		reserve: float64(int(burst / 2)),
+		tokens:  burst,
+		metrics: m,
+		now:     time.Now,
+	}
+	l.last = l.now()
+	l.publish()
+	return l
+}
+
+// wait блокируется, пока запрос не может быть выполнен, или до отмены ctx.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	need := 1.0
+	if isBackground(ctx) {
+		need += l.reserve
+	}
+	waited := false
+	for {
+		l.mu.Lock()
+		now := l.now()
+		l.refill(now)
+		var delay time.Duration
+		switch {
+		case now.Before(l.blockedUntil):
+			delay = l.blockedUntil.Sub(now)
+		case l.tokens >= need:
+			l.tokens--
+			l.publish()
+			l.mu.Unlock()
+			return nil
+		default:
+			delay = time.Duration((need - l.tokens) / l.rate * float64(time.Second))
+		}
+		l.mu.Unlock()
+
+		if !waited {
+			waited = true
+			if l.metrics != nil {
+				l.metrics.IncAPIRateWaits()
+			}
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// block запрещает запросы на время d (по заголовку Retry-After).
+func (l *rateLimiter) block(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	until := l.now().Add(d)
+	if until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+	l.tokens = 0
+	l.publish()
+}
+
+// budget возвращает число доступных токенов.
+func (l *rateLimiter) budget() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(l.now())
+	return l.tokens
+}
+
+// refill пополняет корзину; вызывается под l.mu.
+func (l *rateLimiter) refill(now time.Time) {
+	if elapsed := now.Sub(l.last).Seconds(); elapsed > 0 {
+		l.tokens += elapsed * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+}
+
+// publish сохраняет текущий бюджет в метриках; вызывается под l.mu.
+func (l *rateLimiter) publish() {
+	if l.metrics != nil {
+		l.metrics.SetAPIRateBudget(int64(l.tokens))
+	}
+}
+
+// parseRetryAfter разбирает заголовок Retry-After (секунды или HTTP-дата).
+// Возвращает 0, если заголовок отсутствует или некорректен.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs <= 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// SetRateLimit задаёт лимит запросов в минуту; 0 или отрицательное значение отключает ограничение.
+func (c *Client) SetRateLimit(perMinute int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if perMinute <= 0 {
+		c.limiter = nil
+		return
+	}
+	c.limiter = newRateLimiter(perMinute, c.metrics)
+}
+
+// RateBudget возвращает число запросов, которые можно выполнить без ожидания
+// (-1, если ограничение отключено).
+func (c *Client) RateBudget() int {
+	c.mu.RLock()
+	l := c.limiter
+	c.mu.RUnlock()
+	if l == nil {
+		return -1
+	}
+	return int(l.budget())
+}
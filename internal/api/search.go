@@ -0,0 +1,119 @@
+// Package api содержит поиск задач Yougile по тексту и фильтрам.
+package api
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"log"
+	"sort"
+
+	"yougile_bot4/internal/models"
+)
+
+// defaultSearchLimit ограничивает число найденных задач, если лимит не задан.
+const defaultSearchLimit = 50
+
+// SearchTasks ищет задачи по фильтру (подстрока названия, колонка, статус,
+// дата создания) и возвращает не больше limit задач (0 — defaultSearchLimit),
+// новые первыми. API не упорядочивает задачи по дате создания, поэтому обходятся
+// все страницы списка и сохраняются limit самых новых совпадений; фильтр
+// применяется локально к каждой задаче, поэтому поиск работает и на
+// эндпоинтах, игнорирующих параметр title. Если Yougile недоступен, поиск
+// выполняется по кэшу задач клиента (см. GetTasks).
+func (c *Client) SearchTasks(ctx context.Context, filter TaskFilter, limit int) ([]models.Task, error) {
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	newest := newestTasks{limit: limit}
+	it := c.IterateTasks(ctx, filter)
+	for it.Next() {
+		newest.add(it.Task())
+	}
+	found := newest.sorted()
+	if err := it.Err(); err != nil {
+		if ctx.Err() != nil || errors.Is(err, ErrUnauthorized) {
+			return nil, err
+		}
+		cached, ok := c.searchCache(filter, limit)
+		if !ok {
+			return nil, err
+		}
+		log.Printf("SearchTasks: ошибка получения задач, поиск выполнен по кэшу: %v", err)
+		found = cached
+	}
+	return found, nil
+}
+
+// searchCache применяет фильтр к кэшу задач. Возвращает false, если кэш пуст.
+func (c *Client) searchCache(filter TaskFilter, limit int) ([]models.Task, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.cache == nil || len(c.cache.Tasks) == 0 {
+		return nil, false
+	}
+	newest := newestTasks{limit: limit}
+	for _, t := range c.cache.Tasks {
+		if filter.Matches(t) {
+			newest.add(t)
+		}
+	}
+	return newest.sorted(), true
+}
+
+// newestTasks хранит не больше limit самых новых задач: куча с самой старой
+// задачей в корне, которая вытесняется более новой. Задачи без времени создания
+// считаются самыми старыми; при равном времени остаются встреченные раньше.
+type newestTasks struct {
+	limit int
+	seen  int
+	items []rankedTask
+}
+
+// rankedTask — задача и порядковый номер, под которым она встретилась.
+type rankedTask struct {
+	task models.Task
+	seq  int
+}
+
+// add учитывает очередную найденную задачу.
+func (n *newestTasks) add(t models.Task) {
+	item := rankedTask{task: t, seq: n.seen}
+	n.seen++
+	if len(n.items) < n.limit {
+		heap.Push(n, item)
+		return
+	}
+	if n.older(n.items[0], item) {
+		n.items[0] = item
+		heap.Fix(n, 0)
+	}
+}
+
+// sorted возвращает сохранённые задачи, новые первыми.
+func (n *newestTasks) sorted() []models.Task {
+	sort.Slice(n.items, func(i, j int) bool { return n.older(n.items[j], n.items[i]) })
+	tasks := make([]models.Task, len(n.items))
+	for i, item := range n.items {
+		tasks[i] = item.task
+	}
+	return tasks
+}
+
+// older сообщает, что a уступает b: создана раньше или, при равном времени, встретилась позже.
+func (n *newestTasks) older(a, b rankedTask) bool {
+	if !a.task.CreatedAt.Equal(b.task.CreatedAt) {
+		return a.task.CreatedAt.Before(b.task.CreatedAt)
+	}
+	return a.seq > b.seq
+}
+
+func (n *newestTasks) Len() int           { return len(n.items) }
+func (n *newestTasks) Less(i, j int) bool { return n.older(n.items[i], n.items[j]) }
+func (n *newestTasks) Swap(i, j int)      { n.items[i], n.items[j] = n.items[j], n.items[i] }
+func (n *newestTasks) Push(x interface{}) { n.items = append(n.items, x.(rankedTask)) }
+func (n *newestTasks) Pop() interface{} {
+	last := n.items[len(n.items)-1]
+	n.items = n.items[:len(n.items)-1]
+	return last
+}
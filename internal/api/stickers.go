@@ -0,0 +1,188 @@
+// Package api содержит работу со стикерами (пользовательскими полями) досок Yougile.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"yougile_bot4/internal/models"
+)
+
+// stickerCacheTTL — время, в течение которого определения стикеров доски считаются актуальными.
+const stickerCacheTTL = 10 * time.Minute
+
+// stickerCache хранит определения стикеров по доскам.
+type stickerCache struct {
+	mu      sync.Mutex
+	boards  map[string][]models.Sticker
+	fetched map[string]time.Time
+}
+
+// get возвращает закэшированные стикеры доски, если они не устарели.
+func (s *stickerCache) get(boardID string) ([]models.Sticker, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	at, ok := s.fetched[boardID]
+	if !ok || time.Since(at) > stickerCacheTTL {
+		return nil, false
+	}
+	return s.boards[boardID], true
+}
+
+// put сохраняет стикеры доски.
+func (s *stickerCache) put(boardID string, stickers []models.Sticker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.boards == nil {
+		s.boards = make(map[string][]models.Sticker)
+		s.fetched = make(map[string]time.Time)
+	}
+	s.boards[boardID] = stickers
+	s.fetched[boardID] = time.Now()
+}
+
+// invalidate сбрасывает кэш доски (например, после добавления состояния).
+func (s *stickerCache) invalidate(boardID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.fetched, boardID)
+}
+
+// ListStickers возвращает неудалённые текстовые стикеры доски boardID
+// вместе с их неудалёнными состояниями.
+func (c *Client) ListStickers(ctx context.Context, boardID string) ([]models.Sticker, error) {
+	params := url.Values{}
+	if boardID != "" {
+		params.Set("boardId", boardID)
+	}
+	var stickers []models.Sticker
+	err := c.listAll(ctx, "/api-v2/string-stickers", params, func(content json.RawMessage) (int, error) {
+		var page []models.Sticker
+		if err := json.Unmarshal(content, &page); err != nil {
+			return 0, err
+		}
+		for _, s := range page {
+			if s.Deleted {
+				continue
+			}
+			states := s.States[:0]
+			for _, st := range s.States {
+				if !st.Deleted {
+					states = append(states, st)
+				}
+			}
+			s.States = states
+			stickers = append(stickers, s)
+		}
+		return len(page), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения стикеров: %w", err)
+	}
+	c.stickers.put(boardID, stickers)
+	return stickers, nil
+}
+
+// CreateStickerState добавляет стикеру stickerID состояние name и возвращает его ID.
+func (c *Client) CreateStickerState(ctx context.Context, stickerID, name string) (string, error) {
+	path := "/api-v2/string-stickers/" + url.PathEscape(stickerID) + "/states"
+	data, err := json.Marshal(map[string]string{"name": name})
+	if err != nil {
+		return "", fmt.Errorf("ошибка сериализации состояния стикера: %w", err)
+	}
+	var created struct {
+		ID string `json:"id"`
+	}
+	err = c.retryOperation(ctx, func() (bool, error) {
+		req, err := c.newListRequest(ctx, http.MethodPost, path, nil, data)
+		if err != nil {
+			return true, err
+		}
+		resp, err := c.do(req)
+		if err != nil {
+			return false, fmt.Errorf("ошибка выполнения запроса: %w", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if cerr := resp.Body.Close(); cerr != nil {
+			log.Printf("Ошибка закрытия тела ответа в CreateStickerState: %v", cerr)
+		}
+		if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+			apiErr := newAPIError(req, resp, body)
+			return !apiErr.Retryable, apiErr
+		}
+		if err := json.Unmarshal(body, &created); err != nil || created.ID == "" {
+			return true, fmt.Errorf("не удалось распарсить ответ CreateStickerState: %s", strings.TrimSpace(string(body)))
+		}
+		return true, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+// ResolveStickers переводит значения стикеров (ID стикера → текст) в формат задачи
+// Yougile. Для стикеров с набором состояний текст сопоставляется с названием
+// состояния без учёта регистра; отсутствующее состояние создаётся, только если
+// createStates, иначе значение пропускается. Для свободных полей и стикеров, не
+// найденных среди текстовых стикеров доски, текст передаётся как есть. Пустые
+// значения пропускаются.
+func (c *Client) ResolveStickers(ctx context.Context, boardID string, values map[string]string, createStates bool) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	stickers, ok := c.stickers.get(boardID)
+	if !ok {
+		var err error
+		if stickers, err = c.ListStickers(ctx, boardID); err != nil {
+			return nil, err
+		}
+	}
+	byID := make(map[string]models.Sticker, len(stickers))
+	for _, s := range stickers {
+		byID[s.ID] = s
+	}
+
+	result := make(map[string]string, len(values))
+	for stickerID, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		sticker, known := byID[stickerID]
+		if !known || len(sticker.States) == 0 {
+			result[stickerID] = value
+			continue
+		}
+		stateID := ""
+		for _, st := range sticker.States {
+			if strings.EqualFold(strings.TrimSpace(st.Name), value) {
+				stateID = st.ID
+				break
+			}
+		}
+		if stateID == "" && !createStates {
+			log.Printf("ResolveStickers: у стикера %q нет состояния %q, значение не записано", sticker.Name, value)
+			continue
+		}
+		if stateID == "" {
+			id, err := c.CreateStickerState(ctx, stickerID, value)
+			if err != nil {
+				return nil, fmt.Errorf("ошибка создания состояния %q стикера %s: %w", value, sticker.Name, err)
+			}
+			log.Printf("ResolveStickers: стикеру %q добавлено состояние %q", sticker.Name, value)
+			c.stickers.invalidate(boardID)
+			stateID = id
+		}
+		result[stickerID] = stateID
+	}
+	return result, nil
+}
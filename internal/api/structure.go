@@ -0,0 +1,145 @@
+// Package api содержит получение структуры Yougile: проектов, досок и колонок.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"yougile_bot4/internal/models"
+)
+
+// ListProjects возвращает все неудалённые проекты компании.
+func (c *Client) ListProjects(ctx context.Context) ([]models.Project, error) {
+	var projects []models.Project
+	err := c.listAll(ctx, "/api-v2/projects", nil, func(content json.RawMessage) (int, error) {
+		var page []models.Project
+		if err := json.Unmarshal(content, &page); err != nil {
+			return 0, err
+		}
+		for _, p := range page {
+			if !p.Deleted {
+				projects = append(projects, p)
+			}
+		}
+		return len(page), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения проектов: %w", err)
+	}
+	return projects, nil
+}
+
+// ListBoards возвращает неудалённые доски проекта projectID
+// (все доски компании, если projectID пуст).
+func (c *Client) ListBoards(ctx context.Context, projectID string) ([]models.Board, error) {
+	params := url.Values{}
+	if projectID != "" {
+		params.Set("projectId", projectID)
+	}
+	var boards []models.Board
+	err := c.listAll(ctx, "/api-v2/boards", params, func(content json.RawMessage) (int, error) {
+		var page []models.Board
+		if err := json.Unmarshal(content, &page); err != nil {
+			return 0, err
+		}
+		for _, b := range page {
+			if !b.Deleted {
+				boards = append(boards, b)
+			}
+		}
+		return len(page), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения досок: %w", err)
+	}
+	return boards, nil
+}
+
+// ListColumns возвращает неудалённые колонки доски boardID.
+func (c *Client) ListColumns(ctx context.Context, boardID string) ([]models.Column, error) {
+	params := url.Values{}
+	if boardID != "" {
+		params.Set("boardId", boardID)
+	}
+	var columns []models.Column
+	err := c.listAll(ctx, "/api-v2/columns", params, func(content json.RawMessage) (int, error) {
+		var page []models.Column
+		if err := json.Unmarshal(content, &page); err != nil {
+			return 0, err
+		}
+		for _, col := range page {
+			if !col.Deleted {
+				columns = append(columns, col)
+			}
+		}
+		return len(page), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения колонок: %w", err)
+	}
+	return columns, nil
+}
+
+// listAll постранично обходит список Yougile вида {"paging": {...}, "content": [...]}.
+// Для каждой страницы вызывается add с содержимым content; add возвращает число
+// элементов на странице. Обход завершается, когда сервер сообщает об отсутствии
+// следующей страницы или возвращает пустую страницу.
+func (c *Client) listAll(ctx context.Context, path string, params url.Values, add func(content json.RawMessage) (int, error)) error {
+	offset := 0
+	for page := 0; page < maxIteratorPages; page++ {
+		q := url.Values{}
+		for k, v := range params {
+			q[k] = v
+		}
+		q.Set("limit", strconv.Itoa(defaultPageSize))
+		q.Set("offset", strconv.Itoa(offset))
+
+		var result struct {
+			Paging  *Paging         `json:"paging"`
+			Content json.RawMessage `json:"content"`
+		}
+		err := c.retryOperation(ctx, func() (bool, error) {
+			req, err := c.newListRequest(ctx, http.MethodGet, path, q, nil)
+			if err != nil {
+				return true, err
+			}
+			resp, err := c.do(req)
+			if err != nil {
+				return false, fmt.Errorf("ошибка выполнения запроса: %w", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if cerr := resp.Body.Close(); cerr != nil {
+				log.Printf("Ошибка закрытия тела ответа в %s: %v", path, cerr)
+			}
+			if resp.StatusCode != http.StatusOK {
+				apiErr := newAPIError(req, resp, body)
+				return !apiErr.Retryable, apiErr
+			}
+			if err := json.Unmarshal(body, &result); err != nil {
+				return true, fmt.Errorf("ошибка декодирования ответа: %w", err)
+			}
+			return true, nil
+		})
+		if err != nil {
+			return err
+		}
+
+		n := 0
+		if len(result.Content) > 0 {
+			if n, err = add(result.Content); err != nil {
+				return fmt.Errorf("ошибка декодирования ответа: %w", err)
+			}
+		}
+		if n == 0 || result.Paging == nil || !result.Paging.Next {
+			return nil
+		}
+		offset += n
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+// Package api содержит единый разбор задач Yougile из JSON.
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"yougile_bot4/internal/models"
+)
+
+// taskKeyFields — поля с коротким ключом задачи (например ITS-2904) в порядке приоритета.
+var taskKeyFields = []string{"idTaskProject", "idTaskCommon", "key", "shortId", "number"}
+
+// DecodeTask разбирает задачу Yougile из JSON. Поддерживаются задача как есть и
+// обёртки {"data": {...}} и {"data": {"task": {...}}}; ID может быть строкой (UUID,
+// попадает в ExternalID) или числом (ID). Кроме полей API понимает и собственный
+// формат models.Task (snake_case). Неизвестные поля сохраняются в Task.Extra.
+func DecodeTask(data []byte) (models.Task, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return models.Task{}, fmt.Errorf("ошибка декодирования задачи: %w", err)
+	}
+	return decodeTaskFields(unwrapTask(raw)), nil
+}
+
+// unwrapTask снимает обёртки data и data.task, если в объекте нет полей задачи.
+func unwrapTask(raw map[string]json.RawMessage) map[string]json.RawMessage {
+	for _, field := range []string{"data", "task"} {
+		if _, ok := raw["id"]; ok {
+			return raw
+		}
+		inner, ok := raw[field]
+		if !ok {
+			return raw
+		}
+		var obj map[string]json.RawMessage
+		if json.Unmarshal(inner, &obj) != nil || obj == nil {
+			return raw
+		}
+		raw = obj
+	}
+	return raw
+}
+
+// decodeTaskFields отображает поля объекта задачи в models.Task.
+func decodeTaskFields(raw map[string]json.RawMessage) models.Task {
+	var t models.Task
+	for name, v := range raw {
+		switch name {
+		case "id":
+			// строковый ID (UUID Yougile) хранится в ExternalID, числовой — в ID
+			if trimmed := bytes.TrimSpace(v); len(trimmed) > 0 && trimmed[0] == '"' {
+				t.ExternalID = rawString(v)
+			} else {
+				t.ID, _ = rawInt(v)
+			}
+		case "external_id":
+			if s := rawString(v); s != "" {
+				t.ExternalID = s
+			}
+		case "key", "idTaskProject", "idTaskCommon", "shortId", "number":
+			// разбираются ниже в порядке приоритета
+		case "title":
+			t.Title = rawString(v)
+		case "description":
+			t.Description = rawString(v)
+		case "columnId", "column_id":
+			t.ColumnID = rawString(v)
+		case "boardId", "board_id":
+			t.BoardID = rawString(v)
+		case "completed", "done":
+			t.Done = t.Done || rawBool(v)
+		case "archived":
+			t.Archived = rawBool(v)
+		case "deleted":
+			t.Deleted = rawBool(v)
+		case "status":
+			t.Status = models.TaskStatus(rawString(v))
+		case "timestamp", "created_at":
+			t.CreatedAt = rawTime(v)
+		case "updated_at":
+			t.UpdatedAt = rawTime(v)
+		case "deadline", "due_date":
+			t.DueDate = rawDeadline(v)
+			t.HasDeadline = true
+		case "priority":
+			if n, ok := rawInt(v); ok {
+				t.Priority = int(n)
+			}
+		case "assigned":
+			t.Assigned = rawStrings(v)
+		case "assignee":
+			t.Assignee = rawString(v)
+		case "stickers":
+			t.Stickers = rawStringMap(v)
+		case "labels":
+			t.Labels = rawStrings(v)
+		case "parent_id":
+			t.ParentID, _ = rawInt(v)
+		case "requester_id":
+			t.RequesterID, _ = rawInt(v)
+		case "attachments":
+			t.Attachments = rawStrings(v)
+		default:
+			if t.Extra == nil {
+				t.Extra = make(map[string]json.RawMessage)
+			}
+			t.Extra[name] = v
+		}
+	}
+	for _, field := range taskKeyFields {
+		if s := rawString(raw[field]); s != "" {
+			t.Key = s
+			break
+		}
+	}
+	return t
+}
+
+// decodeTaskList разбирает ответ со списком задач. Поддерживаются формат Yougile
+// {"paging": {...}, "content": [...]}, упрощённый {"data": [...]} и голый массив;
+// paging равен nil, если метаданные страницы в ответе отсутствуют.
+func decodeTaskList(body []byte) ([]models.Task, *Paging, error) {
+	var items []json.RawMessage
+	var paging *Paging
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
+		}
+	} else {
+		var result struct {
+			Paging  *Paging           `json:"paging"`
+			Content []json.RawMessage `json:"content"`
+			Data    []json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return nil, nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
+		}
+		items, paging = result.Data, result.Paging
+		if result.Content != nil {
+			items = result.Content
+		}
+	}
+
+	tasks := make([]models.Task, 0, len(items))
+	for i, item := range items {
+		task, err := DecodeTask(item)
+		if err != nil {
+			return nil, nil, fmt.Errorf("задача %d: %w", i, err)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, paging, nil
+}
+
+// rawString возвращает строку или число как строку; для прочих значений — "".
+func rawString(v json.RawMessage) string {
+	if len(v) == 0 {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(v, &s) == nil {
+		return s
+	}
+	var n json.Number
+	if json.Unmarshal(v, &n) == nil {
+		return n.String()
+	}
+	return ""
+}
+
+// rawInt возвращает целое из числа или строки с числом.
+func rawInt(v json.RawMessage) (int64, bool) {
+	var n json.Number
+	if json.Unmarshal(v, &n) != nil {
+		var s string
+		if json.Unmarshal(v, &s) != nil {
+			return 0, false
+		}
+		n = json.Number(strings.TrimSpace(s))
+	}
+	if i, err := n.Int64(); err == nil {
+		return i, true
+	}
+	if f, err := n.Float64(); err == nil {
+		return int64(f), true
+	}
+	return 0, false
+}
+
+// rawBool возвращает логическое значение (true, "true", 1).
+func rawBool(v json.RawMessage) bool {
+	var b bool
+	if json.Unmarshal(v, &b) == nil {
+		return b
+	}
+	if n, ok := rawInt(v); ok {
+		return n != 0
+	}
+	b, _ = strconv.ParseBool(rawString(v))
+	return b
+}
+
+// rawTime разбирает время из миллисекунд Unix (числом или строкой) или RFC 3339.
+func rawTime(v json.RawMessage) time.Time {
+	if n, ok := rawInt(v); ok {
+		if n <= 0 {
+			return time.Time{}
+		}
+		return time.UnixMilli(n)
+	}
+	if t, err := time.Parse(time.RFC3339, rawString(v)); err == nil && !t.IsZero() {
+		return t
+	}
+	return time.Time{}
+}
+
+// rawDeadline разбирает срок: стикер {"deadline": ms, "deleted": bool} или время.
+func rawDeadline(v json.RawMessage) time.Time {
+	var sticker struct {
+		Deadline json.RawMessage `json:"deadline"`
+		Deleted  bool            `json:"deleted"`
+	}
+	if json.Unmarshal(v, &sticker) == nil && sticker.Deadline != nil {
+		if sticker.Deleted {
+			return time.Time{}
+		}
+		return rawTime(sticker.Deadline)
+	}
+	return rawTime(v)
+}
+
+// rawStrings разбирает массив строк (или одну строку) в срез.
+func rawStrings(v json.RawMessage) []string {
+	var list []json.RawMessage
+	if json.Unmarshal(v, &list) != nil {
+		if s := rawString(v); s != "" {
+			return []string{s}
+		}
+		return nil
+	}
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		if s := rawString(item); s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// rawStringMap разбирает объект со строковыми или числовыми значениями.
+func rawStringMap(v json.RawMessage) map[string]string {
+	var obj map[string]json.RawMessage
+	if json.Unmarshal(v, &obj) != nil || len(obj) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(obj))
+	for k, item := range obj {
+		result[k] = rawString(item)
+	}
+	return result
+}
@@ -0,0 +1,82 @@
+// Package api содержит операции жизненного цикла задачи Yougile: перемещение,
+// выполнение, архивирование и срок.
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// deadlineDto — стикер «Дэдлайн» (UpdateDeadline). Время передаётся в миллисекундах.
+type deadlineDto struct {
+	Deadline      int64    `json:"deadline,omitempty"`
+	WithTime      bool     `json:"withTime,omitempty"`
+	Deleted       bool     `json:"deleted,omitempty"`
+	BlockedPoints []string `json:"blockedPoints"`
+	Links         []string `json:"links"`
+}
+
+// taskPatch — частичное изменение задачи (UpdateTaskDto): передаются только заданные поля.
+type taskPatch struct {
+	Title       *string           `json:"title,omitempty"`
+	Description *string           `json:"description,omitempty"`
+	ColumnID    *string           `json:"columnId,omitempty"`
+	Completed   *bool             `json:"completed,omitempty"`
+	Archived    *bool             `json:"archived,omitempty"`
+	Deadline    *deadlineDto      `json:"deadline,omitempty"`
+	Stickers    map[string]string `json:"stickers,omitempty"`
+	Assigned    *[]string         `json:"assigned,omitempty"`
+}
+
+// patchTask изменяет задачу id запросом PUT /api-v2/tasks/{id} только с полями patch.
+func (c *Client) patchTask(ctx context.Context, id string, patch taskPatch) error {
+	if id == "" {
+		return fmt.Errorf("не указан ID задачи")
+	}
+	if err := c.requestJSON(ctx, http.MethodPut, "/api-v2/tasks/"+url.PathEscape(id), patch, nil); err != nil {
+		return fmt.Errorf("ошибка изменения задачи %s: %w", id, err)
+	}
+	return nil
+}
+
+// MoveTask перемещает задачу в колонку columnID.
+func (c *Client) MoveTask(ctx context.Context, id, columnID string) error {
+	return c.patchTask(ctx, id, taskPatch{ColumnID: &columnID})
+}
+
+// SetTaskCompleted отмечает задачу выполненной (completed=true) или возвращает в работу.
+func (c *Client) SetTaskCompleted(ctx context.Context, id string, completed bool) error {
+	return c.patchTask(ctx, id, taskPatch{Completed: &completed})
+}
+
+// ArchiveTask переносит задачу в архив (archived=true) или возвращает из архива.
+func (c *Client) ArchiveTask(ctx context.Context, id string, archived bool) error {
+	return c.patchTask(ctx, id, taskPatch{Archived: &archived})
+}
+
+// ReopenTask возвращает выполненную или архивную задачу в работу одним запросом.
+func (c *Client) ReopenTask(ctx context.Context, id string) error {
+	no := false
+	return c.patchTask(ctx, id, taskPatch{Completed: &no, Archived: &no})
+}
+
+// SetTaskDeadline устанавливает срок задачи; withTime показывает на стикере время,
+// а не только дату. Нулевой deadline открепляет стикер срока.
+func (c *Client) SetTaskDeadline(ctx context.Context, id string, deadline time.Time, withTime bool) error {
+	return c.patchTask(ctx, id, taskPatch{Deadline: newDeadline(deadline, withTime)})
+}
+
+// newDeadline строит стикер срока; нулевое время открепляет стикер.
+func newDeadline(deadline time.Time, withTime bool) *deadlineDto {
+	d := &deadlineDto{BlockedPoints: []string{}, Links: []string{}}
+	if deadline.IsZero() {
+		d.Deleted = true
+	} else {
+		d.Deadline = deadline.UnixMilli()
+		d.WithTime = withTime
+	}
+	return d
+}
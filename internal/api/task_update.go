@@ -0,0 +1,131 @@
+// Package api содержит частичное обновление задачи Yougile по набору изменённых
+// полей с обнаружением конфликтов.
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"yougile_bot4/internal/models"
+)
+
+// TaskUpdate описывает изменяемые поля задачи: nil-поля не отправляются и не меняются.
+type TaskUpdate struct {
+	Title       *string
+	Description *string
+	ColumnID    *string
+	// Deadline — новый срок; нулевое время открепляет стикер срока.
+	Deadline  *time.Time
+	Completed *bool
+	// Stickers — значения изменяемых стикеров; "-" открепляет стикер от задачи.
+	Stickers map[string]string
+	// Assigned — новый список исполнителей; пустой срез снимает всех.
+	Assigned *[]string
+	// Base — задача в том виде, в каком её прочитал вызывающий (см. FetchTask).
+	// Если задана, перед записью задача перечитывается, и поля, которые изменились
+	// в Yougile и отличаются от записываемых, возвращаются как *ConflictError.
+	Base *models.Task
+}
+
+// FetchTask читает задачу по ID Yougile с полями, которые меняет UpdateTaskFields.
+// Результат можно передать в TaskUpdate.Base для обнаружения конфликтов.
+func (c *Client) FetchTask(ctx context.Context, id string) (*models.Task, error) {
+	var body []byte
+	if err := c.requestJSON(ctx, http.MethodGet, "/api-v2/tasks/"+url.PathEscape(id), nil, &body); err != nil {
+		return nil, fmt.Errorf("ошибка получения задачи %s: %w", id, err)
+	}
+	task, err := DecodeTask(body)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения задачи %s: %w", id, err)
+	}
+	if task.ExternalID == "" {
+		task.ExternalID = id
+	}
+	return &task, nil
+}
+
+// UpdateTaskFields изменяет у задачи id только поля, заданные в upd.
+// При заданном upd.Base возвращает *ConflictError (errors.Is(err, ErrConflict)),
+// если изменяемые поля уже изменены в Yougile; в этом случае задача не меняется.
+func (c *Client) UpdateTaskFields(ctx context.Context, id string, upd TaskUpdate) error {
+	if upd.Base != nil {
+		current, err := c.FetchTask(ctx, id)
+		if err != nil {
+			return err
+		}
+		if fields := upd.conflicts(*upd.Base, *current); len(fields) > 0 {
+			return &ConflictError{TaskID: id, Fields: fields}
+		}
+	}
+	patch := taskPatch{
+		Title:       upd.Title,
+		Description: upd.Description,
+		ColumnID:    upd.ColumnID,
+		Completed:   upd.Completed,
+		Stickers:    upd.Stickers,
+		Assigned:    upd.Assigned,
+	}
+	if upd.Deadline != nil {
+		patch.Deadline = newDeadline(*upd.Deadline, false)
+	}
+	return c.patchTask(ctx, id, patch)
+}
+
+// conflicts возвращает имена изменяемых полей, которые в Yougile (current) отличаются
+// и от прочитанного значения (base), и от записываемого: такое изменение затёрло бы чужое.
+func (upd TaskUpdate) conflicts(base, current models.Task) []string {
+	var fields []string
+	check := func(name string, remote, read, want interface{}) {
+		if remote != read && remote != want {
+			fields = append(fields, name)
+		}
+	}
+	if upd.Title != nil {
+		check("title", current.Title, base.Title, *upd.Title)
+	}
+	if upd.Description != nil {
+		check("description", current.Description, base.Description, *upd.Description)
+	}
+	if upd.ColumnID != nil {
+		check("columnId", current.ColumnID, base.ColumnID, *upd.ColumnID)
+	}
+	if upd.Completed != nil {
+		check("completed", current.Done, base.Done, *upd.Completed)
+	}
+	if upd.Deadline != nil {
+		check("deadline", current.DueDate.UnixMilli(), base.DueDate.UnixMilli(), upd.Deadline.UnixMilli())
+	}
+	if upd.Assigned != nil {
+		check("assigned", setKey(current.Assigned), setKey(base.Assigned), setKey(*upd.Assigned))
+	}
+	keys := make([]string, 0, len(upd.Stickers))
+	for k := range upd.Stickers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		check("stickers."+k, current.Stickers[k], base.Stickers[k], upd.Stickers[k])
+	}
+	return fields
+}
+
+// setKey возвращает ключ набора строк, не зависящий от порядка элементов.
+func setKey(values []string) string {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	return fmt.Sprint(sorted)
+}
+
+// taskAPIID возвращает идентификатор задачи для запросов: строковый ID Yougile
+// или числовой, если строкового нет.
+func taskAPIID(task *models.Task) string {
+	if task.ExternalID != "" {
+		return task.ExternalID
+	}
+	return strconv.FormatInt(task.ID, 10)
+}
@@ -0,0 +1,165 @@
+// Package api содержит получение сотрудников компании Yougile.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"yougile_bot4/internal/models"
+)
+
+// Параметры кэша сотрудников.
+const (
+	// userCacheTTL — время, в течение которого список сотрудников считается актуальным.
+	userCacheTTL = time.Hour
+	// userRefreshMin — минимальный интервал между обновлениями списка из-за
+	// неизвестного идентификатора (например, удалённого сотрудника).
+	userRefreshMin = time.Minute
+)
+
+// userDirectory — кэш сотрудников компании, индексированный по ID.
+type userDirectory struct {
+	mu        sync.Mutex
+	users     map[string]models.YougileUser
+	updatedAt time.Time
+}
+
+// ListUsers возвращает всех сотрудников компании.
+func (c *Client) ListUsers(ctx context.Context) ([]models.YougileUser, error) {
+	var users []models.YougileUser
+	err := c.listAll(ctx, "/api-v2/users", nil, func(content json.RawMessage) (int, error) {
+		var page []models.YougileUser
+		if err := json.Unmarshal(content, &page); err != nil {
+			return 0, err
+		}
+		users = append(users, page...)
+		return len(page), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения сотрудников: %w", err)
+	}
+	c.directory.store(users)
+	return users, nil
+}
+
+// GetUser возвращает сотрудника по ID.
+func (c *Client) GetUser(ctx context.Context, id string) (*models.YougileUser, error) {
+	path := "/api-v2/users/" + url.PathEscape(id)
+	var user models.YougileUser
+	err := c.retryOperation(ctx, func() (bool, error) {
+		req, err := c.newListRequest(ctx, http.MethodGet, path, nil, nil)
+		if err != nil {
+			return true, err
+		}
+		resp, err := c.do(req)
+		if err != nil {
+			return false, fmt.Errorf("ошибка выполнения запроса: %w", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if cerr := resp.Body.Close(); cerr != nil {
+			log.Printf("Ошибка закрытия тела ответа в GetUser: %v", cerr)
+		}
+		if resp.StatusCode != http.StatusOK {
+			apiErr := newAPIError(req, resp, body)
+			return !apiErr.Retryable, apiErr
+		}
+		if err := json.Unmarshal(body, &user); err != nil {
+			return true, fmt.Errorf("ошибка декодирования ответа: %w", err)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindUser ищет сотрудника по ID или почте (без учёта регистра) в списке компании.
+func (c *Client) FindUser(ctx context.Context, idOrEmail string) (*models.YougileUser, error) {
+	users, err := c.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		if u.ID == idOrEmail || strings.EqualFold(u.Email, idOrEmail) {
+			found := u
+			return &found, nil
+		}
+	}
+	return nil, fmt.Errorf("сотрудник %q: %w", idOrEmail, ErrNotFound)
+}
+
+// UserNames возвращает имена сотрудников по их ID, используя кэш списка сотрудников.
+// Если сотрудник не найден или список получить не удалось, вместо имени возвращается ID.
+func (c *Client) UserNames(ctx context.Context, ids []string) []string {
+	if len(ids) == 0 {
+		return nil
+	}
+	if c.directory.stale(ids) {
+		if _, err := c.ListUsers(ctx); err != nil {
+			log.Printf("UserNames: не удалось обновить список сотрудников: %v", err)
+			c.directory.touch()
+		}
+	}
+	return c.directory.names(ids)
+}
+
+// store заменяет содержимое кэша.
+func (d *userDirectory) store(users []models.YougileUser) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.users = make(map[string]models.YougileUser, len(users))
+	for _, u := range users {
+		d.users[u.ID] = u
+	}
+	d.updatedAt = time.Now()
+}
+
+// touch откладывает следующее обновление после неудачной попытки.
+func (d *userDirectory) touch() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.updatedAt = time.Now()
+}
+
+// stale сообщает, нужно ли обновить кэш, чтобы получить имена ids.
+func (d *userDirectory) stale(ids []string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	age := time.Since(d.updatedAt)
+	if !d.updatedAt.IsZero() && age < userRefreshMin {
+		return false
+	}
+	if d.users == nil || age > userCacheTTL {
+		return true
+	}
+	for _, id := range ids {
+		if _, ok := d.users[id]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// names возвращает имена сотрудников из кэша.
+func (d *userDirectory) names(ids []string) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if u, ok := d.users[id]; ok {
+			result = append(result, u.DisplayName())
+		} else {
+			result = append(result, id)
+		}
+	}
+	return result
+}
@@ -0,0 +1,142 @@
+// Package api содержит управление подписками Yougile на события (вебхуками).
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"yougile_bot4/internal/models"
+)
+
+// ListWebhooks возвращает неудалённые подписки компании на события.
+func (c *Client) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	var body []byte
+	if err := c.requestJSON(ctx, http.MethodGet, "/api-v2/webhooks", nil, &body); err != nil {
+		return nil, fmt.Errorf("ошибка получения подписок: %w", err)
+	}
+	// API возвращает массив подписок; на случай постраничного ответа поддерживаем и content
+	var list []models.Webhook
+	if err := json.Unmarshal(body, &list); err != nil {
+		var paged struct {
+			Content []models.Webhook `json:"content"`
+		}
+		if perr := json.Unmarshal(body, &paged); perr != nil {
+			return nil, fmt.Errorf("ошибка декодирования подписок: %w", err)
+		}
+		list = paged.Content
+	}
+	webhooks := make([]models.Webhook, 0, len(list))
+	for _, w := range list {
+		if !w.Deleted {
+			webhooks = append(webhooks, w)
+		}
+	}
+	return webhooks, nil
+}
+
+// CreateWebhook подписывает адрес hookURL на события event и возвращает ID подписки.
+func (c *Client) CreateWebhook(ctx context.Context, hookURL, event string) (string, error) {
+	var created struct {
+		ID string `json:"id"`
+	}
+	payload := map[string]string{"url": hookURL, "event": event}
+	if err := c.requestJSON(ctx, http.MethodPost, "/api-v2/webhooks", payload, &created); err != nil {
+		return "", fmt.Errorf("ошибка создания подписки %s: %w", event, err)
+	}
+	return created.ID, nil
+}
+
+// UpdateWebhook изменяет адрес и состояние подписки id.
+func (c *Client) UpdateWebhook(ctx context.Context, id string, hook models.Webhook) error {
+	payload := map[string]interface{}{
+		"url":      hook.URL,
+		"event":    hook.Event,
+		"disabled": hook.Disabled,
+		"deleted":  hook.Deleted,
+	}
+	if err := c.requestJSON(ctx, http.MethodPut, "/api-v2/webhooks/"+url.PathEscape(id), payload, nil); err != nil {
+		return fmt.Errorf("ошибка изменения подписки %s: %w", id, err)
+	}
+	return nil
+}
+
+// EnsureWebhook гарантирует активную подписку hookURL на события event и возвращает её ID.
+// Подписка на тот же адрес (без учёта строки запроса) переиспользуется: при смене
+// секрета или отключении она обновляется, а не дублируется.
+func (c *Client) EnsureWebhook(ctx context.Context, hookURL, event string) (string, error) {
+	existing, err := c.ListWebhooks(ctx)
+	if err != nil {
+		return "", err
+	}
+	base := webhookBase(hookURL)
+	for _, w := range existing {
+		if w.Event != event || webhookBase(w.URL) != base {
+			continue
+		}
+		if w.URL != hookURL || w.Disabled {
+			w.URL, w.Disabled = hookURL, false
+			if err := c.UpdateWebhook(ctx, w.ID, w); err != nil {
+				return "", err
+			}
+		}
+		return w.ID, nil
+	}
+	return c.CreateWebhook(ctx, hookURL, event)
+}
+
+// webhookBase возвращает адрес подписки без строки запроса (в ней передаётся секрет).
+func webhookBase(hookURL string) string {
+	base, _, _ := strings.Cut(hookURL, "?")
+	return strings.TrimSuffix(base, "/")
+}
+
+// requestJSON выполняет запрос с JSON-телом payload (nil — без тела) и с повторами.
+// Ответ декодируется в out; если out — *[]byte, в него копируется тело ответа как есть.
+// Успешными считаются ответы 200 и 201.
+func (c *Client) requestJSON(ctx context.Context, method, path string, payload, out interface{}) error {
+	var data []byte
+	if payload != nil {
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("ошибка сериализации запроса: %w", err)
+		}
+	}
+	return c.retryOperation(ctx, func() (bool, error) {
+		req, err := c.newListRequest(ctx, method, path, nil, data)
+		if err != nil {
+			return true, err
+		}
+		resp, err := c.do(req)
+		if err != nil {
+			return false, fmt.Errorf("ошибка выполнения запроса: %w", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if cerr := resp.Body.Close(); cerr != nil {
+			log.Printf("Ошибка закрытия тела ответа в %s: %v", path, cerr)
+		}
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+			apiErr := newAPIError(req, resp, body)
+			return !apiErr.Retryable, apiErr
+		}
+		switch v := out.(type) {
+		case nil:
+		case *[]byte:
+			*v = bytes.Clone(body)
+		default:
+			if len(bytes.TrimSpace(body)) == 0 {
+				return true, nil
+			}
+			if err := json.Unmarshal(body, v); err != nil {
+				return true, fmt.Errorf("ошибка декодирования ответа: %w", err)
+			}
+		}
+		return true, nil
+	})
+}
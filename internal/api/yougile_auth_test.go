@@ -0,0 +1,125 @@
+// Package api содержит тесты и вспомогательные функции для клиента Yougile API.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestAuthKeyProvisioning проверяет выпуск ключа: список компаний по страницам,
+// список ключей без удалённых, создание и удаление ключа.
+func TestAuthKeyProvisioning(t *testing.T) {
+	var created map[string]string
+	deleted := ""
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("auth endpoints must not send a key, got %q", r.Header.Get("Authorization"))
+		}
+		var body map[string]string
+		if r.Body != nil {
+			_ = json.NewDecoder(r.Body).Decode(&body)
+		}
+		if r.Method == http.MethodPost && (body["login"] != "admin@example.com" || body["password"] != "secret") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var resp string
+		switch {
+		case r.URL.Path == "/api-v2/auth/companies" && r.URL.Query().Get("offset") == "0":
+			resp = `{"paging":{"count":2,"limit":100,"offset":0,"next":true},"content":[{"id":"c1","name":"Альфа","isAdmin":true}]}`
+		case r.URL.Path == "/api-v2/auth/companies":
+			resp = `{"paging":{"count":2,"limit":100,"offset":100,"next":false},"content":[{"id":"c2","name":"Бета"}]}`
+		case r.URL.Path == "/api-v2/auth/keys/get":
+			resp = `[{"key":"old","companyId":"c1","timestamp":1560506639447},{"key":"gone","companyId":"c1","timestamp":1,"deleted":true}]`
+		case r.Method == http.MethodPost && r.URL.Path == "/api-v2/auth/keys":
+			created = body
+			w.WriteHeader(http.StatusCreated)
+			resp = `{"key":"new-key"}`
+		case r.Method == http.MethodDelete && r.URL.Path == "/api-v2/auth/keys/old":
+			deleted = "old"
+			resp = `{}`
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if _, err := io.WriteString(w, resp); err != nil {
+			t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+		}
+	}))
+	defer ts.Close()
+
+	c := newPagingClient(ts)
+	c.token = ""
+	ctx := context.Background()
+	cr := Credentials{Login: "admin@example.com", Password: "secret"}
+
+	companies, err := c.ListCompanies(ctx, cr, "")
+	if err != nil {
+		t.Fatalf("ListCompanies failed: %v", err)
+	}
+	if len(companies) != 2 || companies[0].ID != "c1" || !companies[0].IsAdmin || companies[1].Name != "Бета" {
+		t.Fatalf("unexpected companies %+v", companies)
+	}
+	keys, err := c.ListAuthKeys(ctx, cr, "c1")
+	if err != nil {
+		t.Fatalf("ListAuthKeys failed: %v", err)
+	}
+	if len(keys) != 1 || keys[0].Key != "old" {
+		t.Fatalf("expected only the active key, got %+v", keys)
+	}
+	key, err := c.CreateAuthKey(ctx, cr, "c1")
+	if err != nil || key != "new-key" {
+		t.Fatalf("CreateAuthKey = %q, %v", key, err)
+	}
+	if created["companyId"] != "c1" {
+		t.Fatalf("companyId not sent: %v", created)
+	}
+	if err := c.DeleteAuthKey(ctx, "old"); err != nil || deleted != "old" {
+		t.Fatalf("DeleteAuthKey failed: %v (deleted %q)", err, deleted)
+	}
+
+	_, err = c.ListCompanies(ctx, Credentials{Login: "admin@example.com", Password: "wrong"}, "")
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized for wrong password, got %v", err)
+	}
+}
+
+// TestUnauthorizedHandler проверяет уведомление об отозванном ключе: обработчик
+// вызывается на 401 по ключу и не вызывается на 401 от эндпоинтов авторизации.
+func TestUnauthorizedHandler(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	c := newPagingClient(ts)
+	reported := make(chan error, 4)
+	c.SetUnauthorizedHandler(func(err error) { reported <- err })
+
+	if err := c.CheckKey(context.Background()); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+	select {
+	case err := <-reported:
+		if !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("handler got unexpected error %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("unauthorized handler was not called")
+	}
+
+	if _, err := c.ListCompanies(context.Background(), Credentials{Login: "a", Password: "b"}, ""); err == nil {
+		t.Fatalf("expected error for wrong credentials")
+	}
+	select {
+	case err := <-reported:
+		t.Fatalf("handler must ignore auth endpoints, got %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
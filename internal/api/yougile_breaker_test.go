@@ -0,0 +1,88 @@
+// Package api содержит тесты и вспомогательные функции для клиента Yougile API.
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"yougile_bot4/internal/metrics"
+	"yougile_bot4/internal/models"
+)
+
+// TestCircuitBreakerOpensAndRecovers проверяет размыкание выключателя после
+// серии ошибок 5xx, отказ без сетевых запросов и замыкание после пробного запроса.
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	var calls int32
+	var healthy atomic.Bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if !healthy.Load() {
+			http.Error(w, "down", http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	c := NewClient("token", "board", 2*time.Second, &metrics.Metrics{})
+	c.baseURL = ts.URL
+	c.httpClient = ts.Client()
+	c.retryWait = 5 * time.Millisecond
+	c.retryCount = 2
+	c.SetCircuitBreaker(3, 50*time.Millisecond)
+
+	comment := &models.Comment{Text: "x"}
+	for i := 0; i < 2; i++ {
+		if err := c.AddComment(context.Background(), "1", comment); !errors.Is(err, ErrUnavailable) {
+			t.Fatalf("expected ErrUnavailable, got %v", err)
+		}
+	}
+	if c.BreakerState() != "open" {
+		t.Fatalf("expected open breaker, got %s", c.BreakerState())
+	}
+
+	before := atomic.LoadInt32(&calls)
+	err := c.AddComment(context.Background(), "1", comment)
+	if !errors.Is(err, ErrCircuitOpen) || !IsUnavailable(err) {
+		t.Fatalf("expected fast ErrCircuitOpen, got %v", err)
+	}
+	if atomic.LoadInt32(&calls) != before {
+		t.Fatalf("open breaker must not reach the server")
+	}
+
+	healthy.Store(true)
+	time.Sleep(60 * time.Millisecond)
+	if err := c.AddComment(context.Background(), "1", comment); err != nil {
+		t.Fatalf("probe request after cooldown failed: %v", err)
+	}
+	if c.BreakerState() != "closed" {
+		t.Fatalf("expected closed breaker after successful probe, got %s", c.BreakerState())
+	}
+}
+
+// TestCircuitBreakerIgnoresClientErrors проверяет, что ответы 4xx не размыкают выключатель.
+func TestCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "bad", http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	c := NewClient("token", "board", 2*time.Second, &metrics.Metrics{})
+	c.baseURL = ts.URL
+	c.httpClient = ts.Client()
+	c.SetCircuitBreaker(2, time.Minute)
+
+	for i := 0; i < 5; i++ {
+		if err := c.AddComment(context.Background(), "1", &models.Comment{Text: "x"}); IsUnavailable(err) {
+			t.Fatalf("4xx must not be reported as unavailability: %v", err)
+		}
+	}
+	if c.BreakerState() != "closed" {
+		t.Fatalf("expected closed breaker, got %s", c.BreakerState())
+	}
+}
@@ -0,0 +1,125 @@
+// Package api содержит тесты и вспомогательные функции для клиента Yougile API.
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"yougile_bot4/internal/metrics"
+	"yougile_bot4/internal/models"
+)
+
+// memCapabilityStore — хранилище возможностей API в памяти для тестов.
+type memCapabilityStore struct {
+	caps models.APICapabilities
+}
+
+func (m *memCapabilityStore) GetAPICapabilities() models.APICapabilities { return m.caps }
+
+func (m *memCapabilityStore) SetAPICapabilities(caps models.APICapabilities) { m.caps = caps }
+
+// TestGetTasksProbesOnceAndReusesStrategy проверяет, что стратегия определяется один раз
+// и последующие вызовы идут сразу в рабочий эндпоинт.
+func TestGetTasksProbesOnceAndReusesStrategy(t *testing.T) {
+	calls := map[string]int{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls[r.URL.Path]++
+		if r.URL.Path == "/api-v2/task-list" && r.Method == "GET" {
+			if _, err := io.WriteString(w, `{"data": [{"id": 1, "title": "a", "idTaskProject": "ITS-1"}]}`); err != nil {
+				t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+			}
+			return
+		}
+		http.Error(w, "bad request", http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	store := &memCapabilityStore{caps: models.APICapabilities{}}
+	c := NewClient("token", "board", 2*time.Second, &metrics.Metrics{})
+	c.baseURL = ts.URL
+	c.httpClient = ts.Client()
+	c.retryWait = 10 * time.Millisecond
+	c.cache.Expiration = 0
+	c.SetCapabilityStore(store)
+
+	tasks, err := c.GetTasks(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("GetTasks failed: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].Key != "ITS-1" {
+		t.Fatalf("unexpected tasks: %+v", tasks)
+	}
+	if calls["/api-v2/tasks"] != 1 || calls["/api-v2/task-list"] != 1 {
+		t.Fatalf("unexpected probe calls: %v", calls)
+	}
+
+	capability, ok := store.caps[capabilityScope("board", "")]
+	if !ok || capability.Strategy != "task_list_get" {
+		t.Fatalf("expected persisted strategy task_list_get, got %+v", store.caps)
+	}
+
+	if _, err := c.GetTasks(context.Background(), 10); err != nil {
+		t.Fatalf("second GetTasks failed: %v", err)
+	}
+	if calls["/api-v2/tasks"] != 1 || calls["/api-v2/task-list"] != 2 {
+		t.Fatalf("expected cached strategy to be reused, calls: %v", calls)
+	}
+}
+
+// TestGetTasksReprobesWhenStrategyFails проверяет повторный опрос, когда сохранённая
+// стратегия начала возвращать 4xx.
+func TestGetTasksReprobesWhenStrategyFails(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api-v2/tasks" && r.Method == "GET" {
+			if _, err := io.WriteString(w, `{"data": []}`); err != nil {
+				t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+			}
+			return
+		}
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	store := &memCapabilityStore{caps: models.APICapabilities{
+		capabilityScope("board", ""): {Strategy: "task_list_post", BoardID: "board"},
+	}}
+	c := NewClient("token", "board", 2*time.Second, &metrics.Metrics{})
+	c.baseURL = ts.URL
+	c.httpClient = ts.Client()
+	c.retryWait = 10 * time.Millisecond
+	c.SetCapabilityStore(store)
+
+	if _, err := c.GetTasks(context.Background(), 10); err != nil {
+		t.Fatalf("GetTasks failed: %v", err)
+	}
+	if got := c.Capabilities()[capabilityScope("board", "")].Strategy; got != "tasks_get" {
+		t.Fatalf("expected strategy tasks_get after reprobe, got %q", got)
+	}
+}
+
+// TestGetTasksDoesNotReprobeOnServerError проверяет, что 5xx не запускает перебор стратегий.
+func TestGetTasksDoesNotReprobeOnServerError(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		http.Error(w, "server error", http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	c := NewClient("token", "board", 2*time.Second, &metrics.Metrics{})
+	c.baseURL = ts.URL
+	c.httpClient = ts.Client()
+	c.retryCount = 2
+	c.retryWait = 10 * time.Millisecond
+
+	if _, err := c.GetTasks(context.Background(), 10); err == nil {
+		t.Fatalf("expected error")
+	}
+	if calls != 2 {
+		t.Fatalf("expected only retries of the first strategy (2 calls), got %d", calls)
+	}
+}
@@ -0,0 +1,154 @@
+// Package api содержит тесты и вспомогательные функции для клиента Yougile API.
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"yougile_bot4/internal/metrics"
+)
+
+func TestRecorderRedactsSecretsAndReplays(t *testing.T) {
+	const secret = "secret-key"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api-v2/tasks/t-1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer "+secret {
+			t.Errorf("unexpected Authorization %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := io.WriteString(w, `{"id":"t-1","title":"Записано","note":"`+secret+`"}`); err != nil {
+			t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+		}
+	}))
+	defer ts.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	c := NewClient(secret, "", 0, &metrics.Metrics{})
+	c.baseURL = ts.URL
+	recorder := NewRecorder(path, ts.Client().Transport, secret)
+	defer recorder.Close()
+	c.SetTransport(recorder)
+	recorded, err := c.GetTaskByID(context.Background(), "t-1")
+	if err != nil {
+		t.Fatalf("GetTaskByID failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read cassette: %v", err)
+	}
+	if strings.Contains(string(data), secret) {
+		t.Fatalf("cassette must not contain the token:\n%s", data)
+	}
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("LoadCassette failed: %v", err)
+	}
+	if len(cassette.Interactions) != 1 {
+		t.Fatalf("expected 1 interaction, got %d", len(cassette.Interactions))
+	}
+	in := cassette.Interactions[0]
+	if in.Request.URL != "/api-v2/tasks/t-1" || in.Request.Header.Get("Authorization") != redacted || in.Response.Status != http.StatusOK {
+		t.Fatalf("unexpected interaction %+v", in)
+	}
+
+	// воспроизведение не обращается к сети: хост клиента недоступен
+	replay := NewClient(secret, "", 0, &metrics.Metrics{})
+	replay.baseURL = "http://yougile.invalid"
+	replayer := NewReplayer(cassette)
+	replay.SetTransport(replayer)
+	got, err := replay.GetTaskByID(context.Background(), "t-1")
+	if err != nil {
+		t.Fatalf("replayed GetTaskByID failed: %v", err)
+	}
+	if got.ExternalID != recorded.ExternalID || got.Title != "Записано" || replayer.Remaining() != 0 {
+		t.Fatalf("unexpected replayed task %+v (remaining %d)", got, replayer.Remaining())
+	}
+	if _, err := replayer.RoundTrip(httptest.NewRequest(http.MethodGet, "/api-v2/tasks/t-1", nil)); !errors.Is(err, ErrNoInteraction) {
+		t.Fatalf("expected ErrNoInteraction for an exhausted cassette, got %v", err)
+	}
+}
+
+// TestCassetteBoardScopedFallback воспроизводит инсталляцию, где задача доступна
+// только через /api-v2/board/{board}/tasks/{id}.
+func TestCassetteBoardScopedFallback(t *testing.T) {
+	cassette, err := LoadCassette("testdata/cassettes/task_board_fallback.json")
+	if err != nil {
+		t.Fatalf("LoadCassette failed: %v", err)
+	}
+	c := NewClient("token", "b1", 0, &metrics.Metrics{})
+	c.retryWait = 10 * time.Millisecond
+	replayer := NewReplayer(cassette)
+	c.SetTransport(replayer)
+
+	task, err := c.GetTaskByIDQuiet(context.Background(), "t-42")
+	if err != nil {
+		t.Fatalf("GetTaskByID failed: %v", err)
+	}
+	if task.ExternalID != "t-42" || task.Key != "ITS-42" || task.Title != "Заявка из кассеты" {
+		t.Fatalf("unexpected task %+v", task)
+	}
+	if replayer.Remaining() != 0 {
+		t.Fatalf("expected both interactions to be replayed, %d left", replayer.Remaining())
+	}
+}
+
+// TestRecorderAppendsInteractions проверяет, что обмены дописываются в кассету
+// по строке, не перезаписывая уже записанные.
+func TestRecorderAppendsInteractions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.WriteString(w, `{"id":"`+strings.TrimPrefix(r.URL.Path, "/api-v2/tasks/")+`"}`); err != nil {
+			t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+		}
+	}))
+	defer ts.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.jsonl")
+	if err := os.WriteFile(path, []byte("старая запись\n"), 0600); err != nil {
+		t.Fatalf("write cassette: %v", err)
+	}
+	recorder := NewRecorder(path, ts.Client().Transport)
+	c := NewClient("token", "", 0, &metrics.Metrics{})
+	c.baseURL = ts.URL
+	c.SetTransport(recorder)
+	for _, id := range []string{"t-1", "t-2"} {
+		if _, err := c.GetTaskByIDQuiet(context.Background(), id); err != nil {
+			t.Fatalf("GetTaskByID(%s) failed: %v", id, err)
+		}
+		if id == "t-1" {
+			// после Close запись продолжается в тот же файл
+			if err := recorder.Close(); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+		}
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read cassette: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 2 {
+		t.Fatalf("expected one line per interaction, got:\n%s", data)
+	}
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("LoadCassette failed: %v", err)
+	}
+	if len(cassette.Interactions) != 2 || cassette.Interactions[1].Request.URL != "/api-v2/tasks/t-2" {
+		t.Fatalf("unexpected interactions %+v", cassette.Interactions)
+	}
+}
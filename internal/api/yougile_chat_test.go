@@ -0,0 +1,87 @@
+// Package api содержит тесты и вспомогательные функции для клиента Yougile API.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestListChatMessagesFiltersAndSorts(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api-v2/chats/task-1/messages" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if got := r.URL.Query().Get("since"); got != "1700000000000" {
+			t.Errorf("expected since=1700000000000, got %q", got)
+		}
+		var resp map[string]interface{}
+		if r.URL.Query().Get("offset") == "0" {
+			resp = map[string]interface{}{
+				"paging": map[string]interface{}{"count": 3, "limit": 2, "offset": 0, "next": true},
+				"content": []map[string]interface{}{
+					{"id": 1700000000300, "fromUserId": "u1", "text": "третье"},
+					{"id": 1700000000100, "fromUserId": "u2", "text": "удалено", "deleted": true},
+				},
+			}
+		} else {
+			resp = map[string]interface{}{
+				"paging": map[string]interface{}{"count": 3, "limit": 2, "offset": 2, "next": false},
+				"content": []map[string]interface{}{
+					{"id": 1700000000200, "fromUserId": "u1", "text": "второе"},
+				},
+			}
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+		}
+	}))
+	defer ts.Close()
+
+	c := newStructureTestClient(ts)
+	got, err := c.ListChatMessages(context.Background(), "task-1", time.UnixMilli(1700000000000))
+	if err != nil {
+		t.Fatalf("ListChatMessages failed: %v", err)
+	}
+	if len(got) != 2 || got[0].Text != "второе" || got[1].Text != "третье" {
+		t.Fatalf("expected two messages in creation order, got %+v", got)
+	}
+	if !got[0].CreatedAt().Equal(time.UnixMilli(1700000000200)) {
+		t.Fatalf("unexpected creation time %v", got[0].CreatedAt())
+	}
+}
+
+func TestSendChatMessageEscapesHTML(t *testing.T) {
+	var body map[string]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api-v2/chats/task-1/messages" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode message: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		if _, err := io.WriteString(w, `{"id":1700000000500}`); err != nil {
+			t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+		}
+	}))
+	defer ts.Close()
+
+	c := newStructureTestClient(ts)
+	id, err := c.SendChatMessage(context.Background(), "task-1", "a < b\nготово")
+	if err != nil {
+		t.Fatalf("SendChatMessage failed: %v", err)
+	}
+	if id != 1700000000500 {
+		t.Fatalf("expected id 1700000000500, got %d", id)
+	}
+	if body["text"] != "a < b\nготово" || body["textHtml"] != "a &lt; b<br>готово" {
+		t.Fatalf("unexpected payload %v", body)
+	}
+}
@@ -0,0 +1,74 @@
+// Package api содержит тесты и вспомогательные функции для клиента Yougile API.
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"yougile_bot4/internal/metrics"
+	"yougile_bot4/internal/models"
+)
+
+// TestRetryBackoffInterruptedByCancel проверяет, что отмена контекста прерывает
+// ожидание между повторами, а не дожидается окончания backoff.
+func TestRetryBackoffInterruptedByCancel(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		http.Error(w, "server error", http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	c := NewClient("token", "board", 2*time.Second, &metrics.Metrics{})
+	c.baseURL = ts.URL
+	c.httpClient = ts.Client()
+	c.SetRetryPolicy(5, 5*time.Second, time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := c.CreateTask(ctx, &models.Task{Title: "t"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("cancellation took too long: %v", elapsed)
+	}
+	if calls != 1 {
+		t.Fatalf("expected a single attempt before cancellation, got %d", calls)
+	}
+}
+
+// TestRequestCancelledInFlight проверяет, что отмена прерывает уже отправленный запрос.
+func TestRequestCancelledInFlight(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	c := NewClient("token", "board", 10*time.Second, &metrics.Metrics{})
+	c.baseURL = ts.URL
+	c.httpClient = ts.Client()
+	c.retryWait = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	if _, err := c.GetTaskByIDQuiet(ctx, "ITS-1"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("in-flight request was not cancelled: %v", elapsed)
+	}
+}
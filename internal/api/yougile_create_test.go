@@ -2,9 +2,12 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -39,7 +42,7 @@ func TestCreateTaskRetriesAndParseID(t *testing.T) {
 
 	task := &models.Task{Title: "hello"}
 
-	if err := c.CreateTask(task); err != nil {
+	if err := c.CreateTask(context.Background(), task); err != nil {
 		t.Fatalf("expected success, got error: %v", err)
 	}
 
@@ -51,3 +54,36 @@ func TestCreateTaskRetriesAndParseID(t *testing.T) {
 		t.Fatalf("expected at least 2 calls, got %d", calls)
 	}
 }
+
+// TestCreateTaskNotRetriedAfterTimeout проверяет, что POST, оставшийся без ответа
+// до таймаута клиента, не повторяется: сервер мог уже создать задачу.
+func TestCreateTaskNotRetriedAfterTimeout(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	c := NewClient("token", "board", 2*time.Second, &metrics.Metrics{})
+	c.baseURL = ts.URL
+	c.httpClient = ts.Client()
+	c.httpClient.Timeout = 50 * time.Millisecond
+	c.SetRetryPolicy(3, 10*time.Millisecond, time.Minute)
+
+	err := c.CreateTask(context.Background(), &models.Task{Title: "t"})
+	if !errors.Is(err, ErrUncertain) {
+		t.Fatalf("expected ErrUncertain, got %v", err)
+	}
+	if IsUnavailable(err) {
+		t.Fatalf("uncertain create must not be queued for replay: %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("expected a single POST, got %d", n)
+	}
+}
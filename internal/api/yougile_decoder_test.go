@@ -0,0 +1,95 @@
+// Package api содержит тесты и вспомогательные функции для клиента Yougile API.
+package api
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("read fixture %s: %v", name, err)
+	}
+	return data
+}
+
+func TestDecodeTaskMapsYougileFields(t *testing.T) {
+	task, err := DecodeTask(readFixture(t, "task_uuid.json"))
+	if err != nil {
+		t.Fatalf("DecodeTask failed: %v", err)
+	}
+	if task.ID != 0 || task.ExternalID != "2f0c1a6e-5d2b-4c1e-9a7f-3b8e0d4c2a11" {
+		t.Fatalf("unexpected ids: id=%d external=%q", task.ID, task.ExternalID)
+	}
+	if task.Title != "Не работает принтер" || task.Description != "Кабинет 101" || task.ColumnID != "col-1" {
+		t.Fatalf("unexpected text fields: %+v", task)
+	}
+	if !task.Done || !task.Archived || task.Deleted {
+		t.Fatalf("unexpected flags: done=%v archived=%v deleted=%v", task.Done, task.Archived, task.Deleted)
+	}
+	if task.Key != "ITS-2904" {
+		t.Fatalf("expected key from idTaskProject, got %q", task.Key)
+	}
+	if !task.CreatedAt.Equal(time.UnixMilli(1700000000000)) || !task.DueDate.Equal(time.UnixMilli(1700086400000)) {
+		t.Fatalf("unexpected times: created=%v due=%v", task.CreatedAt, task.DueDate)
+	}
+	if len(task.Assigned) != 2 || task.Assigned[1] != "u2" || task.Stickers["s-room"] != "101" {
+		t.Fatalf("unexpected assigned/stickers: %v %v", task.Assigned, task.Stickers)
+	}
+	if len(task.Extra) != 2 || string(task.Extra["createdBy"]) != `"u3"` || task.Extra["checklists"] == nil {
+		t.Fatalf("expected unknown fields in Extra, got %v", task.Extra)
+	}
+}
+
+func TestDecodeTaskWrappedNumericID(t *testing.T) {
+	task, err := DecodeTask(readFixture(t, "task_numeric_wrapped.json"))
+	if err != nil {
+		t.Fatalf("DecodeTask failed: %v", err)
+	}
+	if task.ID != 15 || task.ExternalID != "" || task.Key != "15" {
+		t.Fatalf("unexpected ids: id=%d external=%q key=%q", task.ID, task.ExternalID, task.Key)
+	}
+	if task.ColumnID != "col-2" || !task.Done {
+		t.Fatalf("unexpected fields: %+v", task)
+	}
+	if !task.CreatedAt.Equal(time.UnixMilli(1700000000000)) {
+		t.Fatalf("expected timestamp from string, got %v", task.CreatedAt)
+	}
+	if !task.DueDate.IsZero() || !task.HasDeadline {
+		t.Fatalf("deleted deadline must be reported as removed, got %v (present=%v)", task.DueDate, task.HasDeadline)
+	}
+	if len(task.Extra) != 0 {
+		t.Fatalf("unexpected Extra %v", task.Extra)
+	}
+
+	if _, err := DecodeTask([]byte(`[1, 2]`)); err == nil {
+		t.Fatalf("expected error for non-object JSON")
+	}
+}
+
+func TestDecodeTaskList(t *testing.T) {
+	tasks, paging, err := decodeTaskList(readFixture(t, "task_list.json"))
+	if err != nil {
+		t.Fatalf("decodeTaskList failed: %v", err)
+	}
+	if paging == nil || paging.Count != 2 || paging.Next {
+		t.Fatalf("unexpected paging %+v", paging)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("expected 2 tasks, got %d", len(tasks))
+	}
+	if tasks[0].ExternalID != "t-1" || tasks[0].Key != "ITS-1" || tasks[0].Done {
+		t.Fatalf("unexpected first task %+v", tasks[0])
+	}
+	if tasks[1].ID != 2 || len(tasks[1].Labels) != 1 || !tasks[1].CreatedAt.Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("unexpected second task %+v", tasks[1])
+	}
+
+	tasks, paging, err = decodeTaskList([]byte(`[{"id": "a"}, {"id": 3}]`))
+	if err != nil || paging != nil || len(tasks) != 2 || tasks[1].ID != 3 || tasks[1].HasDeadline {
+		t.Fatalf("unexpected bare array result: %v %v %v", tasks, paging, err)
+	}
+}
@@ -0,0 +1,121 @@
+// Package api содержит тесты и вспомогательные функции для клиента Yougile API.
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"yougile_bot4/internal/metrics"
+	"yougile_bot4/internal/models"
+)
+
+func newErrorTestClient(ts *httptest.Server) *Client {
+	c := NewClient("token", "board", 2*time.Second, &metrics.Metrics{})
+	c.baseURL = ts.URL
+	c.httpClient = ts.Client()
+	c.retryWait = 10 * time.Millisecond
+	return c
+}
+
+// TestCreateTaskUnauthorizedIsTyped проверяет, что 401 возвращается как *APIError,
+// сопоставляется с ErrUnauthorized и не повторяется.
+func TestCreateTaskUnauthorizedIsTyped(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.Header().Set("X-Request-Id", "req-42")
+		http.Error(w, `{"error":"key revoked"}`, http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+	c := newErrorTestClient(ts)
+
+	err := c.CreateTask(context.Background(), &models.Task{Title: "t"})
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %T", err)
+	}
+	if apiErr.StatusCode != http.StatusUnauthorized || apiErr.Method != http.MethodPost ||
+		apiErr.Endpoint != "/api-v2/tasks" || apiErr.RequestID != "req-42" || apiErr.Retryable {
+		t.Fatalf("unexpected APIError fields: %+v", apiErr)
+	}
+	if !strings.Contains(apiErr.Body, "key revoked") {
+		t.Fatalf("expected body in APIError, got %q", apiErr.Body)
+	}
+	if calls != 1 {
+		t.Fatalf("401 must not be retried, got %d calls", calls)
+	}
+}
+
+// TestServerErrorIsRetryable проверяет повторы и классификацию ответа 5xx.
+func TestServerErrorIsRetryable(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+	c := newErrorTestClient(ts)
+	c.retryCount = 2
+
+	err := c.AddComment(context.Background(), "123", &models.Comment{Text: "hi"})
+	if !errors.Is(err, ErrUnavailable) || !IsRetryable(err) {
+		t.Fatalf("expected retryable ErrUnavailable, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 attempts, got %d", calls)
+	}
+}
+
+// TestGetTasksUnauthorizedStopsProbing проверяет, что 401 не запускает перебор стратегий.
+func TestGetTasksUnauthorizedStopsProbing(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+	c := newErrorTestClient(ts)
+
+	if _, err := c.GetTasks(context.Background(), 10); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected a single request, got %d", calls)
+	}
+	if len(c.Capabilities()) != 0 {
+		t.Fatalf("no strategy must be cached after 401: %+v", c.Capabilities())
+	}
+}
+
+// TestGetTaskByIDNotFound проверяет сопоставление 404 с ErrNotFound.
+func TestGetTaskByIDNotFound(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.NotFound(w, nil)
+	}))
+	defer ts.Close()
+	c := newErrorTestClient(ts)
+
+	if _, err := c.GetTaskByIDQuiet(context.Background(), "ITS-1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+// TestTruncateBodyKeepsUTF8 проверяет усечение тела без разрыва многобайтовых символов.
+func TestTruncateBodyKeepsUTF8(t *testing.T) {
+	body := strings.Repeat("я", maxErrorBody)
+	got := truncateBody([]byte(body))
+	if !strings.HasSuffix(got, "…") || len(got) > maxErrorBody+len("…") {
+		t.Fatalf("unexpected truncation length %d", len(got))
+	}
+	if strings.ContainsRune(got, '�') {
+		t.Fatalf("truncation broke a UTF-8 sequence")
+	}
+}
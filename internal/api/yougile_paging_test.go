@@ -0,0 +1,141 @@
+// Package api содержит тесты и вспомогательные функции для клиента Yougile API.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"yougile_bot4/internal/metrics"
+)
+
+// newPagingServer возвращает сервер, отдающий total задач постранично в формате Yougile.
+// Каждая третья задача помечена выполненной.
+func newPagingServer(t *testing.T, total int, honourOffset bool, requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		if !honourOffset {
+			offset = 0
+		}
+		content := []map[string]interface{}{}
+		for i := offset; i < total && i < offset+limit; i++ {
+			content = append(content, map[string]interface{}{
+				"id":    i + 1,
+				"title": "task " + strconv.Itoa(i+1),
+				"done":  (i+1)%3 == 0,
+			})
+		}
+		resp := map[string]interface{}{
+			"paging":  map[string]interface{}{"count": len(content), "limit": limit, "offset": offset, "next": offset+limit < total},
+			"content": content,
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+		}
+	}))
+}
+
+func newPagingClient(ts *httptest.Server) *Client {
+	c := NewClient("token", "board", 2*time.Second, &metrics.Metrics{})
+	c.baseURL = ts.URL
+	c.httpClient = ts.Client()
+	c.retryWait = 10 * time.Millisecond
+	return c
+}
+
+// TestIterateTasksFollowsPaging проверяет обход всех страниц по метаданным paging.
+func TestIterateTasksFollowsPaging(t *testing.T) {
+	requests := 0
+	ts := newPagingServer(t, 5, true, &requests)
+	defer ts.Close()
+	c := newPagingClient(ts)
+
+	it := c.IterateTasks(context.Background(), TaskFilter{BoardID: "board", PageSize: 2})
+	count := 0
+	for it.Next() {
+		count++
+		if it.Task().ID != int64(count) {
+			t.Fatalf("unexpected task order: got ID %d at position %d", it.Task().ID, count)
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 5 {
+		t.Fatalf("expected 5 tasks, got %d", count)
+	}
+	if requests != 3 {
+		t.Fatalf("expected 3 page requests, got %d", requests)
+	}
+}
+
+// TestIterateTasksCompletedFilter проверяет локальную фильтрацию по статусу выполнения.
+func TestIterateTasksCompletedFilter(t *testing.T) {
+	requests := 0
+	ts := newPagingServer(t, 7, true, &requests)
+	defer ts.Close()
+	c := newPagingClient(ts)
+
+	notDone := false
+	it := c.IterateTasks(context.Background(), TaskFilter{BoardID: "board", PageSize: 3, Completed: &notDone})
+	count := 0
+	for it.Next() {
+		if it.Task().Done {
+			t.Fatalf("completed task %d passed the filter", it.Task().ID)
+		}
+		count++
+	}
+	if count != 5 {
+		t.Fatalf("expected 5 open tasks, got %d", count)
+	}
+}
+
+// TestIterateTasksStopsWhenOffsetIgnored проверяет, что обход не зацикливается,
+// если сервер игнорирует offset.
+func TestIterateTasksStopsWhenOffsetIgnored(t *testing.T) {
+	requests := 0
+	ts := newPagingServer(t, 10, false, &requests)
+	defer ts.Close()
+	c := newPagingClient(ts)
+
+	it := c.IterateTasks(context.Background(), TaskFilter{BoardID: "board", PageSize: 4})
+	count := 0
+	for it.Next() {
+		count++
+	}
+	if count != 4 {
+		t.Fatalf("expected only the first page (4 tasks), got %d", count)
+	}
+	if requests != 2 {
+		t.Fatalf("expected 2 requests, got %d", requests)
+	}
+}
+
+// TestIterateTasksCancelled проверяет прерывание обхода по контексту.
+func TestIterateTasksCancelled(t *testing.T) {
+	requests := 0
+	ts := newPagingServer(t, 10, true, &requests)
+	defer ts.Close()
+	c := newPagingClient(ts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	it := c.IterateTasks(ctx, TaskFilter{BoardID: "board", PageSize: 2})
+	if !it.Next() {
+		t.Fatalf("expected first task, err: %v", it.Err())
+	}
+	cancel()
+	for it.Next() {
+	}
+	if it.Err() != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", it.Err())
+	}
+	if requests != 1 {
+		t.Fatalf("expected no requests after cancel, got %d", requests)
+	}
+}
@@ -0,0 +1,120 @@
+// Package api содержит тесты и вспомогательные функции для клиента Yougile API.
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"yougile_bot4/internal/metrics"
+	"yougile_bot4/internal/models"
+)
+
+// TestParseRetryAfter проверяет разбор заголовка Retry-After в обоих форматах.
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cases := map[string]time.Duration{
+		"":                              0,
+		"3":                             3 * time.Second,
+		"-1":                            0,
+		"soon":                          0,
+		"Mon, 01 Jan 2024 12:00:30 GMT": 30 * time.Second,
+		"Mon, 01 Jan 2024 11:00:00 GMT": 0,
+	}
+	for in, want := range cases {
+		if got := parseRetryAfter(in, now); got != want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+// TestRetryAfterIsHonoured проверяет, что повтор после 429 выполняется не раньше Retry-After.
+func TestRetryAfterIsHonoured(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		if _, err := w.Write([]byte(`{"id": "abc"}`)); err != nil {
+			t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+		}
+	}))
+	defer ts.Close()
+
+	m := &metrics.Metrics{}
+	c := NewClient("token", "board", 2*time.Second, m)
+	c.baseURL = ts.URL
+	c.httpClient = ts.Client()
+	c.retryWait = 10 * time.Millisecond
+	c.SetRateLimit(600)
+
+	start := time.Now()
+	if err := c.CreateTask(context.Background(), &models.Task{Title: "t"}); err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("retry happened before Retry-After elapsed: %v", elapsed)
+	}
+	if calls != 2 || m.APIRateLimited != 1 {
+		t.Fatalf("unexpected calls=%d rate_limited=%d", calls, m.APIRateLimited)
+	}
+}
+
+// TestRetryAfterBeyondBudgetFailsFast проверяет, что слишком долгий Retry-After
+// не блокирует вызывающего дольше maxRetryElapsed.
+func TestRetryAfterBeyondBudgetFailsFast(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	c := NewClient("token", "board", 2*time.Second, &metrics.Metrics{})
+	c.baseURL = ts.URL
+	c.httpClient = ts.Client()
+	c.retryWait = 10 * time.Millisecond
+
+	start := time.Now()
+	err := c.AddComment(context.Background(), "1", &models.Comment{Text: "x"})
+	if !errors.Is(err, ErrRateLimit) {
+		t.Fatalf("expected ErrRateLimit, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("caller was blocked for %v", elapsed)
+	}
+}
+
+// TestBackgroundRequestsKeepReserve проверяет, что фоновые запросы не расходуют
+// резерв, оставленный запросам пользователей.
+func TestBackgroundRequestsKeepReserve(t *testing.T) {
+	m := &metrics.Metrics{}
+	l := newRateLimiter(600, m) // корзина 60, резерв 30
+	fixed := time.Now()
+	l.now = func() time.Time { return fixed }
+	l.last = fixed
+
+	bg := Background(context.Background())
+	for i := 0; i < 30; i++ {
+		if err := l.wait(bg); err != nil {
+			t.Fatalf("background request %d blocked: %v", i, err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(bg, 20*time.Millisecond)
+	defer cancel()
+	if err := l.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("background request must wait for the reserve, got %v", err)
+	}
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatalf("foreground request must use the reserve: %v", err)
+	}
+	if m.APIRateBudget != 29 {
+		t.Fatalf("expected budget 29 in metrics, got %d", m.APIRateBudget)
+	}
+}
@@ -0,0 +1,128 @@
+// Package api содержит тесты и вспомогательные функции для клиента Yougile API.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"yougile_bot4/internal/models"
+)
+
+// TestSearchTasksFiltersLocally проверяет, что фильтр передаётся в API и
+// применяется локально, если сервер параметр title игнорирует.
+func TestSearchTasksFiltersLocally(t *testing.T) {
+	base := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	var titles []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api-v2/tasks" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		titles = append(titles, r.URL.Query().Get("title"))
+		resp := map[string]interface{}{
+			"paging": map[string]interface{}{"count": 4, "limit": 50, "offset": 0, "next": false},
+			"content": []map[string]interface{}{
+				{"id": "t1", "title": "Сломан ПРИНТЕР в 101", "columnId": "c1", "timestamp": base.Add(-10 * 24 * time.Hour).UnixMilli()},
+				{"id": "t2", "title": "Принтер не печатает", "columnId": "c1", "timestamp": base.Add(-2 * 24 * time.Hour).UnixMilli()},
+				{"id": "t3", "title": "Принтер в 205", "columnId": "c1", "completed": true, "timestamp": base.Add(-1 * 24 * time.Hour).UnixMilli()},
+				{"id": "t4", "title": "Нет интернета", "columnId": "c1", "timestamp": base.UnixMilli()},
+			},
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+		}
+	}))
+	defer ts.Close()
+
+	c := newPagingClient(ts)
+	open := false
+	got, err := c.SearchTasks(context.Background(), TaskFilter{BoardID: "board", Title: "принтер", Completed: &open}, 0)
+	if err != nil {
+		t.Fatalf("SearchTasks failed: %v", err)
+	}
+	if len(got) != 2 || got[0].ExternalID != "t2" || got[1].ExternalID != "t1" {
+		t.Fatalf("expected open printer tasks newest first, got %+v", got)
+	}
+	if len(titles) != 1 || titles[0] != "принтер" {
+		t.Fatalf("expected title parameter in request, got %v", titles)
+	}
+
+	got, err = c.SearchTasks(context.Background(), TaskFilter{BoardID: "board", Title: "принтер", CreatedAfter: base.Add(-7 * 24 * time.Hour)}, 1)
+	if err != nil {
+		t.Fatalf("SearchTasks failed: %v", err)
+	}
+	if len(got) != 1 || got[0].ExternalID != "t3" {
+		t.Fatalf("expected the newest recent match only, got %+v", got)
+	}
+}
+
+// TestSearchTasksKeepsNewestAcrossPages проверяет, что поиск просматривает все
+// страницы: самая новая задача на последней странице не теряется из-за лимита.
+func TestSearchTasksKeepsNewestAcrossPages(t *testing.T) {
+	base := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	pages := [][]map[string]interface{}{
+		{
+			{"id": "old1", "title": "Принтер зажевал бумагу", "timestamp": base.Add(-30 * 24 * time.Hour).UnixMilli()},
+			{"id": "old2", "title": "Принтер не видит картридж", "timestamp": base.Add(-20 * 24 * time.Hour).UnixMilli()},
+			{"id": "mid", "title": "Принтер в 205", "timestamp": base.Add(-10 * 24 * time.Hour).UnixMilli()},
+		},
+		{
+			{"id": "other", "title": "Нет интернета", "timestamp": base.UnixMilli()},
+			{"id": "new", "title": "Принтер печатает полосами", "timestamp": base.Add(-7 * 24 * time.Hour).UnixMilli()},
+		},
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := 0
+		if r.URL.Query().Get("offset") != "" {
+			page = 1
+		}
+		resp := map[string]interface{}{
+			"paging":  map[string]interface{}{"count": 5, "limit": 3, "offset": page * 3, "next": page == 0},
+			"content": pages[page],
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+		}
+	}))
+	defer ts.Close()
+
+	c := newPagingClient(ts)
+	got, err := c.SearchTasks(context.Background(), TaskFilter{BoardID: "board", Title: "принтер", PageSize: 3}, 2)
+	if err != nil {
+		t.Fatalf("SearchTasks failed: %v", err)
+	}
+	if len(got) != 2 || got[0].ExternalID != "new" || got[1].ExternalID != "mid" {
+		t.Fatalf("expected the two newest matches from all pages, got %+v", got)
+	}
+}
+
+// TestSearchTasksFallsBackToCache проверяет поиск по кэшу при недоступном Yougile.
+func TestSearchTasksFallsBackToCache(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	c := newPagingClient(ts)
+	c.retryCount = 1
+	c.cache.Tasks = []models.Task{
+		{ExternalID: "a", Title: "Заменить картридж принтера"},
+		{ExternalID: "b", Title: "Настроить почту"},
+	}
+	got, err := c.SearchTasks(context.Background(), TaskFilter{BoardID: "board", Title: "ПРИНТЕР"}, 10)
+	if err != nil {
+		t.Fatalf("expected cache fallback, got %v", err)
+	}
+	if len(got) != 1 || got[0].ExternalID != "a" {
+		t.Fatalf("unexpected cached result %+v", got)
+	}
+
+	c.cache.Tasks = nil
+	if _, err := c.SearchTasks(context.Background(), TaskFilter{BoardID: "board", Title: "принтер"}, 10); err == nil {
+		t.Fatalf("expected error without cache")
+	}
+}
@@ -0,0 +1,91 @@
+// Package api содержит тесты и вспомогательные функции для клиента Yougile API.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolveStickersMatchesAndCreatesStates(t *testing.T) {
+	lists, created := 0, ""
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api-v2/string-stickers":
+			lists++
+			if got := r.URL.Query().Get("boardId"); got != "b1" {
+				t.Errorf("expected boardId=b1, got %q", got)
+			}
+			resp := map[string]interface{}{
+				"paging": map[string]interface{}{"count": 2, "limit": 50, "offset": 0, "next": false},
+				"content": []map[string]interface{}{
+					{"id": "s-building", "name": "Здание", "states": []map[string]interface{}{
+						{"id": "st1", "name": "Корпус 1"},
+						{"id": "st-old", "name": "Корпус 2", "deleted": true},
+					}},
+					{"id": "s-room", "name": "Кабинет"},
+				},
+			}
+			if err := json.NewEncoder(w).Encode(resp); err != nil {
+				t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+			}
+		case r.Method == http.MethodPost && r.URL.Path == "/api-v2/string-stickers/s-building/states":
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decode state: %v", err)
+			}
+			created = body["name"]
+			w.WriteHeader(http.StatusCreated)
+			if _, err := io.WriteString(w, `{"id":"st-new"}`); err != nil {
+				t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c := newStructureTestClient(ts)
+	got, err := c.ResolveStickers(context.Background(), "b1", map[string]string{
+		"s-building": "корпус 1",
+		"s-room":     "101",
+		"s-free":     "без определения",
+		"s-empty":    " ",
+	}, false)
+	if err != nil {
+		t.Fatalf("ResolveStickers failed: %v", err)
+	}
+	want := map[string]string{"s-building": "st1", "s-room": "101", "s-free": "без определения"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Fatalf("sticker %s: expected %q, got %q", k, v, got[k])
+		}
+	}
+
+	// без разрешения состояние не создаётся, значение пропускается
+	got, err = c.ResolveStickers(context.Background(), "b1", map[string]string{"s-building": "Корпус 2", "s-room": "102"}, false)
+	if err != nil {
+		t.Fatalf("ResolveStickers failed: %v", err)
+	}
+	if _, ok := got["s-building"]; ok || got["s-room"] != "102" || created != "" {
+		t.Fatalf("expected missing state to be skipped without creation, got %v (created %q)", got, created)
+	}
+
+	// удалённое состояние не используется: создаётся новое
+	got, err = c.ResolveStickers(context.Background(), "b1", map[string]string{"s-building": "Корпус 2"}, true)
+	if err != nil {
+		t.Fatalf("ResolveStickers failed: %v", err)
+	}
+	if got["s-building"] != "st-new" || created != "Корпус 2" {
+		t.Fatalf("expected created state st-new for «Корпус 2», got %v (created %q)", got, created)
+	}
+	if lists != 1 {
+		t.Fatalf("expected sticker definitions to be cached, got %d list requests", lists)
+	}
+}
@@ -0,0 +1,155 @@
+// Package api содержит тесты и вспомогательные функции для клиента Yougile API.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"yougile_bot4/internal/metrics"
+	"yougile_bot4/internal/models"
+)
+
+func newStructureTestClient(ts *httptest.Server) *Client {
+	c := NewClient("token", "b1", 0, &metrics.Metrics{})
+	c.baseURL = ts.URL
+	c.httpClient = ts.Client()
+	c.retryWait = 10 * time.Millisecond
+	return c
+}
+
+func TestListProjectsFollowsPagingAndSkipsDeleted(t *testing.T) {
+	const total = 120
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/api-v2/projects" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		content := []map[string]interface{}{}
+		for i := offset; i < total && i < offset+limit; i++ {
+			content = append(content, map[string]interface{}{
+				"id":      "p" + strconv.Itoa(i),
+				"title":   "Проект " + strconv.Itoa(i),
+				"deleted": i%10 == 0,
+			})
+		}
+		resp := map[string]interface{}{
+			"paging":  map[string]interface{}{"count": total, "limit": limit, "offset": offset, "next": offset+limit < total},
+			"content": content,
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+		}
+	}))
+	defer ts.Close()
+
+	c := newStructureTestClient(ts)
+	projects, err := c.ListProjects(context.Background())
+	if err != nil {
+		t.Fatalf("ListProjects failed: %v", err)
+	}
+	if len(projects) != total-total/10 {
+		t.Fatalf("expected %d projects, got %d", total-total/10, len(projects))
+	}
+	if requests != 3 {
+		t.Fatalf("expected 3 page requests, got %d", requests)
+	}
+	for _, p := range projects {
+		if p.Deleted {
+			t.Fatalf("deleted project %s returned", p.ID)
+		}
+	}
+}
+
+func TestListBoardsAndColumnsPassParent(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var content []map[string]interface{}
+		switch r.URL.Path {
+		case "/api-v2/boards":
+			if got := r.URL.Query().Get("projectId"); got != "p1" {
+				t.Errorf("expected projectId=p1, got %q", got)
+			}
+			content = []map[string]interface{}{{"id": "b1", "title": "Заявки", "projectId": "p1"}}
+		case "/api-v2/columns":
+			if got := r.URL.Query().Get("boardId"); got != "b1" {
+				t.Errorf("expected boardId=b1, got %q", got)
+			}
+			content = []map[string]interface{}{
+				{"id": "c1", "title": "Новые", "boardId": "b1", "color": 3},
+				{"id": "c2", "title": "Старые", "boardId": "b1", "deleted": true},
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		resp := map[string]interface{}{
+			"paging":  map[string]interface{}{"count": len(content), "limit": 50, "offset": 0, "next": false},
+			"content": content,
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+		}
+	}))
+	defer ts.Close()
+
+	c := newStructureTestClient(ts)
+	boards, err := c.ListBoards(context.Background(), "p1")
+	if err != nil {
+		t.Fatalf("ListBoards failed: %v", err)
+	}
+	if len(boards) != 1 || boards[0].ID != "b1" || boards[0].ProjectID != "p1" {
+		t.Fatalf("unexpected boards: %+v", boards)
+	}
+	columns, err := c.ListColumns(context.Background(), "b1")
+	if err != nil {
+		t.Fatalf("ListColumns failed: %v", err)
+	}
+	if len(columns) != 1 || columns[0].ID != "c1" || columns[0].Color != 3 {
+		t.Fatalf("unexpected columns: %+v", columns)
+	}
+}
+
+func TestTargetChangesWhileRequestsRun(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/api-v2/tasks" {
+			var payload map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+			if payload["boardId"] == "" || payload["boardId"] == nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(map[string]string{"id": "t1"})
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+	c := newStructureTestClient(ts)
+
+	// Администратор меняет доску и колонку (/projects), пока идут запросы;
+	// под go test -race здесь не должно быть гонок
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			c.SetBoardID("b" + strconv.Itoa(i))
+			c.SetColumnID("c" + strconv.Itoa(i))
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		if err := c.CreateTask(context.Background(), &models.Task{Title: "t"}); err != nil {
+			t.Fatalf("CreateTask failed: %v", err)
+		}
+		_, _ = c.GetTaskByID(context.Background(), "t1")
+	}
+	<-done
+}
@@ -0,0 +1,69 @@
+// Package api содержит тесты и вспомогательные функции для клиента Yougile API.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTaskOperationsSendPartialUpdates(t *testing.T) {
+	var bodies []map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/api-v2/tasks/t1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode patch: %v", err)
+		}
+		bodies = append(bodies, body)
+		if _, err := io.WriteString(w, `{"id":"t1"}`); err != nil {
+			t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+		}
+	}))
+	defer ts.Close()
+
+	c := newStructureTestClient(ts)
+	ctx := context.Background()
+	due := time.Date(2026, 10, 20, 18, 0, 0, 0, time.UTC)
+	steps := []func() error{
+		func() error { return c.MoveTask(ctx, "t1", "col-2") },
+		func() error { return c.SetTaskCompleted(ctx, "t1", true) },
+		func() error { return c.ArchiveTask(ctx, "t1", true) },
+		func() error { return c.ReopenTask(ctx, "t1") },
+		func() error { return c.SetTaskDeadline(ctx, "t1", due, false) },
+		func() error { return c.SetTaskDeadline(ctx, "t1", time.Time{}, false) },
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("step %d failed: %v", i, err)
+		}
+	}
+
+	if len(bodies[0]) != 1 || bodies[0]["columnId"] != "col-2" {
+		t.Fatalf("move: unexpected payload %v", bodies[0])
+	}
+	if len(bodies[1]) != 1 || bodies[1]["completed"] != true {
+		t.Fatalf("complete: unexpected payload %v", bodies[1])
+	}
+	if len(bodies[2]) != 1 || bodies[2]["archived"] != true {
+		t.Fatalf("archive: unexpected payload %v", bodies[2])
+	}
+	if bodies[3]["completed"] != false || bodies[3]["archived"] != false {
+		t.Fatalf("reopen: unexpected payload %v", bodies[3])
+	}
+	deadline, _ := bodies[4]["deadline"].(map[string]interface{})
+	if deadline["deadline"] != float64(due.UnixMilli()) || deadline["deleted"] != nil {
+		t.Fatalf("deadline: unexpected payload %v", bodies[4])
+	}
+	cleared, _ := bodies[5]["deadline"].(map[string]interface{})
+	if cleared["deleted"] != true || cleared["deadline"] != nil {
+		t.Fatalf("clear deadline: unexpected payload %v", bodies[5])
+	}
+}
@@ -0,0 +1,106 @@
+// Package api содержит тесты и вспомогательные функции для клиента Yougile API.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"yougile_bot4/internal/models"
+)
+
+// newTaskUpdateServer возвращает сервер задачи t1 с заголовком remoteTitle
+// и сохраняет тела запросов PUT в puts.
+func newTaskUpdateServer(t *testing.T, remoteTitle string, puts *[]map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api-v2/tasks/t1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			task := map[string]interface{}{
+				"id": "t1", "title": remoteTitle, "columnId": "c1", "completed": false,
+				"assigned": []string{"u2", "u1"},
+				"deadline": map[string]interface{}{"deadline": 1700000000000},
+			}
+			if err := json.NewEncoder(w).Encode(task); err != nil {
+				t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+			}
+		case http.MethodPut:
+			var body map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decode update: %v", err)
+			}
+			*puts = append(*puts, body)
+			if _, err := io.WriteString(w, `{"id":"t1"}`); err != nil {
+				t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+			}
+		}
+	}))
+}
+
+func TestUpdateTaskFieldsSendsOnlyChangedFields(t *testing.T) {
+	var puts []map[string]interface{}
+	ts := newTaskUpdateServer(t, "Старое", &puts)
+	defer ts.Close()
+	c := newStructureTestClient(ts)
+
+	base, err := c.FetchTask(context.Background(), "t1")
+	if err != nil {
+		t.Fatalf("FetchTask failed: %v", err)
+	}
+	if base.Title != "Старое" || base.DueDate.UnixMilli() != 1700000000000 {
+		t.Fatalf("unexpected task %+v", base)
+	}
+
+	// исполнители в другом порядке — не конфликт
+	title := "Новое"
+	assigned := []string{"u3"}
+	err = c.UpdateTaskFields(context.Background(), "t1", TaskUpdate{Title: &title, Assigned: &assigned, Base: &models.Task{
+		Title: "Старое", Assigned: []string{"u1", "u2"},
+	}})
+	if err != nil {
+		t.Fatalf("UpdateTaskFields failed: %v", err)
+	}
+	if len(puts) != 1 || len(puts[0]) != 2 || puts[0]["title"] != "Новое" {
+		t.Fatalf("expected only title and assigned, got %v", puts)
+	}
+
+	// запись по модели отправляет только явно заданные поля
+	puts = nil
+	task := &models.Task{ExternalID: "t1", Title: "T", Description: "D", Done: true, Comments: []models.Comment{{Text: "x"}}, TimeSpent: 2}
+	if err := c.UpdateTask(context.Background(), task, TaskUpdate{Title: &task.Title}); err != nil {
+		t.Fatalf("UpdateTask failed: %v", err)
+	}
+	if len(puts) != 1 || len(puts[0]) != 1 || puts[0]["title"] != "T" {
+		t.Fatalf("expected only title to be sent, got %v", puts)
+	}
+}
+
+func TestUpdateTaskFieldsDetectsConflict(t *testing.T) {
+	var puts []map[string]interface{}
+	ts := newTaskUpdateServer(t, "Изменено в Yougile", &puts)
+	defer ts.Close()
+	c := newStructureTestClient(ts)
+
+	title := "Моё"
+	err := c.UpdateTaskFields(context.Background(), "t1", TaskUpdate{Title: &title, Base: &models.Task{Title: "Старое"}})
+	var conflict *ConflictError
+	if !errors.Is(err, ErrConflict) || !errors.As(err, &conflict) || len(conflict.Fields) != 1 || conflict.Fields[0] != "title" {
+		t.Fatalf("expected title conflict, got %v", err)
+	}
+	if len(puts) != 0 {
+		t.Fatalf("task must not be written on conflict, got %v", puts)
+	}
+
+	// то же значение уже записано другим — не конфликт
+	title = "Изменено в Yougile"
+	if err := c.UpdateTaskFields(context.Background(), "t1", TaskUpdate{Title: &title, Base: &models.Task{Title: "Старое"}}); err != nil {
+		t.Fatalf("expected no conflict, got %v", err)
+	}
+}
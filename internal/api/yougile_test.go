@@ -2,6 +2,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -30,7 +31,7 @@ func TestCreateTaskParsesID(t *testing.T) {
 	c.httpClient = ts.Client()
 
 	task := &models.Task{Title: "t"}
-	if err := c.CreateTask(task); err != nil {
+	if err := c.CreateTask(context.Background(), task); err != nil {
 		t.Fatalf("CreateTask failed: %v", err)
 	}
 
@@ -2,6 +2,7 @@
 package api
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -38,7 +39,7 @@ func TestUpdateTaskRetries(t *testing.T) {
 	c.retryWait = 10 * time.Millisecond
 
 	task := &models.Task{ID: 42, Title: "update me"}
-	if err := c.UpdateTask(task); err != nil {
+	if err := c.UpdateTask(context.Background(), task, TaskUpdate{Title: &task.Title}); err != nil {
 		t.Fatalf("expected success, got error: %v", err)
 	}
 	if calls < 2 {
@@ -72,7 +73,7 @@ func TestAddCommentRetries(t *testing.T) {
 	c.retryWait = 10 * time.Millisecond
 
 	comment := &models.Comment{Text: "hello"}
-	if err := c.AddComment("123", comment); err != nil {
+	if err := c.AddComment(context.Background(), "123", comment); err != nil {
 		t.Fatalf("expected success, got error: %v", err)
 	}
 	if calls < 2 {
@@ -2,9 +2,12 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,18 +15,41 @@ import (
 	"yougile_bot4/internal/models"
 )
 
-// Тест проверяет, что UploadAttachment делает retry при 500 и успешно завершается при 201
+// Тест проверяет, что UploadAttachment делает retry при 500, загружает файл через
+// /api-v2/upload-file и публикует ссылку на него в чате задачи
 func TestUploadAttachmentRetries(t *testing.T) {
 	calls := 0
-	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
-		calls++
-		if calls == 1 {
-			http.Error(w, "server error", http.StatusInternalServerError)
-			return
-		}
-		w.WriteHeader(http.StatusCreated)
-		if _, err := io.WriteString(w, `{"data": {"id": 123}}`); err != nil {
-			t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+	var message map[string]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api-v2/upload-file":
+			calls++
+			if calls == 1 {
+				http.Error(w, "server error", http.StatusInternalServerError)
+				return
+			}
+			file, header, err := r.FormFile("file")
+			if err != nil {
+				t.Fatalf("expected multipart file: %v", err)
+			}
+			data, _ := io.ReadAll(file)
+			if string(data) != "hello" || header.Filename != "file1" {
+				t.Fatalf("unexpected upload %q (%s)", data, header.Filename)
+			}
+			w.WriteHeader(http.StatusCreated)
+			if _, err := io.WriteString(w, `{"result":"ok","url":"/user-data/u1/file1"}`); err != nil {
+				t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+			}
+		case "/api-v2/chats/1/messages":
+			if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+				t.Fatalf("decode message: %v", err)
+			}
+			w.WriteHeader(http.StatusCreated)
+			if _, err := io.WriteString(w, `{"id": 123}`); err != nil {
+				t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
 		}
 	}))
 	defer ts.Close()
@@ -37,11 +63,18 @@ func TestUploadAttachmentRetries(t *testing.T) {
 	attachment := &models.Attachment{ID: "file1", Type: models.AttachmentTypeFile}
 	data := []byte("hello")
 
-	if err := c.UploadAttachment("1", attachment, data); err != nil {
+	if err := c.UploadAttachment(context.Background(), "1", attachment, data); err != nil {
 		t.Fatalf("expected success, got error: %v", err)
 	}
 
 	if calls < 2 {
 		t.Fatalf("expected at least 2 calls, got %d", calls)
 	}
+	wantURL := ts.URL + "/user-data/u1/file1"
+	if attachment.URL != wantURL {
+		t.Fatalf("expected attachment URL %s, got %s", wantURL, attachment.URL)
+	}
+	if !strings.Contains(message["text"], wantURL) || !strings.Contains(message["textHtml"], `href="`+wantURL+`"`) {
+		t.Fatalf("expected chat message with file link, got %v", message)
+	}
 }
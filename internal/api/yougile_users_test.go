@@ -0,0 +1,110 @@
+// Package api содержит тесты и вспомогательные функции для клиента Yougile API.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"yougile_bot4/internal/metrics"
+	"yougile_bot4/internal/models"
+)
+
+// newUsersServer возвращает сервер со списком сотрудников; requests считает обращения к списку.
+func newUsersServer(t *testing.T, requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api-v2/users":
+			*requests++
+			resp := map[string]interface{}{
+				"paging": map[string]interface{}{"count": 2, "limit": 50, "offset": 0, "next": false},
+				"content": []map[string]interface{}{
+					{"id": "u1", "email": "ivanov@example.com", "realName": "Иванов Иван", "status": "online"},
+					{"id": "u2", "email": "petrov@example.com", "realName": "", "status": "offline"},
+				},
+			}
+			if err := json.NewEncoder(w).Encode(resp); err != nil {
+				t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+			}
+		case "/api-v2/users/u1":
+			if _, err := io.WriteString(w, `{"id":"u1","email":"ivanov@example.com","realName":"Иванов Иван"}`); err != nil {
+				t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestUserNamesUsesCachedDirectory(t *testing.T) {
+	requests := 0
+	ts := newUsersServer(t, &requests)
+	defer ts.Close()
+
+	c := newStructureTestClient(ts)
+	names := c.UserNames(context.Background(), []string{"u1", "u2", "u3"})
+	want := []string{"Иванов Иван", "petrov@example.com", "u3"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected %v, got %v", want, names)
+	}
+	// неизвестный u3 не должен вызывать повторную загрузку списка чаще userRefreshMin
+	c.UserNames(context.Background(), []string{"u3"})
+	if requests != 1 {
+		t.Fatalf("expected 1 users list request, got %d", requests)
+	}
+}
+
+func TestFindAndGetUser(t *testing.T) {
+	requests := 0
+	ts := newUsersServer(t, &requests)
+	defer ts.Close()
+
+	c := newStructureTestClient(ts)
+	u, err := c.FindUser(context.Background(), "IVANOV@example.com")
+	if err != nil || u.ID != "u1" {
+		t.Fatalf("FindUser by email: %+v, %v", u, err)
+	}
+	if _, err := c.FindUser(context.Background(), "nobody@example.com"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	got, err := c.GetUser(context.Background(), "u1")
+	if err != nil || got.RealName != "Иванов Иван" {
+		t.Fatalf("GetUser: %+v, %v", got, err)
+	}
+	if _, err := c.GetUser(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestCreateTaskSendsAssigned(t *testing.T) {
+	var payload map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		if _, err := io.WriteString(w, `{"id":"a1b2"}`); err != nil {
+			t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+		}
+	}))
+	defer ts.Close()
+
+	c := NewClient("token", "board", 2*time.Second, &metrics.Metrics{})
+	c.baseURL = ts.URL
+	c.httpClient = ts.Client()
+
+	task := &models.Task{Title: "t", Assigned: []string{"u1"}}
+	if err := c.CreateTask(context.Background(), task); err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	assigned, ok := payload["assigned"].([]interface{})
+	if !ok || len(assigned) != 1 || assigned[0] != "u1" {
+		t.Fatalf("expected assigned [u1], got %v", payload["assigned"])
+	}
+}
@@ -0,0 +1,64 @@
+// Package api содержит тесты и вспомогательные функции для клиента Yougile API.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEnsureWebhookReusesAndCreates(t *testing.T) {
+	var updated, created map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api-v2/webhooks":
+			list := []map[string]interface{}{
+				{"id": "w1", "url": "https://bot.example.com/hook?token=old", "event": "task-.*", "disabled": true, "failuresSinceLastSuccess": 3},
+				{"id": "w0", "url": "https://bot.example.com/hook", "event": "chat_message-created", "deleted": true, "failuresSinceLastSuccess": 0},
+			}
+			if err := json.NewEncoder(w).Encode(list); err != nil {
+				t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+			}
+		case r.Method == http.MethodPut && r.URL.Path == "/api-v2/webhooks/w1":
+			if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+				t.Errorf("decode update: %v", err)
+			}
+			if _, err := io.WriteString(w, `{"id":"w1"}`); err != nil {
+				t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+			}
+		case r.Method == http.MethodPost && r.URL.Path == "/api-v2/webhooks":
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				t.Errorf("decode create: %v", err)
+			}
+			w.WriteHeader(http.StatusCreated)
+			if _, err := io.WriteString(w, `{"id":"w2"}`); err != nil {
+				t.Fatalf("Ошибка записи тела ответа в тесте: %v", err)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c := newStructureTestClient(ts)
+	hook := "https://bot.example.com/hook?token=new"
+	id, err := c.EnsureWebhook(context.Background(), hook, "task-.*")
+	if err != nil {
+		t.Fatalf("EnsureWebhook failed: %v", err)
+	}
+	if id != "w1" || updated["url"] != hook || updated["disabled"] != false {
+		t.Fatalf("expected w1 re-enabled with new secret, got id %q update %v", id, updated)
+	}
+
+	// удалённая подписка не переиспользуется
+	id, err = c.EnsureWebhook(context.Background(), hook, "chat_message-created")
+	if err != nil {
+		t.Fatalf("EnsureWebhook failed: %v", err)
+	}
+	if id != "w2" || created["url"] != hook || created["event"] != "chat_message-created" {
+		t.Fatalf("expected new subscription w2, got id %q create %v", id, created)
+	}
+}
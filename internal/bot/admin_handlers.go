@@ -133,9 +133,7 @@ func (b *Bot) handlePromoteAdmin(c telebot.Context) error {
 		return c.Send("Произошла ошибка при сохранении изменений.")
 	}
 
-	if _, err := b.bot.Send(&telebot.User{ID: targetID}, "Вам были предоставлены права администратора."); err != nil {
-		log.Printf("Ошибка отправки уведомления пользователю %d: %v", targetID, err)
-	}
+	b.enqueue(targetID, "Вам были предоставлены права администратора.")
 
 	return c.Send(fmt.Sprintf("Пользователь %s %s назначен администратором.",
 		targetUser.FirstName, targetUser.LastName))
@@ -187,9 +185,7 @@ func (b *Bot) handleDemoteAdmin(c telebot.Context) error {
 		return c.Send("Произошла ошибка при сохранении изменений.")
 	}
 
-	if _, err := b.bot.Send(&telebot.User{ID: targetID}, "С вас были сняты права администратора."); err != nil {
-		log.Printf("Ошибка отправки уведомления пользователю %d: %v", targetID, err)
-	}
+	b.enqueue(targetID, "С вас были сняты права администратора.")
 
 	return c.Send(fmt.Sprintf("С пользователя %s %s сняты права администратора.",
 		targetUser.FirstName, targetUser.LastName))
@@ -223,9 +219,7 @@ func (b *Bot) handleMakeAdminCallback(c telebot.Context) error {
 		return c.Send("Произошла ошибка при сохранении изменений.")
 	}
 
-	if _, err := b.bot.Send(&telebot.User{ID: targetID}, "Вам были предоставлены права администратора."); err != nil {
-		log.Printf("Ошибка отправки уведомления пользователю %d: %v", targetID, err)
-	}
+	b.enqueue(targetID, "Вам были предоставлены права администратора.")
 
 	return c.Send(fmt.Sprintf("Пользователь %s %s назначен администратором.", targetUser.FirstName, targetUser.LastName))
 }
@@ -258,9 +252,48 @@ func (b *Bot) handleMakeUserCallback(c telebot.Context) error {
 		return c.Send("Произошла ошибка при сохранении изменений.")
 	}
 
-	if _, err := b.bot.Send(&telebot.User{ID: targetID}, "С вас были сняты права администратора."); err != nil {
-		log.Printf("Ошибка отправки уведомления пользователю %d: %v", targetID, err)
-	}
+	b.enqueue(targetID, "С вас были сняты права администратора.")
 
 	return c.Send(fmt.Sprintf("С пользователя %s %s сняты права администратора.", targetUser.FirstName, targetUser.LastName))
 }
+
+// handleAPICapabilities показывает стратегии запросов к Yougile API, обнаруженные клиентом.
+// С аргументом "reset" сбрасывает их, чтобы при следующей проверке задач опрос выполнился заново.
+func (b *Bot) handleAPICapabilities(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+
+	arg := strings.TrimSpace(strings.TrimPrefix(c.Text(), "/apicaps"))
+	if arg == "reset" {
+		b.yougileClient.ResetCapabilities()
+		if err := b.storage.SaveData(); err != nil {
+			log.Printf("Ошибка сохранения данных после сброса возможностей API: %v", err)
+		}
+		return c.Send("Возможности API сброшены. При следующем запросе задач эндпоинты будут опрошены заново.")
+	}
+
+	caps := b.yougileClient.Capabilities()
+	if len(caps) == 0 {
+		return c.Send("Возможности API ещё не обнаружены. Они определятся при первом запросе списка задач.")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Обнаруженные способы получения списка задач:\n")
+	for _, cp := range caps {
+		sb.WriteString(fmt.Sprintf("\n📋 Доска: %s\n📂 Колонка: %s\n⚙️ Стратегия: %s\n🕒 Обнаружено: %s (проб: %d)\n",
+			valueOrDash(cp.BoardID), valueOrDash(cp.ColumnID), cp.Strategy,
+			cp.DiscoveredAt.Format("02.01.2006 15:04"), cp.Probes))
+	}
+	sb.WriteString("\nДля повторного опроса: /apicaps reset")
+	return c.Send(sb.String())
+}
+
+// valueOrDash возвращает значение или прочерк для пустой строки.
+func valueOrDash(v string) string {
+	if v == "" {
+		return "—"
+	}
+	return v
+}
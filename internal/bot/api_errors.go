@@ -0,0 +1,52 @@
+// Package bot содержит обработчики и логику Telegram-бота.
+package bot
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"yougile_bot4/internal/api"
+)
+
+// authAlertInterval — минимальный интервал между повторными уведомлениями
+// администраторов об отказе Yougile в доступе.
+const authAlertInterval = 30 * time.Minute
+
+// yougileErrorText возвращает понятное пользователю описание ошибки Yougile API.
+// fallback используется, если причину определить не удалось.
+func yougileErrorText(err error, fallback string) string {
+	switch {
+	case errors.Is(err, api.ErrUnauthorized):
+		return "Бот не может подключиться к Yougile: ключ доступа недействителен или отозван. Администраторы уже уведомлены."
+	case errors.Is(err, api.ErrRateLimit):
+		return "Yougile временно ограничил число запросов. Пожалуйста, повторите через минуту."
+	case errors.Is(err, api.ErrConflict):
+		return "Задачу уже изменили в Yougile. Обновите данные и повторите действие."
+	case errors.Is(err, api.ErrUncertain):
+		return "Yougile не ответил вовремя, но операция могла быть выполнена. Проверьте доску, прежде чем повторять запрос."
+	case errors.Is(err, api.ErrUnavailable):
+		return "Yougile сейчас недоступен. Пожалуйста, попробуйте позже."
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "Бот перезапускается. Пожалуйста, повторите запрос через пару минут."
+	}
+	return fallback
+}
+
+// ReportAPIError уведомляет администраторов об отказе в доступе к Yougile
+// (не чаще раза в authAlertInterval). Остальные ошибки игнорируются:
+// пользователю они показываются через yougileErrorText.
+func (b *Bot) ReportAPIError(err error) {
+	if !errors.Is(err, api.ErrUnauthorized) {
+		return
+	}
+	b.authAlertMu.Lock()
+	if time.Since(b.authAlertAt) < authAlertInterval {
+		b.authAlertMu.Unlock()
+		return
+	}
+	b.authAlertAt = time.Now()
+	b.authAlertMu.Unlock()
+	b.NotifyAdmins("⚠️ Yougile отклонил ключ доступа (401). Проверьте YOUGILE_TOKEN: ключ мог быть отозван.\n" +
+		"Новый ключ можно выпустить командой go run ./tools/yougile_key по логину и паролю администратора Yougile.\n" + err.Error())
+}
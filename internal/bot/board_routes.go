@@ -0,0 +1,329 @@
+// Package bot содержит работу с несколькими отслеживаемыми досками: выбор доски
+// для новой задачи, рассылку уведомлений по чатам доски и команды настройки.
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"yougile_bot4/internal/models"
+
+	"gopkg.in/telebot.v3"
+)
+
+const (
+	// boardPickUnique — префикс данных кнопок выбора доски в конструкторе задач.
+	boardPickUnique = "ygb"
+	// boardAnswerKey — ключ ответа конструктора с выбранным пользователем направлением.
+	boardAnswerKey = "board"
+	// mainRouteID обозначает основную доску на кнопках выбора направления.
+	mainRouteID = "-"
+)
+
+// WatchedBoards возвращает все отслеживаемые доски: основную (с пустым ID,
+// если она выбрана) и дополнительные направления. Повторы одной колонки отбрасываются.
+func (b *Bot) WatchedBoards() []models.BoardRoute {
+	var boards []models.BoardRoute
+	seen := make(map[string]bool)
+	if boardID, columnID := b.target(); boardID != "" {
+		main := models.BoardRoute{BoardID: boardID, ColumnID: columnID}
+		if tc, ok := b.storage.GetTargetColumn(); ok {
+			main.Title = tc.BoardTitle
+		}
+		boards = append(boards, main)
+		seen[boardID+"/"+columnID] = true
+	}
+	for _, r := range b.storage.GetBoardRoutes() {
+		if r.BoardID == "" || seen[r.BoardID+"/"+r.ColumnID] {
+			continue
+		}
+		seen[r.BoardID+"/"+r.ColumnID] = true
+		boards = append(boards, r)
+	}
+	return boards
+}
+
+// routeForTask возвращает дополнительное направление колонки columnID доски boardID.
+// Сначала ищется направление с той же доской и колонкой, затем направление всей
+// доски (без колонки). Если подходящего направления нет, задача уходит в чаты
+// по умолчанию, а задачи основной доски — к основной доске.
+func (b *Bot) routeForTask(boardID, columnID string) (models.BoardRoute, bool) {
+	mainBoard, mainColumn := b.target()
+	if boardID == "" || (boardID == mainBoard && columnID == mainColumn) {
+		return models.BoardRoute{}, false
+	}
+	routes := b.storage.GetBoardRoutes()
+	if columnID != "" {
+		for _, r := range routes {
+			if r.BoardID == boardID && r.ColumnID == columnID {
+				return r, true
+			}
+		}
+	}
+	if boardID == mainBoard {
+		return models.BoardRoute{}, false
+	}
+	// задачи других колонок не должны попадать в чаты направления конкретной колонки
+	for _, r := range routes {
+		if r.BoardID == boardID && r.ColumnID == "" {
+			return r, true
+		}
+	}
+	return models.BoardRoute{}, false
+}
+
+// taskDestination выбирает доску и колонку для новой задачи: направление,
+// выбранное пользователем, направление по ответам конструктора или основную доску.
+func (b *Bot) taskDestination(state *models.TaskCreationState) (boardID, columnID string) {
+	if state != nil && state.Answers != nil {
+		routes := b.storage.GetBoardRoutes()
+		if id := state.Answers[boardAnswerKey]; id != "" && id != mainRouteID {
+			for _, r := range routes {
+				if r.ID == id {
+					return r.BoardID, r.ColumnID
+				}
+			}
+		}
+		for _, r := range routes {
+			for _, answer := range r.Answers {
+				step, option, ok := strings.Cut(answer, ":")
+				if ok && state.Answers[step] == option {
+					return r.BoardID, r.ColumnID
+				}
+			}
+		}
+	}
+	return b.target()
+}
+
+// needsBoardPick сообщает, что пользователь выбирает доску сам: направления
+// настроены, но ни одно не выбирается по ответам конструктора.
+func (b *Bot) needsBoardPick() bool {
+	routes := b.storage.GetBoardRoutes()
+	if len(routes) == 0 {
+		return false
+	}
+	for _, r := range routes {
+		if len(r.Answers) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// boardPickMarkup возвращает кнопки выбора направления для новой задачи.
+func (b *Bot) boardPickMarkup() *telebot.ReplyMarkup {
+	menu := &telebot.ReplyMarkup{}
+	var rows []telebot.Row
+	if boardID, _ := b.target(); boardID != "" {
+		title := "Основная доска"
+		if tc, ok := b.storage.GetTargetColumn(); ok && tc.BoardTitle != "" {
+			title = tc.BoardTitle
+		}
+		rows = append(rows, menu.Row(menu.Data(title, boardPickUnique, mainRouteID)))
+	}
+	for _, r := range b.storage.GetBoardRoutes() {
+		rows = append(rows, menu.Row(menu.Data(r.Title, boardPickUnique, r.ID)))
+	}
+	menu.Inline(rows...)
+	return menu
+}
+
+// handleBoardPickCallback запоминает выбранное направление и продолжает конструктор.
+func (b *Bot) handleBoardPickCallback(c telebot.Context) error {
+	state, exists := b.taskCreationStates[c.Sender().ID]
+	if !exists || !state.IsTemplated {
+		return c.Send("Сессия создания задачи истекла. Пожалуйста, начните заново.")
+	}
+	_ = c.Respond()
+	state.Answers[boardAnswerKey] = callbackArg(c)
+	return b.sendInitialStep(c)
+}
+
+// SendTaskNotification отправляет уведомление о задаче в чаты её доски (для
+// дополнительных направлений — с названием направления) с кнопками действий
+// для администраторов.
+func (b *Bot) SendTaskNotification(task models.Task, msg string) {
+	id := outboxTaskID(&task)
+	if id == "" || id == "0" {
+		b.sendToTaskChats(task, msg)
+		return
+	}
+	b.sendToTaskChats(task, msg, taskActionsMarkup(id, task.Done))
+}
+
+// sendToTaskChats отправляет сообщение о задаче в чаты её доски, подписанные
+// на такие задачи правилами (см. /rules).
+func (b *Bot) sendToTaskChats(task models.Task, msg string, opts ...interface{}) {
+	chats := b.storage.GetChatIDs()
+	if r, ok := b.routeForTask(task.BoardID, task.ColumnID); ok {
+		msg = fmt.Sprintf("🗂 %s\n%s", r.Title, msg)
+		if len(r.ChatIDs) > 0 {
+			chats = r.ChatIDs
+		}
+	}
+	filtered := b.ruleChats(task, chats)
+	if len(chats) > 0 && len(filtered) == 0 {
+		log.Printf("Уведомление о задаче %s не отправлено: ни один чат не подписан правилами", outboxTaskID(&task))
+		return
+	}
+	b.sendToChats(filtered, msg, opts...)
+}
+
+// handleBoards показывает основную и дополнительные отслеживаемые доски.
+func (b *Bot) handleBoards(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	var sb strings.Builder
+	if tc, ok := b.storage.GetTargetColumn(); ok {
+		fmt.Fprintf(&sb, "Основная доска: «%s», колонка «%s»\n", tc.BoardTitle, tc.ColumnTitle)
+	} else if boardID, columnID := b.target(); boardID != "" {
+		fmt.Fprintf(&sb, "Основная доска: %s, колонка %s\n", boardID, columnID)
+	} else {
+		sb.WriteString("Основная доска не выбрана (/projects)\n")
+	}
+	routes := b.storage.GetBoardRoutes()
+	if len(routes) == 0 {
+		sb.WriteString("\nДополнительных досок нет.")
+	}
+	for _, r := range routes {
+		fmt.Fprintf(&sb, "\n• %s — «%s»\n  доска %s", r.ID, r.Title, r.BoardID)
+		if r.ColumnID != "" {
+			fmt.Fprintf(&sb, ", колонка %s", r.ColumnID)
+		}
+		if len(r.ChatIDs) > 0 {
+			fmt.Fprintf(&sb, "\n  чатов уведомлений: %d", len(r.ChatIDs))
+		} else {
+			sb.WriteString("\n  уведомления: общие чаты")
+		}
+		if len(r.Answers) > 0 {
+			fmt.Fprintf(&sb, "\n  ответы конструктора: %s", strings.Join(r.Answers, ", "))
+		}
+	}
+	sb.WriteString("\n\nДобавить: /addboard <имя> <название>\nУдалить: /delboard <имя>\n" +
+		"Чат уведомлений: /boardchat <имя>\nВыбор по ответу: /boardanswer <имя> <шаг:вариант>")
+	return c.Send(sb.String())
+}
+
+// handleAddBoard начинает выбор колонки для нового (или изменяемого) направления.
+func (b *Bot) handleAddBoard(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	args := strings.Fields(strings.TrimPrefix(c.Text(), "/addboard"))
+	if len(args) < 2 || args[0] == mainRouteID || strings.ContainsAny(args[0], "|:") {
+		return c.Send("Использование: /addboard <имя> <название>, например: /addboard aho Административно-хозяйственный отдел")
+	}
+	state := b.pickState(c.Sender().ID)
+	b.pickMu.Lock()
+	state.RouteID = args[0]
+	state.RouteTitle = strings.Join(args[1:], " ")
+	b.pickMu.Unlock()
+	return b.showProjects(c)
+}
+
+// saveBoardRoute сохраняет направление, выбранное через /addboard; чаты и ответы
+// конструктора существующего направления сохраняются.
+func (b *Bot) saveBoardRoute(c telebot.Context, id, title string, tc models.TargetColumn) error {
+	route, _ := b.storage.GetBoardRoute(id)
+	route.ID, route.Title = id, title
+	route.BoardID, route.ColumnID = tc.BoardID, tc.ColumnID
+	b.storage.SetBoardRoute(route)
+	if err := b.storage.SaveData(); err != nil {
+		log.Printf("Ошибка сохранения настроек: %v", err)
+	}
+	log.Printf("Администратор %d настроил направление %s: колонка %s доски %s", c.Sender().ID, id, tc.ColumnID, tc.BoardID)
+	return c.Edit(fmt.Sprintf("✅ Направление «%s» (%s): колонка «%s» доски «%s».\nЧат уведомлений: /boardchat %s",
+		title, id, tc.ColumnTitle, tc.BoardTitle, id))
+}
+
+// handleDeleteBoard удаляет направление.
+func (b *Bot) handleDeleteBoard(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	id := strings.TrimSpace(strings.TrimPrefix(c.Text(), "/delboard"))
+	if id == "" {
+		return c.Send("Использование: /delboard <имя>")
+	}
+	if !b.storage.RemoveBoardRoute(id) {
+		return c.Send(fmt.Sprintf("Направление %s не найдено. Список: /boards", id))
+	}
+	if err := b.storage.SaveData(); err != nil {
+		log.Printf("Ошибка сохранения настроек: %v", err)
+	}
+	return c.Send(fmt.Sprintf("Направление %s удалено.", id))
+}
+
+// handleBoardChat включает или выключает уведомления направления в текущем чате.
+func (b *Bot) handleBoardChat(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	id := strings.TrimSpace(strings.TrimPrefix(c.Text(), "/boardchat"))
+	route, ok := b.storage.GetBoardRoute(id)
+	if !ok {
+		return c.Send("Использование: /boardchat <имя> — в чате, куда присылать уведомления направления. Список: /boards")
+	}
+	chatID := c.Chat().ID
+	var text string
+	if i := indexInt64(route.ChatIDs, chatID); i >= 0 {
+		route.ChatIDs = append(route.ChatIDs[:i], route.ChatIDs[i+1:]...)
+		text = fmt.Sprintf("Уведомления направления «%s» в этот чат отключены.", route.Title)
+	} else {
+		route.ChatIDs = append(route.ChatIDs, chatID)
+		text = fmt.Sprintf("Уведомления направления «%s» будут приходить в этот чат (%d).", route.Title, chatID)
+	}
+	b.storage.SetBoardRoute(route)
+	if err := b.storage.SaveData(); err != nil {
+		log.Printf("Ошибка сохранения настроек: %v", err)
+	}
+	return c.Send(text)
+}
+
+// handleBoardAnswer включает или выключает выбор направления по ответу конструктора.
+func (b *Bot) handleBoardAnswer(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	args := strings.Fields(strings.TrimPrefix(c.Text(), "/boardanswer"))
+	var route models.BoardRoute
+	ok := len(args) == 2
+	if ok {
+		route, ok = b.storage.GetBoardRoute(args[0])
+	}
+	if !ok || !strings.Contains(args[1], ":") {
+		return c.Send("Использование: /boardanswer <имя> <шаг:вариант>, например: /boardanswer aho initial:furniture")
+	}
+	answer := args[1]
+	var text string
+	if contains(route.Answers, answer) {
+		route.Answers = remove(route.Answers, answer)
+		text = fmt.Sprintf("Ответ %s больше не выбирает направление «%s».", answer, route.Title)
+	} else {
+		route.Answers = append(route.Answers, answer)
+		text = fmt.Sprintf("Задачи с ответом %s будут создаваться в направлении «%s».", answer, route.Title)
+	}
+	b.storage.SetBoardRoute(route)
+	if err := b.storage.SaveData(); err != nil {
+		log.Printf("Ошибка сохранения настроек: %v", err)
+	}
+	return c.Send(text)
+}
+
+// indexInt64 возвращает индекс v в срезе или -1.
+func indexInt64(slice []int64, v int64) int {
+	for i, x := range slice {
+		if x == v {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,60 @@
+// Package bot содержит тесты выбора направления задачи по доске и колонке.
+package bot
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"yougile_bot4/internal/models"
+)
+
+func TestRouteForTaskMatchesColumn(t *testing.T) {
+	b := newTestBot(t, &fakeYougile{})
+	b.boardID, b.defaultColumn = "main", "main-new"
+	b.storage.SetBoardRoute(models.BoardRoute{ID: "aho", BoardID: "main", ColumnID: "main-aho", ChatIDs: []int64{1}})
+	b.storage.SetBoardRoute(models.BoardRoute{ID: "security", BoardID: "ops", ColumnID: "ops-security", ChatIDs: []int64{2}})
+	b.storage.SetBoardRoute(models.BoardRoute{ID: "repair", BoardID: "ops", ColumnID: "ops-repair", ChatIDs: []int64{3}})
+	b.storage.SetBoardRoute(models.BoardRoute{ID: "it", BoardID: "it", ChatIDs: []int64{4}})
+
+	for _, tc := range []struct {
+		board, column string
+		want          string // пусто — основная доска
+	}{
+		{"main", "main-new", ""},
+		{"main", "main-aho", "aho"},
+		{"main", "main-done", ""},
+		{"ops", "ops-repair", "repair"},
+		{"ops", "ops-security", "security"},
+		{"ops", "ops-done", ""},
+		{"it", "it-any", "it"},
+		{"unknown", "col", ""},
+	} {
+		r, ok := b.routeForTask(tc.board, tc.column)
+		if got := r.ID; ok != (tc.want != "") || got != tc.want {
+			t.Errorf("routeForTask(%s, %s) = %q, %v; want %q", tc.board, tc.column, got, ok, tc.want)
+		}
+	}
+}
+
+func TestTaskBoardIDPrefersTaskBoard(t *testing.T) {
+	b := newTestBot(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/api-v2/tasks/t-it" {
+			fmt.Fprint(w, `{"id":"t-it","title":"x","boardId":"it"}`)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	b.boardID, b.defaultColumn = "main", "main-new"
+	b.storage.SetTaskSnapshot(models.TaskSnapshot{Key: "t-ops", BoardID: "ops", ColumnID: "ops-repair"})
+
+	for id, want := range map[string]string{
+		"t-ops":     "ops",  // из снимка, без запроса к Yougile
+		"t-it":      "it",   // из задачи в Yougile
+		"t-missing": "main", // доска не определена — основная
+	} {
+		if got := b.taskBoardID(id); got != want {
+			t.Errorf("taskBoardID(%s) = %q, want %q", id, got, want)
+		}
+	}
+}
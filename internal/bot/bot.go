@@ -3,6 +3,7 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -14,7 +15,10 @@ import (
 	"yougile_bot4/internal/api"
 	"yougile_bot4/internal/metrics"
 	"yougile_bot4/internal/models"
+	"yougile_bot4/internal/notify"
+	"yougile_bot4/internal/sendq"
 	"yougile_bot4/internal/storage"
+	"yougile_bot4/internal/watcher"
 
 	"gopkg.in/telebot.v3"
 )
@@ -61,7 +65,6 @@ type Bot struct {
 	boardID            string
 	regTimeout         time.Duration
 	minMsgLen          int
-	notifications      chan string
 	metrics            *metrics.Metrics                    // метрики бота
 	regStates          map[int64]*RegistrationState        // ключ - TelegramID
 	addressChange      map[int64]string                    // этап изменения адреса: "building" или "room"
@@ -72,14 +75,38 @@ type Bot struct {
 	adminActions       map[int64]*AdminAction              // состояния действий администратора
 	adminUserStates    map[int64]*AdminUserState           // состояния управления пользователями
 	defaultColumn      string
-	// full scan control
-	fullScanCancel  context.CancelFunc
-	fullScanMu      sync.Mutex
-	fullScanRunning bool
+	// targetMu защищает boardID и defaultColumn, которые администратор может
+	// изменить командой /projects во время работы бота
+	targetMu       sync.RWMutex
+	pickMu         sync.Mutex
+	structurePicks map[int64]*StructurePickState // выбор колонки администраторами
+	taskMoves      map[int64]*TaskMovePick       // выбор колонки для перемещения задачи
+	searches       map[int64]*TaskSearch         // последний поиск задач администратора
+	// changeNotify включает сообщения об изменениях задач в чаты досок
+	changeNotify bool
+	// ctx — корневой контекст бота; отменяется при завершении работы и прерывает
+	// все запросы к Yougile, выполняемые обработчиками и фоновыми задачами.
+	ctx context.Context
+	// authAlertAt — время последнего уведомления администраторов об ошибке 401
+	authAlertMu sync.Mutex
+	authAlertAt time.Time
+	// outboxMu не допускает одновременной отправки отложенных операций
+	outboxMu sync.Mutex
+	// relayMu не допускает одновременной пересылки комментариев
+	relayMu sync.Mutex
+	// watcher — наблюдатель за задачами Yougile (подключается из main)
+	watcher *watcher.Watcher
+	// messages — шаблоны текстов уведомлений (см. /template)
+	messages *notify.Set
+	// queue — очередь исходящих сообщений: всё, что бот отправляет по своей
+	// инициативе, уходит через неё с учётом ограничений Telegram
+	queue *sendq.Queue
 }
 
 // NewBot создает и настраивает экземпляр Bot, регистрирует обработчики команд.
-func NewBot(token string, storage *storage.Storage, yougileToken string, boardID string, regTimeout time.Duration, minMsgLen int, metrics *metrics.Metrics) (*Bot, error) {
+// yougileClient разделяется с фоновыми службами main, чтобы обнаруженные
+// возможности API и кэш задач были общими.
+func NewBot(token string, storage *storage.Storage, yougileClient *api.Client, boardID string, regTimeout time.Duration, minMsgLen int, metrics *metrics.Metrics) (*Bot, error) {
 	b, err := telebot.NewBot(telebot.Settings{
 		Token:  token,
 		Poller: &telebot.LongPoller{Timeout: 10 * time.Second},
@@ -88,8 +115,6 @@ func NewBot(token string, storage *storage.Storage, yougileToken string, boardID
 		return nil, fmt.Errorf("ошибка создания бота: %w", err)
 	}
 
-	yougileClient := api.NewClient(yougileToken, boardID, 30*time.Second, metrics)
-
 	bot := &Bot{
 		bot:                b,
 		storage:            storage,
@@ -97,7 +122,6 @@ func NewBot(token string, storage *storage.Storage, yougileToken string, boardID
 		boardID:            boardID,
 		regTimeout:         regTimeout,
 		minMsgLen:          minMsgLen,
-		notifications:      make(chan string, 100),
 		metrics:            metrics,
 		regStates:          make(map[int64]*RegistrationState),
 		addressChange:      make(map[int64]string),
@@ -108,6 +132,17 @@ func NewBot(token string, storage *storage.Storage, yougileToken string, boardID
 		timeStates:         make(map[int64]int64),
 		adminUserStates:    make(map[int64]*AdminUserState),
 		defaultColumn:      os.Getenv("COLUMN_ID"),
+		structurePicks:     make(map[int64]*StructurePickState),
+		taskMoves:          make(map[int64]*TaskMovePick),
+		searches:           make(map[int64]*TaskSearch),
+		ctx:                context.Background(),
+		messages:           notify.Default(),
+	}
+
+	// Колонка, выбранная администратором в боте, важнее переменных окружения
+	if tc, ok := storage.GetTargetColumn(); ok {
+		bot.applyTarget(tc)
+		log.Printf("Новые задачи создаются в колонке %s (%s) доски %s (%s)", tc.ColumnID, tc.ColumnTitle, tc.BoardID, tc.BoardTitle)
 	}
 
 	// Настраиваем клавиатуру для основного меню
@@ -132,6 +167,8 @@ func NewBot(token string, storage *storage.Storage, yougileToken string, boardID
 	// Кнопка для просмотра пользователей (для админов)
 	// Регистрация обработчика производится в setupHandlers
 
+	bot.queue = sendq.New(storage, &telegramTransport{b: bot}, metrics)
+
 	bot.setupHandlers()
 	return bot, nil
 }
@@ -155,19 +192,23 @@ func (b *Bot) menuForContext(c telebot.Context) interface{} {
 }
 
 // formatTaskDescription формирует описание задачи и в конце добавляет в скобках
-// адрес, кабинет и должность; имя/фамилия не добавляются (они будут в заголовке).
-func (b *Bot) formatTaskDescription(user *models.User, original string) string {
+// адрес, кабинет и должность, кроме записанных в стикеры задачи (inStickers —
+// источники из taskStickers); имя/фамилия не добавляются (они будут в заголовке).
+func (b *Bot) formatTaskDescription(user *models.User, original string, inStickers map[string]bool) string {
 	// Собираем постфикс (адрес, кабинет, должность)
 	parts := []string{}
-	if user.BuildingAddress != "" {
+	switch {
+	case inStickers[models.StickerSourceBuilding]:
+		// адрес записан в стикер
+	case user.BuildingAddress != "":
 		parts = append(parts, user.BuildingAddress)
-	} else if user.Address != "" {
+	case user.Address != "":
 		parts = append(parts, user.Address)
 	}
-	if user.RoomNumber != "" {
+	if user.RoomNumber != "" && !inStickers[models.StickerSourceRoom] {
 		parts = append(parts, "каб. "+user.RoomNumber)
 	}
-	if user.Position != "" {
+	if user.Position != "" && !inStickers[models.StickerSourcePosition] {
 		parts = append(parts, user.Position)
 	}
 	postfix := ""
@@ -194,95 +235,13 @@ func (b *Bot) formatTaskTitle(user *models.User, title string) string {
 	return name + ". " + strings.TrimSpace(title)
 }
 
-// RescanTasks выполняет немедленную проверку задач через API Yougile и
-// отправляет уведомления для новых задач. Возвращает ошибку при неудаче.
-func (b *Bot) RescanTasks(limit int) error {
-	tasks, err := b.yougileClient.GetTasks(limit)
-	if err != nil {
-		return fmt.Errorf("RescanTasks: GetTasks failed: %w", err)
-	}
-
-	for _, task := range tasks {
-		key := ""
-		if task.ExternalID != "" {
-			key = task.ExternalID
-		} else if task.Key != "" {
-			key = task.Key
-		} else if task.ID != 0 {
-			key = fmt.Sprintf("%d", task.ID)
-		}
-		if key == "" {
-			continue
-		}
-		known := b.storage.IsKnownKey(key)
-		log.Printf("RescanTasks: task Key=%s ID=%d ExternalID=%s Title=%q Done=%v Known=%v", key, task.ID, task.ExternalID, task.Title, task.Done, known)
-		if !known {
-			b.storage.AddKnownKey(key)
-			if task.ID != 0 {
-				b.storage.AddKnownTask(task.ID)
-			}
-			if !task.Done {
-				b.SendNotification(b.formatTaskNotification(task))
-			}
-		}
-	}
-	return nil
-}
-
-// formatTaskNotification формирует текст уведомления о задаче (аналогично реализации в main).
-func (b *Bot) formatTaskNotification(task models.Task) string {
-	var status, priority string
-
-	if task.Done {
-		status = "✅"
-	} else {
-		status = "🔵"
-	}
-
-	switch task.Priority {
-	case 1:
-		priority = "⚡️ Высокий"
-	case 2:
-		priority = "⭐️ Средний"
-	default:
-		priority = "📌 Обычный"
-	}
-
-	var dueDate string
-	if !task.DueDate.IsZero() {
-		dueDate = fmt.Sprintf("\n📅 Срок: %s", task.DueDate.Format("02.01.2006"))
-	}
-
-	var assignee string
-	if task.Assignee != "" {
-		assignee = fmt.Sprintf("\n👤 Исполнитель: %s", task.Assignee)
-	}
-
-	msg := fmt.Sprintf("%s Новая задача\n"+
-		"📎 %s\n"+
-		"🏷 %s%s%s",
-		status, task.Title, priority, dueDate, assignee)
-
-	if task.Description != "" {
-		descLen := len(task.Description)
-		if descLen > 200 {
-			descLen = 200
-		}
-		msg += fmt.Sprintf("\n\n📝 %s", task.Description[:descLen])
-		if len(task.Description) > 200 {
-			msg += "..."
-		}
-	}
-
-	return msg
-}
-
 // setupHandlers настраивает обработчики команд
 func (b *Bot) setupHandlers() {
 	// Стандартные команды
 	b.bot.Handle("/start", b.handleStart)
 	b.bot.Handle("/help", b.handleHelp)
 	b.bot.Handle("/address", b.handleChangeAddress)
+	b.bot.Handle("/updates", b.handleTaskUpdates)
 	// Команда для создания новой задачи через конструктор
 	b.bot.Handle("/newtask", b.handleTaskConstructor)
 
@@ -340,35 +299,30 @@ func (b *Bot) setupHandlers() {
 	b.bot.Handle("/promote_admin", b.handlePromoteAdmin)
 	b.bot.Handle("/demote_admin", b.handleDemoteAdmin)
 	b.bot.Handle("/list_users", b.handleListUsers)
+	// Выбор колонки для новых задач
+	b.bot.Handle("/projects", b.handleProjects)
+	b.bot.Handle("/column", b.handleColumn)
+	// Дополнительные отслеживаемые доски (направления)
+	b.bot.Handle("/boards", b.handleBoards)
+	b.bot.Handle("/addboard", b.handleAddBoard)
+	b.bot.Handle("/delboard", b.handleDeleteBoard)
+	b.bot.Handle("/boardchat", b.handleBoardChat)
+	b.bot.Handle("/boardanswer", b.handleBoardAnswer)
+	// Правила подписки чатов на уведомления о задачах
+	b.bot.Handle("/rules", b.handleRules)
+	b.bot.Handle("/addrule", b.handleAddRule)
+	b.bot.Handle("/delrule", b.handleDelRule)
+	// Связь пользователей с сотрудниками Yougile
+	b.bot.Handle("/yusers", b.handleYougileUsers)
+	b.bot.Handle("/link", b.handleLinkUser)
+	b.bot.Handle("/unlink", b.handleUnlinkUser)
+	b.bot.Handle("/executor", b.handleExecutor)
+	// Заполнение стикеров задач
+	b.bot.Handle("/stickers", b.handleStickers)
+	b.bot.Handle("/sticker", b.handleSetSticker)
 	// Full scan commands (admins only)
-	b.bot.Handle("/fullscan", func(c telebot.Context) error {
-		sender, exists := b.storage.GetUser(c.Sender().ID)
-		if !exists || sender.Role != models.RoleAdmin {
-			return c.Send("Команда доступна только администраторам.")
-		}
-		// optional arg: range
-		arg := strings.TrimSpace(strings.TrimPrefix(c.Text(), "/fullscan"))
-		rng := 100
-		if arg != "" {
-			if v, err := strconv.Atoi(arg); err == nil && v > 0 {
-				rng = v
-			}
-		}
-		if err := b.startFullScan(rng); err != nil {
-			return c.Send(fmt.Sprintf("Не удалось запустить fullscan: %v", err))
-		}
-		return c.Send(fmt.Sprintf("Full scan запущен (%d запросов)", rng))
-	})
-	b.bot.Handle("/stopfullscan", func(c telebot.Context) error {
-		sender, exists := b.storage.GetUser(c.Sender().ID)
-		if !exists || sender.Role != models.RoleAdmin {
-			return c.Send("Команда доступна только администраторам.")
-		}
-		if err := b.stopFullScan(); err != nil {
-			return c.Send(fmt.Sprintf("Не удалось остановить fullscan: %v", err))
-		}
-		return c.Send("Full scan остановлен")
-	})
+	b.bot.Handle("/fullscan", b.handleFullScan)
+	b.bot.Handle("/stopfullscan", b.handleStopFullScan)
 
 	// Обработчики кнопок управления пользователями
 	b.bot.Handle(&btnPromoteAdmin, b.handlePromoteAdminButton)
@@ -412,6 +366,37 @@ func (b *Bot) setupHandlers() {
 				return b.handleTaskSelectCallback(c)
 			}
 
+			if strings.HasPrefix(data, taskOpsUnique+"|") {
+				c.Callback().Data = data
+				return b.handleTaskOpsCallback(c)
+			}
+
+			if strings.HasPrefix(data, searchUnique+"|") {
+				c.Callback().Data = data
+				return b.handleSearchCallback(c)
+			}
+
+			if strings.HasPrefix(data, boardPickUnique+"|") {
+				c.Callback().Data = data
+				return b.handleBoardPickCallback(c)
+			}
+
+			if strings.HasPrefix(data, "ygprojects") {
+				return b.showProjects(c)
+			}
+			if strings.HasPrefix(data, "ygproj|") {
+				c.Callback().Data = data
+				return b.handleProjectCallback(c)
+			}
+			if strings.HasPrefix(data, "ygboard|") {
+				c.Callback().Data = data
+				return b.handleBoardCallback(c)
+			}
+			if strings.HasPrefix(data, "ygcol|") {
+				c.Callback().Data = data
+				return b.handleColumnCallback(c)
+			}
+
 			if strings.HasPrefix(data, "select_user|") {
 				c.Callback().Data = data
 				return b.handleSelectUser(c)
@@ -471,18 +456,12 @@ func (b *Bot) setupHandlers() {
 	b.bot.Handle(&btnSkip, b.handleSkip)
 
 	// Команда для немедленной проверки новых задач (только для админов)
-	b.bot.Handle("/rescan", func(c telebot.Context) error {
-		sender, exists := b.storage.GetUser(c.Sender().ID)
-		if !exists || sender.Role != models.RoleAdmin {
-			return c.Send("Команда доступна только администраторам.")
-		}
-		// Выполним сканирование
-		if err := b.RescanTasks(100); err != nil {
-			log.Printf("rescan: error: %v", err)
-			return c.Send(fmt.Sprintf("Ошибка при сканировании: %v", err))
-		}
-		return c.Send("Рескан завершён. Проверьте логи для деталей.")
-	})
+	b.bot.Handle("/rescan", b.handleRescan)
+	// Состояние наблюдателя за задачами, его остановка и запуск (админам)
+	b.bot.Handle("/watcher", b.handleWatcher)
+
+	// Команда для просмотра обнаруженных возможностей API (админам)
+	b.bot.Handle("/apicaps", b.handleAPICapabilities)
 
 	// Команда для поиска конкретной задачи по ключу/ID (админам)
 	b.bot.Handle("/findtask", func(c telebot.Context) error {
@@ -497,10 +476,14 @@ func (b *Bot) setupHandlers() {
 		}
 		key := args
 		// Попытаемся получить задачу по ID/ключу
-		task, err := b.yougileClient.GetTaskByID(key)
+		task, err := b.yougileClient.GetTaskByID(b.ctx, key)
+		if errors.Is(err, api.ErrNotFound) {
+			return c.Send("Задача не найдена через API Yougile.")
+		}
 		if err != nil {
 			log.Printf("findtask: GetTaskByID(%s) error: %v", key, err)
-			return c.Send(fmt.Sprintf("Ошибка при запросе задачи: %v", err))
+			b.ReportAPIError(err)
+			return c.Send(yougileErrorText(err, fmt.Sprintf("Ошибка при запросе задачи: %v", err)))
 		}
 		if task == nil {
 			return c.Send("Задача не найдена через API Yougile.")
@@ -510,60 +493,13 @@ func (b *Bot) setupHandlers() {
 		return c.Send(resp)
 	})
 
-	// Admin helper: force notify about a task by key (marks as known and sends notification)
-	b.bot.Handle("/notify", func(c telebot.Context) error {
-		sender, exists := b.storage.GetUser(c.Sender().ID)
-		if !exists || sender.Role != models.RoleAdmin {
-			return c.Send("Команда доступна только администраторам.")
-		}
-		args := strings.TrimSpace(strings.TrimPrefix(c.Text(), "/notify"))
-		if args == "" {
-			return c.Send("Использование: /notify <ключ_или_id> — пометить задачу как новую и разослать уведомление")
-		}
-		key := args
-		task, err := b.yougileClient.GetTaskByID(key)
-		if err != nil {
-			log.Printf("notify: GetTaskByID(%s) error: %v", key, err)
-			return c.Send(fmt.Sprintf("Ошибка при запросе задачи: %v", err))
-		}
-		if task == nil {
-			return c.Send("Задача не найдена через API Yougile.")
-		}
-
-		// Determine tracking key
-		tkey := ""
-		if task.ExternalID != "" {
-			tkey = task.ExternalID
-		} else if task.Key != "" {
-			tkey = task.Key
-		} else if task.ID != 0 {
-			tkey = fmt.Sprintf("%d", task.ID)
-		}
-		if tkey == "" {
-			return c.Send("Не удалось определить ключ задачи для отслеживания.")
-		}
-
-		// Mark known and persist
-		if !b.storage.IsKnownKey(tkey) {
-			b.storage.AddKnownKey(tkey)
-		}
-		if task.ID != 0 && !b.storage.IsKnownTask(task.ID) {
-			b.storage.AddKnownTask(task.ID)
-		}
-		if err := b.storage.SaveData(); err != nil {
-			log.Printf("notify: error saving storage: %v", err)
-		}
+	// Поиск задач по тексту и фильтрам (админам)
+	b.bot.Handle("/search", b.handleSearch)
 
-		// Send notification if task not done
-		if !task.Done {
-			chats := b.storage.GetChatIDs()
-			log.Printf("notify: sending notification for %s to %d chats", tkey, len(chats))
-			b.SendNotification(b.formatTaskNotification(*task))
-			log.Printf("notify: SendNotification called for %s", tkey)
-			return c.Send(fmt.Sprintf("Уведомление отправлено для %s (чатов: %d)", tkey, len(chats)))
-		}
-		return c.Send("Задача помечена как известная, но не отправлено уведомление — задача помечена как завершённая/удалённая.")
-	})
+	// Admin helper: force notify about a task by key (marks as known and sends notification)
+	b.bot.Handle("/notify", b.handleNotify)
+	// Шаблоны текстов уведомлений
+	b.bot.Handle("/template", b.handleTemplate)
 
 	// Обработчик текстовых сообщений
 	b.bot.Handle(telebot.OnText, b.handleMessage)
@@ -619,7 +555,8 @@ func (b *Bot) handleHelp(c telebot.Context) error {
 	return c.Send(`Доступные команды:
 	/start - Начать работу с ботом
 	/help - Показать это сообщение
-	/address - Изменить ваш адрес`, b.menuForContext(c))
+	/address - Изменить ваш адрес
+	/updates - Включить или отключить сообщения об изменениях ваших заявок`, b.menuForContext(c))
 }
 
 // handleChangeAddress обрабатывает команду изменения адреса
@@ -643,6 +580,11 @@ func (b *Bot) handleChangeAddress(c telebot.Context) error {
 
 // handleMessage обрабатывает текстовые сообщения
 func (b *Bot) handleMessage(c telebot.Context) error {
+	// Ответ на пересланный комментарий уходит в чат задачи Yougile
+	if handled, err := b.handleRelayReply(c); handled {
+		return err
+	}
+
 	// Обработка состояний администратора при редактировании пользователя (имя/адрес)
 	if state, ok := b.adminUserStates[c.Sender().ID]; ok {
 		// Проверяем таймаут состояния
@@ -823,11 +765,8 @@ func (b *Bot) handleMessage(c telebot.Context) error {
 				if u.Role != models.RoleAdmin {
 					continue
 				}
-				msg := fmt.Sprintf("Новая заявка на регистрацию:\n👤 %s %s (%d)\n💼 Должность: %s",
-					regState.User.FirstName, regState.User.LastName, regState.User.TelegramID, regState.User.Position)
-				if _, err := b.bot.Send(&telebot.User{ID: u.TelegramID}, msg, menu); err != nil {
-					log.Printf("Ошибка отправки уведомления администратору %d: %v", u.TelegramID, err)
-				}
+				msg := b.messages.Render(notify.RegistrationRequest, notify.RegistrationData{User: *regState.User})
+				b.enqueue(u.TelegramID, msg, menu)
 			}
 
 			delete(b.regStates, c.Sender().ID)
@@ -888,9 +827,7 @@ func (b *Bot) handleMessage(c telebot.Context) error {
 				return c.Send("Произошла ошибка при сохранении изменений.")
 			}
 
-			if _, err := b.bot.Send(&telebot.User{ID: targetID}, "Вам были предоставлены права администратора."); err != nil {
-				log.Printf("Ошибка отправки уведомления пользователю %d: %v", targetID, err)
-			}
+			b.enqueue(targetID, "Вам были предоставлены права администратора.")
 			delete(b.adminActions, c.Sender().ID)
 			return c.Send(fmt.Sprintf("Пользователь %s %s назначен администратором.",
 				targetUser.FirstName, targetUser.LastName))
@@ -909,9 +846,7 @@ func (b *Bot) handleMessage(c telebot.Context) error {
 				return c.Send("Произошла ошибка при сохранении изменений.")
 			}
 
-			if _, err := b.bot.Send(&telebot.User{ID: targetID}, "С вас были сняты права администратора."); err != nil {
-				log.Printf("Ошибка отправки уведомления пользователю %d: %v", targetID, err)
-			}
+			b.enqueue(targetID, "С вас были сняты права администратора.")
 			delete(b.adminActions, c.Sender().ID)
 			return c.Send(fmt.Sprintf("С пользователя %s %s сняты права администратора.",
 				targetUser.FirstName, targetUser.LastName))
@@ -926,143 +861,52 @@ func (b *Bot) handleMessage(c telebot.Context) error {
 	return nil
 }
 
-// Start запускает обработчики бота и фоновую обработку уведомлений.
-func (b *Bot) Start() {
-	// Запускаем обработку уведомлений
-	go func() {
-		for msg := range b.notifications {
-			b.SendNotification(msg)
-		}
-	}()
+// Start запускает обработчики бота и фоновую отправку сообщений.
+// ctx становится корневым контекстом для запросов к Yougile: после его отмены
+// незавершённые запросы и ожидания между повторами прерываются.
+func (b *Bot) Start(ctx context.Context) {
+	b.ctx = ctx
 
-	go b.bot.Start()
-}
+	// Отправляем сообщения из очереди, в том числе оставшиеся с прошлого запуска
+	go b.runSendQueue(ctx)
+	// Повторяем операции, отложенные из-за недоступности Yougile
+	go b.runOutbox(ctx)
+	// Пересылаем комментарии исполнителей авторам задач
+	go b.runChatRelay(ctx)
 
-// startFullScan запускает фоновый fullscan, который проверяет пронумерованные ключи ITS-<n>.
-// rng — количество последовательных номеров для проверки за запуск.
-func (b *Bot) startFullScan(rng int) error {
-	b.fullScanMu.Lock()
-	defer b.fullScanMu.Unlock()
-	if b.fullScanRunning {
-		return fmt.Errorf("fullscan уже запущен")
-	}
-	ctx, cancel := context.WithCancel(context.Background())
-	b.fullScanCancel = cancel
-	b.fullScanRunning = true
-	go b.fullScanLoop(ctx, rng)
-	return nil
-}
-
-// stopFullScan останавливает запущенный fullscan.
-func (b *Bot) stopFullScan() error {
-	b.fullScanMu.Lock()
-	defer b.fullScanMu.Unlock()
-	if !b.fullScanRunning {
-		return fmt.Errorf("fullscan не запущен")
-	}
-	if b.fullScanCancel != nil {
-		b.fullScanCancel()
-	}
-	b.fullScanRunning = false
-	return nil
-}
-
-// fullScanLoop выполняет последовательные вызовы GetTaskByID для ITS-N с динамическим throttle.
-// Поведение: пока находятся новые задачи — 1 запрос в секунду; если в течение 1 минуты новых задач нет — 1 запрос в минуту.
-func (b *Bot) fullScanLoop(ctx context.Context, rng int) {
-	defer func() {
-		b.fullScanMu.Lock()
-		b.fullScanRunning = false
-		b.fullScanMu.Unlock()
-	}()
-
-	last := b.storage.GetLastScanned()
-	if last < 0 {
-		last = 0
-	}
-
-	idleSince := time.Time{}
-	throttleShort := time.Second
-	throttleLong := time.Minute
-	currentThrottle := throttleShort
-
-	for i := 1; i <= rng; i++ {
-		select {
-		case <-ctx.Done():
-			log.Printf("fullScanLoop: cancelled")
-			return
-		default:
-		}
-
-		n := last + i
-		key := fmt.Sprintf("ITS-%d", n)
-		task, err := b.yougileClient.GetTaskByID(key)
-		if err == nil && task != nil {
-			// found
-			tkey := key
-			if task.Key != "" {
-				tkey = task.Key
-			} else if task.ExternalID != "" {
-				tkey = task.ExternalID
-			}
-			known := b.storage.IsKnownKey(tkey)
-			log.Printf("fullScanLoop: found task by key=%s id=%d external=%s title=%q done=%v known=%v", tkey, task.ID, task.ExternalID, task.Title, task.Done, known)
-			if !known {
-				b.storage.AddKnownKey(tkey)
-				if task.ID != 0 {
-					b.storage.AddKnownTask(task.ID)
-				}
-				if !task.Done {
-					// Log chat targets
-					chats := b.storage.GetChatIDs()
-					log.Printf("fullScanLoop: sending notification for task %s to %d chats", tkey, len(chats))
-					b.SendNotification(b.formatTaskNotification(*task))
-					log.Printf("fullScanLoop: SendNotification called for task %s", tkey)
-				}
-			}
-			b.storage.SetLastScanned(n)
-			idleSince = time.Time{} // reset idle timer
-			currentThrottle = throttleShort
-		} else {
-			// not found or error
-			if idleSince.IsZero() {
-				idleSince = time.Now()
-			}
-			if time.Since(idleSince) > time.Minute {
-				currentThrottle = throttleLong
-			}
-		}
-
-		// sleep respecting the current throttle but exit early on cancel
-		sleepUntil := time.Now().Add(currentThrottle)
-		for time.Now().Before(sleepUntil) {
-			select {
-			case <-ctx.Done():
-				log.Printf("fullScanLoop: cancelled during sleep")
-				return
-			case <-time.After(200 * time.Millisecond):
-			}
-		}
-	}
+	go b.bot.Start()
 }
 
-// Stop корректно завершает работу бота и закрывает канал уведомлений.
+// Stop корректно завершает работу бота. Неотправленные сообщения остаются
+// в очереди отправки и будут доставлены после перезапуска.
 func (b *Bot) Stop() {
-	close(b.notifications)
 	b.bot.Stop()
 }
 
 // SendNotification отправляет указанное сообщение во все чаты, зарегистрированные в хранилище.
-func (b *Bot) SendNotification(msg string) {
-	chats := b.storage.GetChatIDs()
+// opts передаются в telebot (например, клавиатура).
+func (b *Bot) SendNotification(msg string, opts ...interface{}) {
+	b.sendToChats(b.storage.GetChatIDs(), msg, opts...)
+}
+
+// sendToChats отправляет сообщение в перечисленные чаты.
+func (b *Bot) sendToChats(chats []int64, msg string, opts ...interface{}) {
 	if len(chats) == 0 {
 		log.Printf("SendNotification: пропускаем отправку — нет зарегистрированных chat_ids")
 		return
 	}
 	for _, chatID := range chats {
-		if _, err := b.bot.Send(&telebot.Chat{ID: chatID}, msg); err != nil {
-			log.Printf("Ошибка отправки уведомления в чат %d: %v", chatID, err)
+		b.enqueue(chatID, msg, opts...)
+	}
+}
+
+// NotifyAdmins отправляет сообщение всем администраторам в личные сообщения.
+func (b *Bot) NotifyAdmins(msg string) {
+	for _, user := range b.storage.GetUsers() {
+		if user.Role != models.RoleAdmin {
+			continue
 		}
+		b.enqueue(user.TelegramID, msg)
 	}
 }
 
@@ -1172,14 +1016,10 @@ func (b *Bot) handleApprove(c telebot.Context) error {
 			userMsg = "Ваш новый адрес подтвержден."
 		}
 		msg := userMsg
-		if _, err := b.bot.Send(&telebot.User{ID: userID}, msg); err != nil {
-			log.Printf("Ошибка отправки уведомления пользователю %d: %v", userID, err)
-		}
+		b.enqueue(userID, msg)
 		// Если подтверждена регистрация — показываем основное меню пользователю
 		if req.Type == "registration" {
-			if _, err := b.bot.Send(&telebot.User{ID: userID}, "Добро пожаловать!", b.menuForUserID(userID)); err != nil {
-				log.Printf("Ошибка отправки mainMenu пользователю %d: %v", userID, err)
-			}
+			b.enqueue(userID, "Добро пожаловать!", b.menuForUserID(userID))
 		}
 
 		return c.Send("Запрос подтвержден.")
@@ -1250,9 +1090,7 @@ func (b *Bot) handleReject(c telebot.Context) error {
 		} else {
 			userMsg = "Изменение адреса отклонено. Пожалуйста, свяжитесь с администратором."
 		}
-		if _, err := b.bot.Send(&telebot.User{ID: userID}, userMsg); err != nil {
-			log.Printf("Ошибка отправки уведомления пользователю %d: %v", userID, err)
-		}
+		b.enqueue(userID, userMsg)
 
 		return c.Send("Запрос отклонен.")
 	}
@@ -1261,11 +1099,6 @@ func (b *Bot) handleReject(c telebot.Context) error {
 	return b.showPendingRequests(c)
 }
 
-// NotificationChannel возвращает канал для отправки уведомлений
-func (b *Bot) NotificationChannel() chan<- string {
-	return b.notifications
-}
-
 // handleDocument обрабатывает отправку документов
 func (b *Bot) handleDocument(c telebot.Context) error {
 	// Проверяем, что пользователь авторизован
@@ -0,0 +1,186 @@
+// Package bot содержит пересылку комментариев между чатами задач Yougile и Telegram.
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"yougile_bot4/internal/api"
+	"yougile_bot4/internal/models"
+
+	"gopkg.in/telebot.v3"
+)
+
+const (
+	// chatRelayInterval — период проверки новых комментариев в задачах.
+	chatRelayInterval = 2 * time.Minute
+	// chatRelayMaxAge — комментарии пересылаются только для задач моложе этого срока.
+	chatRelayMaxAge = 30 * 24 * time.Hour
+	// relayMarker открывает комментарии, отправленные из Telegram, чтобы не пересылать их обратно.
+	relayMarker = "📨 Из Telegram"
+	// maxRelayText ограничивает длину пересылаемого комментария (в символах).
+	maxRelayText = 3000
+)
+
+// runChatRelay периодически пересылает новые комментарии авторам задач до отмены ctx.
+func (b *Bot) runChatRelay(ctx context.Context) {
+	ticker := time.NewTicker(chatRelayInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			b.relayComments()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// relayComments проверяет чаты задач, созданных через бота, и пересылает новые
+// комментарии исполнителей пользователям, оставившим заявку.
+func (b *Bot) relayComments() {
+	if !b.relayMu.TryLock() {
+		return
+	}
+	defer b.relayMu.Unlock()
+
+	ctx := api.Background(b.ctx)
+	for _, task := range b.storage.GetTasks() {
+		if ctx.Err() != nil || !b.relayTask(ctx, task) {
+			break
+		}
+	}
+	if err := b.storage.SaveData(); err != nil {
+		log.Printf("Ошибка сохранения состояния пересылки комментариев: %v", err)
+	}
+}
+
+// relayTask пересылает новые комментарии одной задачи, если она создана через бота.
+// Возвращает false, если проверку остальных задач нужно прекратить
+// (ключ отклонён или Yougile недоступен).
+func (b *Bot) relayTask(ctx context.Context, task *models.Task) bool {
+	if task.RequesterID == 0 || task.Done || time.Since(task.CreatedAt) > chatRelayMaxAge {
+		return true
+	}
+	chatID := outboxTaskID(task)
+	if chatID == "" || chatID == "0" {
+		return true
+	}
+	cursor, tracked := b.storage.GetChatCursor(chatID)
+	since := task.CreatedAt
+	if tracked {
+		since = time.UnixMilli(cursor)
+	}
+	messages, err := b.yougileClient.ListChatMessages(ctx, chatID, since)
+	if err != nil {
+		if errors.Is(err, api.ErrUnauthorized) {
+			b.ReportAPIError(err)
+			return false
+		}
+		if api.IsUnavailable(err) {
+			log.Printf("chat relay: Yougile недоступен, проверка комментариев отложена: %v", err)
+			return false
+		}
+		log.Printf("chat relay: ошибка получения комментариев задачи %s: %v", chatID, err)
+		return true
+	}
+
+	last := since.UnixMilli()
+	if tracked {
+		last = cursor
+	}
+	for _, m := range messages {
+		if m.ID <= last {
+			continue
+		}
+		last = m.ID
+		if isOwnComment(task, m) {
+			continue
+		}
+		b.forwardComment(ctx, task, chatID, m)
+	}
+	if !tracked || last != cursor {
+		b.storage.SetChatCursor(chatID, last)
+	}
+	return true
+}
+
+// isOwnComment сообщает, что сообщение отправлено самим ботом: ответ из Telegram,
+// комментарий, добавленный при создании задачи, или ссылка на загруженное вложение.
+func isOwnComment(task *models.Task, m models.ChatMessage) bool {
+	text := strings.TrimSpace(m.Text)
+	if strings.HasPrefix(text, relayMarker) {
+		return true
+	}
+	for _, fileURL := range task.Attachments {
+		if strings.Contains(text, fileURL) {
+			return true
+		}
+	}
+	for _, comment := range task.Comments {
+		if strings.TrimSpace(comment.Text) == text {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardComment отправляет комментарий автору задачи и запоминает сообщение,
+// чтобы ответ на него ушёл обратно в чат задачи.
+func (b *Bot) forwardComment(ctx context.Context, task *models.Task, chatID string, m models.ChatMessage) {
+	author := "Исполнитель"
+	if m.FromUserID != "" {
+		if names := b.yougileClient.UserNames(ctx, []string{m.FromUserID}); len(names) > 0 {
+			author = names[0]
+		}
+	}
+	msg := fmt.Sprintf("💬 Комментарий к задаче «%s»\n👤 %s:\n%s\n\n↩️ Ответьте на это сообщение, чтобы написать исполнителю.",
+		task.Title, author, truncateRunes(strings.TrimSpace(m.Text), maxRelayText))
+	b.queueMessage(models.OutgoingMessage{
+		ChatID: task.RequesterID,
+		Text:   msg,
+		Relay:  &models.RelayReply{TaskID: chatID, Title: task.Title},
+	})
+}
+
+// handleRelayReply отправляет в чат задачи ответ пользователя на пересланный комментарий.
+// Возвращает false, если сообщение не является таким ответом.
+func (b *Bot) handleRelayReply(c telebot.Context) (bool, error) {
+	msg := c.Message()
+	if msg == nil || msg.ReplyTo == nil || c.Chat() == nil {
+		return false, nil
+	}
+	reply, ok := b.storage.GetRelayReply(c.Chat().ID, msg.ReplyTo.ID)
+	if !ok {
+		return false, nil
+	}
+	text := strings.TrimSpace(c.Text())
+	if text == "" {
+		return true, c.Send("Пустой ответ не отправлен.")
+	}
+	name := c.Sender().FirstName
+	if user, exists := b.storage.GetUser(c.Sender().ID); exists && user != nil {
+		name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+	}
+	body := fmt.Sprintf("%s, %s:\n%s", relayMarker, name, text)
+	if _, err := b.yougileClient.SendChatMessage(b.ctx, reply.TaskID, body); err != nil {
+		log.Printf("chat relay: ошибка отправки ответа в задачу %s: %v", reply.TaskID, err)
+		b.ReportAPIError(err)
+		return true, c.Send(yougileErrorText(err, "Не удалось отправить ответ в Yougile. Пожалуйста, попробуйте позже."))
+	}
+	return true, c.Send(fmt.Sprintf("✅ Ответ отправлен в задачу «%s».", reply.Title))
+}
+
+// truncateRunes обрезает s до n символов, не разрывая многобайтовые символы.
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:n]) + "…"
+}
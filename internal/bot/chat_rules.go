@@ -0,0 +1,182 @@
+// Package bot содержит правила подписки чатов на уведомления о задачах:
+// отбор чатов для уведомления и команды /rules, /addrule, /delrule.
+package bot
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"yougile_bot4/internal/api"
+	"yougile_bot4/internal/models"
+	"yougile_bot4/internal/routing"
+
+	"gopkg.in/telebot.v3"
+)
+
+// ruleUsage — подсказка по командам правил подписки.
+const ruleUsage = "Добавить правило (в чате, который нужно настроить, или с ID чата первым аргументом):\n" +
+	"/addrule [chat_id] board=it; column=ID; prio=high,medium; label=Сантехника; building=Корпус 1; re=протеч|кран; done=no\n" +
+	"Условия разделяются «;», значения — запятыми; board — имя направления (/boards), main или ID доски.\n" +
+	"Чат без правил получает все уведомления, с правилами — подходящие хотя бы под одно.\n" +
+	"Удалить: /delrule [chat_id] <номер|all>"
+
+// ruleChats отбирает из chats чаты, подписанные правилами на задачу task.
+// Названия стикеров и здание запрашиваются, только если правила заданы.
+func (b *Bot) ruleChats(task models.Task, chats []int64) []int64 {
+	rules := b.storage.GetChatRules()
+	if len(rules) == 0 {
+		return chats
+	}
+	return routing.Chats(chats, rules, b.routingFacts(task))
+}
+
+// routingFacts собирает сведения о задаче для правил: названия значений
+// стикеров и здание (по стикеру здания или адресу автора заявки).
+func (b *Bot) routingFacts(task models.Task) routing.Facts {
+	f := routing.FactsOf(task)
+	if len(task.Stickers) > 0 && task.BoardID != "" {
+		stickers, err := b.yougileClient.ListStickers(api.Background(b.ctx), task.BoardID)
+		if err != nil {
+			log.Printf("Правила уведомлений: ошибка получения стикеров доски %s: %v", task.BoardID, err)
+		}
+		buildingSticker := b.storage.GetStickerMap()[models.StickerSourceBuilding]
+		named := make(map[string]bool)
+		for _, s := range stickers {
+			value, ok := task.Stickers[s.ID]
+			if !ok {
+				continue
+			}
+			named[s.ID] = true
+			for _, st := range s.States {
+				if st.ID == value {
+					value = st.Name
+					break
+				}
+			}
+			f.Labels = append(f.Labels, value)
+			if s.ID == buildingSticker {
+				f.Building = value
+			}
+		}
+		for id, value := range task.Stickers {
+			if !named[id] {
+				f.Labels = append(f.Labels, value)
+			}
+		}
+	}
+	if f.Building == "" && task.RequesterID != 0 {
+		if user, ok := b.storage.GetUser(task.RequesterID); ok {
+			f.Building = user.BuildingAddress
+		}
+	}
+	return f
+}
+
+// ruleTarget отделяет от аргументов команды ID чата, если он указан первым
+// и за ним следуют другие аргументы; иначе правило относится к текущему чату.
+func ruleTarget(c telebot.Context, args string) (int64, string) {
+	first, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+	if id, err := strconv.ParseInt(first, 10, 64); err == nil && strings.TrimSpace(rest) != "" {
+		return id, strings.TrimSpace(rest)
+	}
+	return c.Chat().ID, strings.TrimSpace(args)
+}
+
+// handleRules показывает правила подписки всех чатов.
+func (b *Bot) handleRules(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	rules := b.storage.GetChatRules()
+	if len(rules) == 0 {
+		return c.Send("Правил нет: все чаты получают все уведомления о задачах своих досок.\n\n" + ruleUsage)
+	}
+	byChat := make(map[int64][]models.ChatRule)
+	var chats []int64
+	for _, r := range rules {
+		if _, ok := byChat[r.ChatID]; !ok {
+			chats = append(chats, r.ChatID)
+		}
+		byChat[r.ChatID] = append(byChat[r.ChatID], r)
+	}
+	sort.Slice(chats, func(i, j int) bool { return chats[i] < chats[j] })
+
+	var sb strings.Builder
+	sb.WriteString("📬 Правила уведомлений по чатам:")
+	for _, chatID := range chats {
+		fmt.Fprintf(&sb, "\n\nЧат %d", chatID)
+		if chatID == c.Chat().ID {
+			sb.WriteString(" (этот)")
+		}
+		for i, r := range byChat[chatID] {
+			fmt.Fprintf(&sb, "\n%d. %s", i+1, routing.Describe(r))
+		}
+	}
+	sb.WriteString("\n\n" + ruleUsage)
+	return c.Send(sb.String())
+}
+
+// handleAddRule добавляет правило подписки чата ("/addrule [chat_id] <условия>").
+func (b *Bot) handleAddRule(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	chatID, spec := ruleTarget(c, strings.TrimPrefix(c.Text(), "/addrule"))
+	if spec == "" {
+		return c.Send(ruleUsage)
+	}
+	rule, err := routing.Parse(spec)
+	if err != nil {
+		return c.Send(fmt.Sprintf("%s.\n\n%s", capitalize(err.Error()), ruleUsage))
+	}
+	for i, board := range rule.Boards {
+		if board == "main" || board == mainRouteID {
+			if mainBoard, _ := b.target(); mainBoard != "" {
+				rule.Boards[i] = mainBoard
+			}
+		} else if r, ok := b.storage.GetBoardRoute(board); ok {
+			rule.Boards[i] = r.BoardID
+		}
+	}
+	rule.ChatID = chatID
+	b.storage.AddChatRule(rule)
+	if err := b.storage.SaveData(); err != nil {
+		log.Printf("Ошибка сохранения настроек: %v", err)
+	}
+	return c.Send(fmt.Sprintf("Правило %d для чата %d добавлено: %s",
+		len(b.storage.GetChatRulesFor(chatID)), chatID, routing.Describe(rule)))
+}
+
+// handleDelRule удаляет правило подписки чата по номеру из /rules или все
+// правила чата ("/delrule [chat_id] <номер|all>").
+func (b *Bot) handleDelRule(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	chatID, arg := ruleTarget(c, strings.TrimPrefix(c.Text(), "/delrule"))
+	n := -1
+	if arg != "all" {
+		v, err := strconv.Atoi(arg)
+		if err != nil || v <= 0 {
+			return c.Send("Использование: /delrule [chat_id] <номер|all>. Номера правил: /rules")
+		}
+		n = v - 1
+	}
+	removed := b.storage.RemoveChatRule(chatID, n)
+	if removed == 0 {
+		return c.Send(fmt.Sprintf("У чата %d нет такого правила. Список: /rules", chatID))
+	}
+	if err := b.storage.SaveData(); err != nil {
+		log.Printf("Ошибка сохранения настроек: %v", err)
+	}
+	if len(b.storage.GetChatRulesFor(chatID)) == 0 {
+		return c.Send(fmt.Sprintf("Правила чата %d удалены: он снова получает все уведомления.", chatID))
+	}
+	return c.Send(fmt.Sprintf("Удалено правил чата %d: %d.", chatID, removed))
+}
@@ -0,0 +1,211 @@
+// Package bot содержит управление исполнителями задач: связь пользователей
+// Telegram с сотрудниками Yougile.
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"yougile_bot4/internal/api"
+	"yougile_bot4/internal/models"
+
+	"gopkg.in/telebot.v3"
+)
+
+// maxMessageLen — предел длины сообщения, после которого список отправляется частями
+// (ограничение Telegram — 4096 символов).
+const maxMessageLen = 3500
+
+// parseTelegramUser разбирает аргумент команды: числовой Telegram ID или @username
+// зарегистрированного пользователя.
+func (b *Bot) parseTelegramUser(arg string) (int64, error) {
+	arg = strings.TrimSpace(arg)
+	if strings.HasPrefix(arg, "@") {
+		if id := b.storage.GetUserIDByUsername(strings.TrimPrefix(arg, "@")); id != 0 {
+			return id, nil
+		}
+		return 0, fmt.Errorf("пользователь %s не найден", arg)
+	}
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("неверный Telegram ID: %q", arg)
+	}
+	return id, nil
+}
+
+// defaultAssigned возвращает исполнителей Yougile для новых задач.
+func (b *Bot) defaultAssigned() []string {
+	if link, ok := b.storage.GetDefaultExecutor(); ok {
+		return []string{link.YougileUserID}
+	}
+	return nil
+}
+
+// ResolveExecutors заполняет task.Assignee именами исполнителей из Yougile.
+func (b *Bot) ResolveExecutors(task *models.Task) {
+	if len(task.Assigned) == 0 {
+		return
+	}
+	names := b.yougileClient.UserNames(api.Background(b.ctx), task.Assigned)
+	task.Assignee = strings.Join(names, ", ")
+}
+
+// sendChunked отправляет длинный текст несколькими сообщениями по строкам.
+func sendChunked(c telebot.Context, text string) error {
+	var chunk strings.Builder
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if chunk.Len()+len(line) > maxMessageLen && chunk.Len() > 0 {
+			if err := c.Send(chunk.String()); err != nil {
+				return err
+			}
+			chunk.Reset()
+		}
+		chunk.WriteString(line)
+	}
+	if chunk.Len() == 0 {
+		return nil
+	}
+	return c.Send(chunk.String())
+}
+
+// handleYougileUsers показывает сотрудников компании Yougile и их связи с пользователями бота.
+func (b *Bot) handleYougileUsers(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	users, err := b.yougileClient.ListUsers(b.ctx)
+	if err != nil {
+		log.Printf("Ошибка получения сотрудников Yougile: %v", err)
+		b.ReportAPIError(err)
+		return c.Send(yougileErrorText(err, "Не удалось получить список сотрудников Yougile."))
+	}
+	if len(users) == 0 {
+		return c.Send("В компании Yougile нет сотрудников.")
+	}
+	executor, hasExecutor := b.storage.GetDefaultExecutor()
+
+	var sb strings.Builder
+	sb.WriteString("Сотрудники Yougile:\n")
+	for _, u := range users {
+		sb.WriteString(fmt.Sprintf("\n• %s", u.DisplayName()))
+		if u.Email != "" && u.RealName != "" {
+			sb.WriteString(fmt.Sprintf(" <%s>", u.Email))
+		}
+		if link, ok := b.storage.FindYougileLink(u.ID); ok {
+			sb.WriteString(fmt.Sprintf(" ↔ Telegram %d", link.TelegramID))
+			if hasExecutor && executor.TelegramID == link.TelegramID {
+				sb.WriteString(" (исполнитель новых задач)")
+			}
+		}
+	}
+	sb.WriteString("\n\nСвязать: /link <telegram_id|@username> <email|id сотрудника>\nОтвязать: /unlink <telegram_id|@username>\nИсполнитель новых задач: /executor <telegram_id|@username|off>")
+	return sendChunked(c, sb.String())
+}
+
+// handleLinkUser связывает пользователя Telegram с сотрудником Yougile.
+func (b *Bot) handleLinkUser(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	args := strings.Fields(strings.TrimSpace(strings.TrimPrefix(c.Text(), "/link")))
+	if len(args) != 2 {
+		return c.Send("Использование: /link <telegram_id|@username> <email|id сотрудника Yougile>")
+	}
+	telegramID, err := b.parseTelegramUser(args[0])
+	if err != nil {
+		return c.Send(err.Error())
+	}
+	user, err := b.yougileClient.FindUser(b.ctx, args[1])
+	if err != nil {
+		if errors.Is(err, api.ErrNotFound) {
+			return c.Send(fmt.Sprintf("Сотрудник %s не найден в Yougile. Список сотрудников: /yusers", args[1]))
+		}
+		log.Printf("Ошибка поиска сотрудника Yougile %s: %v", args[1], err)
+		b.ReportAPIError(err)
+		return c.Send(yougileErrorText(err, "Не удалось получить список сотрудников Yougile."))
+	}
+
+	b.storage.LinkYougileUser(models.UserLink{
+		TelegramID:    telegramID,
+		YougileUserID: user.ID,
+		Email:         user.Email,
+		RealName:      user.RealName,
+		LinkedBy:      c.Sender().ID,
+		LinkedAt:      time.Now(),
+	})
+	if err := b.storage.SaveData(); err != nil {
+		log.Printf("Ошибка сохранения настроек: %v", err)
+	}
+	return c.Send(fmt.Sprintf("✅ Пользователь %d связан с сотрудником Yougile %s.", telegramID, user.DisplayName()))
+}
+
+// handleUnlinkUser удаляет связь пользователя Telegram с сотрудником Yougile.
+func (b *Bot) handleUnlinkUser(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	arg := strings.TrimSpace(strings.TrimPrefix(c.Text(), "/unlink"))
+	if arg == "" {
+		return c.Send("Использование: /unlink <telegram_id|@username>")
+	}
+	telegramID, err := b.parseTelegramUser(arg)
+	if err != nil {
+		return c.Send(err.Error())
+	}
+	if !b.storage.UnlinkYougileUser(telegramID) {
+		return c.Send(fmt.Sprintf("Пользователь %d не связан с сотрудником Yougile.", telegramID))
+	}
+	if err := b.storage.SaveData(); err != nil {
+		log.Printf("Ошибка сохранения настроек: %v", err)
+	}
+	return c.Send(fmt.Sprintf("Связь пользователя %d с Yougile удалена.", telegramID))
+}
+
+// handleExecutor показывает или задаёт исполнителя, назначаемого на новые задачи.
+func (b *Bot) handleExecutor(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	arg := strings.TrimSpace(strings.TrimPrefix(c.Text(), "/executor"))
+	switch arg {
+	case "":
+		link, ok := b.storage.GetDefaultExecutor()
+		if !ok {
+			return c.Send("Исполнитель новых задач не назначен.\nНазначить: /executor <telegram_id|@username>")
+		}
+		return c.Send(fmt.Sprintf("Новые задачи назначаются на %s (Telegram %d).", linkName(link), link.TelegramID))
+	case "off":
+		b.storage.SetDefaultExecutor(0)
+		if err := b.storage.SaveData(); err != nil {
+			log.Printf("Ошибка сохранения настроек: %v", err)
+		}
+		return c.Send("Новые задачи больше не назначаются на исполнителя.")
+	}
+
+	telegramID, err := b.parseTelegramUser(arg)
+	if err != nil {
+		return c.Send(err.Error())
+	}
+	link, ok := b.storage.GetYougileLink(telegramID)
+	if !ok {
+		return c.Send(fmt.Sprintf("Пользователь %d не связан с сотрудником Yougile. Сначала выполните /link.", telegramID))
+	}
+	b.storage.SetDefaultExecutor(telegramID)
+	if err := b.storage.SaveData(); err != nil {
+		log.Printf("Ошибка сохранения настроек: %v", err)
+	}
+	return c.Send(fmt.Sprintf("✅ Новые задачи будут назначаться на %s.", linkName(link)))
+}
+
+// linkName возвращает имя сотрудника из связи для отображения.
+func linkName(link models.UserLink) string {
+	return models.YougileUser{ID: link.YougileUserID, Email: link.Email, RealName: link.RealName}.DisplayName()
+}
@@ -0,0 +1,77 @@
+// Package bot содержит подключение шаблонов уведомлений и команду
+// администраторов /template для их просмотра и перезагрузки.
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"yougile_bot4/internal/models"
+	"yougile_bot4/internal/notify"
+
+	"gopkg.in/telebot.v3"
+)
+
+// SetMessageTemplates подключает набор шаблонов уведомлений. Вызывается до Start.
+func (b *Bot) SetMessageTemplates(set *notify.Set) {
+	b.messages = set
+}
+
+// handleTemplate показывает шаблоны уведомлений: "/template" — список,
+// "/template <имя>" — пример сообщения и текст шаблона, "/template reload" —
+// перечитать файлы шаблонов.
+func (b *Bot) handleTemplate(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	arg := strings.TrimSpace(strings.TrimPrefix(c.Text(), "/template"))
+	switch arg {
+	case "":
+		return c.Send(b.templateList())
+	case "reload":
+		if err := b.messages.Reload(); err != nil {
+			log.Printf("Шаблоны уведомлений: %v", err)
+			return c.Send(fmt.Sprintf("⚠️ %s.\n\n%s", capitalize(err.Error()), b.templateList()))
+		}
+		return c.Send("Шаблоны уведомлений перечитаны.\n\n" + b.templateList())
+	}
+
+	source, custom := b.messages.Source(arg)
+	if source == "" {
+		return c.Send(fmt.Sprintf("Шаблона %s нет.\n\n%s", arg, b.templateList()))
+	}
+	origin := "встроенный"
+	if custom {
+		origin = "из файла " + arg + ".tmpl"
+	}
+	text := fmt.Sprintf("👁 Пример сообщения %s (%s):\n\n%s\n\n— — —\nТекст шаблона:\n%s",
+		arg, origin, b.messages.Render(arg, notify.Sample(arg)), source)
+	if err := b.messages.Err(arg); err != nil {
+		text += fmt.Sprintf("\n\n⚠️ Файл %s.tmpl не применён: %v", arg, err)
+	}
+	return c.Send(text)
+}
+
+// templateList перечисляет шаблоны уведомлений и их источник.
+func (b *Bot) templateList() string {
+	var sb strings.Builder
+	sb.WriteString("📝 Шаблоны уведомлений:")
+	for _, name := range notify.Names() {
+		_, custom := b.messages.Source(name)
+		switch {
+		case b.messages.Err(name) != nil:
+			fmt.Fprintf(&sb, "\n• %s — встроенный, файл с ошибкой", name)
+		case custom:
+			fmt.Fprintf(&sb, "\n• %s — из файла", name)
+		default:
+			fmt.Fprintf(&sb, "\n• %s — встроенный", name)
+		}
+	}
+	if dir := b.messages.Dir(); dir != "" {
+		fmt.Fprintf(&sb, "\n\nФайлы <имя>.tmpl (text/template) читаются из %s.", dir)
+	}
+	sb.WriteString("\nПример и текст шаблона: /template <имя>\nПеречитать файлы: /template reload")
+	return sb.String()
+}
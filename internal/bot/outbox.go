@@ -0,0 +1,289 @@
+// Package bot содержит отложенную отправку операций в Yougile (outbox).
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"yougile_bot4/internal/api"
+	"yougile_bot4/internal/models"
+)
+
+// outboxInterval — период повторной отправки отложенных операций.
+const outboxInterval = 30 * time.Second
+
+// outboxDir — каталог для содержимого отложенных вложений (переменная, чтобы
+// тесты могли заменить его временным каталогом).
+var outboxDir = "data/outbox"
+
+// Сообщения пользователю об отложенных операциях.
+const (
+	msgTaskQueued    = "Yougile сейчас недоступен. Задача сохранена и будет создана автоматически, как только сервис восстановится, — мы пришлём уведомление."
+	msgCommentQueued = "Yougile сейчас недоступен. Комментарий сохранён и будет добавлен к задаче автоматически."
+)
+
+// createTaskOrQueue создаёт задачу в Yougile, а если Yougile недоступен —
+// сохраняет её в outbox. Возвращает номер операции в очереди (0, если задача
+// создана сразу) или ошибку, если задачу не удалось ни создать, ни отложить.
+// content и image (фотография, уже загруженная в Yougile) нужны для проверки
+// задачи после создания; фотография сохраняется в outboxDir вместе с задачей.
+func (b *Bot) createTaskOrQueue(task *models.Task, requester int64, content string, image []byte) (int64, error) {
+	err := b.yougileClient.CreateTask(b.ctx, task)
+	if err == nil || !api.IsUnavailable(err) {
+		return 0, err
+	}
+	log.Printf("Yougile недоступен, задача %q отложена: %v", task.Title, err)
+	var imagePath string
+	if len(image) > 0 {
+		path, serr := saveOutboxFile("task_image.jpg", image)
+		if serr != nil {
+			log.Printf("Ошибка сохранения фотографии отложенной задачи: %v", serr)
+		} else {
+			imagePath = path
+		}
+	}
+	id := b.storage.EnqueueOutbox(models.OutboxItem{
+		Kind:        models.OutboxCreateTask,
+		RequesterID: requester,
+		CreatedAt:   time.Now(),
+		LastError:   err.Error(),
+		Task:        task,
+		Content:     content,
+		HasImage:    imagePath != "",
+		FilePath:    imagePath,
+	})
+	if serr := b.storage.SaveData(); serr != nil {
+		log.Printf("Ошибка сохранения очереди отложенных операций: %v", serr)
+	}
+	return id, nil
+}
+
+// queueComment откладывает добавление комментария. Задача задаётся либо
+// идентификатором в Yougile, либо номером отложенной операции создания (taskRef).
+func (b *Bot) queueComment(taskID string, taskRef int64, comment *models.Comment, requester int64) {
+	b.storage.EnqueueOutbox(models.OutboxItem{
+		Kind:        models.OutboxAddComment,
+		RequesterID: requester,
+		CreatedAt:   time.Now(),
+		Comment:     comment,
+		TaskID:      taskID,
+		TaskRef:     taskRef,
+	})
+	if err := b.storage.SaveData(); err != nil {
+		log.Printf("Ошибка сохранения очереди отложенных операций: %v", err)
+	}
+}
+
+// queueAttachment откладывает загрузку вложения; содержимое сохраняется в outboxDir.
+// Задача задаётся либо идентификатором в Yougile, либо номером отложенной операции создания (taskRef).
+func (b *Bot) queueAttachment(taskID string, taskRef int64, attachment *models.Attachment, data []byte, requester int64) error {
+	path, err := saveOutboxFile(attachment.ID, data)
+	if err != nil {
+		return err
+	}
+	b.storage.EnqueueOutbox(models.OutboxItem{
+		Kind:        models.OutboxUploadAttachment,
+		RequesterID: requester,
+		CreatedAt:   time.Now(),
+		Attachment:  attachment,
+		TaskID:      taskID,
+		TaskRef:     taskRef,
+		FilePath:    path,
+	})
+	if err := b.storage.SaveData(); err != nil {
+		log.Printf("Ошибка сохранения очереди отложенных операций: %v", err)
+	}
+	return nil
+}
+
+// saveOutboxFile сохраняет содержимое файла name в outboxDir и возвращает путь к нему.
+func saveOutboxFile(name string, data []byte) (string, error) {
+	if err := os.MkdirAll(outboxDir, 0755); err != nil {
+		return "", fmt.Errorf("ошибка создания каталога outbox: %w", err)
+	}
+	path := filepath.Join(outboxDir, fmt.Sprintf("%d_%s", time.Now().UnixNano(), filepath.Base(name)))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("ошибка сохранения вложения: %w", err)
+	}
+	return path, nil
+}
+
+// runOutbox периодически повторяет отложенные операции до отмены ctx.
+func (b *Bot) runOutbox(ctx context.Context) {
+	ticker := time.NewTicker(outboxInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			b.replayOutbox()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// replayOutbox отправляет отложенные операции по порядку. Пока Yougile недоступен
+// (в том числе пока разомкнут выключатель клиента), обработка останавливается на
+// первой операции, чтобы сохранить порядок. Операции с постоянной ошибкой
+// удаляются из очереди, а пользователь и администраторы получают уведомление.
+func (b *Bot) replayOutbox() {
+	if !b.outboxMu.TryLock() {
+		return
+	}
+	defer b.outboxMu.Unlock()
+
+	items := b.storage.GetOutbox()
+	if len(items) == 0 {
+		return
+	}
+	for _, queued := range items {
+		if b.ctx.Err() != nil {
+			break
+		}
+		// Операцию перечитываем из хранилища: создание задачи выше по очереди
+		// проставляет её ID в зависящие от неё комментарии и вложения
+		item, ok := b.storage.GetOutboxItem(queued.ID)
+		if !ok {
+			continue
+		}
+		err := b.replayOutboxItem(item)
+		if err == nil {
+			b.storage.RemoveOutbox(item.ID)
+			continue
+		}
+		if api.IsUnavailable(err) {
+			item.Attempts++
+			item.LastError = err.Error()
+			b.storage.UpdateOutbox(item)
+			log.Printf("outbox: Yougile всё ещё недоступен, в очереди %d операций: %v", b.storage.OutboxLen(), err)
+			break
+		}
+		log.Printf("outbox: операция %d (%s) отброшена: %v", item.ID, item.Kind, err)
+		b.ReportAPIError(err)
+		b.storage.RemoveOutbox(item.ID)
+		b.notifyOutboxFailure(item, err)
+	}
+	if err := b.storage.SaveData(); err != nil {
+		log.Printf("Ошибка сохранения очереди отложенных операций: %v", err)
+	}
+}
+
+// replayOutboxItem выполняет одну отложенную операцию.
+func (b *Bot) replayOutboxItem(item models.OutboxItem) error {
+	switch item.Kind {
+	case models.OutboxCreateTask:
+		if item.Task == nil {
+			return fmt.Errorf("в операции нет задачи")
+		}
+		task := item.Task
+		if err := b.yougileClient.CreateTask(b.ctx, task); err != nil {
+			return err
+		}
+		b.storage.AddTask(task)
+		b.storage.ResolveOutboxRef(item.ID, outboxTaskID(task))
+		b.onQueuedTaskCreated(item, task)
+		return nil
+
+	case models.OutboxAddComment:
+		if item.Comment == nil {
+			return fmt.Errorf("в операции нет комментария")
+		}
+		if item.TaskID == "" {
+			// задача, к которой относился комментарий, так и не была создана
+			return fmt.Errorf("задача для комментария не создана")
+		}
+		if err := b.yougileClient.AddComment(b.ctx, item.TaskID, item.Comment); err != nil {
+			return err
+		}
+		for _, t := range b.storage.GetTasks() {
+			if outboxTaskID(t) == item.TaskID {
+				t.Comments = append(t.Comments, *item.Comment)
+				b.storage.UpdateTask(t)
+				break
+			}
+		}
+		return nil
+
+	case models.OutboxUploadAttachment:
+		if item.Attachment == nil {
+			return fmt.Errorf("в операции нет вложения")
+		}
+		if item.TaskID == "" {
+			// задача, к которой относилось вложение, так и не была создана
+			return fmt.Errorf("задача для вложения не создана")
+		}
+		data, err := os.ReadFile(item.FilePath)
+		if err != nil {
+			return fmt.Errorf("ошибка чтения отложенного вложения: %w", err)
+		}
+		if err := b.yougileClient.UploadAttachment(b.ctx, item.TaskID, item.Attachment, data); err != nil {
+			return err
+		}
+		if rerr := os.Remove(item.FilePath); rerr != nil {
+			log.Printf("outbox: ошибка удаления файла %s: %v", item.FilePath, rerr)
+		}
+		for _, t := range b.storage.GetTasks() {
+			if outboxTaskID(t) == item.TaskID {
+				t.Attachments = append(t.Attachments, item.Attachment.URL)
+				b.storage.UpdateTask(t)
+				break
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("неизвестный тип операции %q", item.Kind)
+}
+
+// onQueuedTaskCreated уведомляет автора о создании отложенной задачи и запускает её проверку.
+func (b *Bot) onQueuedTaskCreated(item models.OutboxItem, task *models.Task) {
+	msg := fmt.Sprintf("✅ Задача «%s», сохранённая во время недоступности Yougile, создана.\n🆔 %s", task.Title, outboxTaskID(task))
+	b.enqueue(item.RequesterID, msg)
+
+	sender := models.User{TelegramID: item.RequesterID}
+	if user, ok := b.storage.GetUser(item.RequesterID); ok && user != nil {
+		sender = *user
+	}
+	var imageData []byte
+	if item.HasImage && item.FilePath != "" {
+		data, err := os.ReadFile(item.FilePath)
+		if err != nil {
+			log.Printf("outbox: ошибка чтения фотографии %s для проверки: %v", item.FilePath, err)
+		}
+		imageData = data
+		if rerr := os.Remove(item.FilePath); rerr != nil && !os.IsNotExist(rerr) {
+			log.Printf("outbox: ошибка удаления файла %s: %v", item.FilePath, rerr)
+		}
+	}
+	b.startTaskVerification(*task, sender, item.Content, item.HasImage, imageData)
+}
+
+// notifyOutboxFailure сообщает автору и администраторам, что отложенную операцию выполнить не удалось.
+func (b *Bot) notifyOutboxFailure(item models.OutboxItem, err error) {
+	what := "операцию"
+	switch item.Kind {
+	case models.OutboxCreateTask:
+		if item.Task != nil {
+			what = fmt.Sprintf("задачу «%s»", item.Task.Title)
+		}
+	case models.OutboxAddComment:
+		what = "комментарий"
+	case models.OutboxUploadAttachment:
+		what = "вложение"
+	}
+	userMsg := fmt.Sprintf("❌ Не удалось отправить в Yougile отложенную %s. Пожалуйста, обратитесь к администратору.", what)
+	b.enqueue(item.RequesterID, userMsg)
+	b.NotifyAdmins(fmt.Sprintf("❌ Отложенная операция %s (пользователь %d) отброшена: %v", item.Kind, item.RequesterID, err))
+}
+
+// outboxTaskID возвращает идентификатор задачи для последующих вызовов API.
+func outboxTaskID(task *models.Task) string {
+	if task.ExternalID != "" {
+		return task.ExternalID
+	}
+	return strconv.FormatInt(task.ID, 10)
+}
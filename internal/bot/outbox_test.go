@@ -0,0 +1,153 @@
+// Package bot содержит тесты отложенной отправки операций в Yougile.
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"yougile_bot4/internal/api"
+	"yougile_bot4/internal/metrics"
+	"yougile_bot4/internal/models"
+	"yougile_bot4/internal/notify"
+	"yougile_bot4/internal/sendq"
+	"yougile_bot4/internal/storage"
+)
+
+// fakeYougile отвечает на запросы клиента Yougile и запоминает их.
+// Пока down, на всё отвечает 503.
+type fakeYougile struct {
+	mu       sync.Mutex
+	down     bool
+	requests []string
+}
+
+func (f *fakeYougile) setDown(down bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.down = down
+}
+
+func (f *fakeYougile) calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.requests...)
+}
+
+func (f *fakeYougile) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	down := f.down
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	f.mu.Unlock()
+	if down {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api-v2/tasks":
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":"t-new"}`)
+	case r.Method == http.MethodPost && r.URL.Path == "/api-v2/upload-file":
+		fmt.Fprint(w, `{"result":"ok","url":"user-data/photo.jpg"}`)
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/api-v2/chats/"):
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":1}`)
+	default:
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{}`)
+	}
+}
+
+// roundTripFunc позволяет подменить транспорт клиента функцией.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// newTestBot создаёт бота без подключения к Telegram: сообщения остаются в
+// очереди отправки, запросы к Yougile обслуживает yougile.
+func newTestBot(t *testing.T, yougile http.Handler) *Bot {
+	t.Helper()
+	dir := t.TempDir()
+	outboxDir = filepath.Join(dir, "outbox")
+	m := metrics.NewMetrics()
+	store, err := storage.NewStorage(filepath.Join(dir, "known.json"), filepath.Join(dir, "chats.json"), filepath.Join(dir, "users.json"),
+		filepath.Join(dir, "tasks.json"), filepath.Join(dir, "templates.json"), m)
+	if err != nil {
+		t.Fatalf("NewStorage failed: %v", err)
+	}
+	client := api.NewClient("token", "board", time.Second, m)
+	client.SetRetryPolicy(1, time.Millisecond, time.Second)
+	client.SetCircuitBreaker(0, 0)
+	client.SetTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		rec := httptest.NewRecorder()
+		yougile.ServeHTTP(rec, req)
+		resp := rec.Result()
+		resp.Request = req
+		return resp, nil
+	}))
+	b := &Bot{
+		storage:       store,
+		yougileClient: client,
+		metrics:       m,
+		ctx:           context.Background(),
+		messages:      notify.Default(),
+	}
+	b.queue = sendq.New(store, &telegramTransport{b: b}, m)
+	return b
+}
+
+// queuedTexts возвращает тексты сообщений пользователю chatID в очереди отправки.
+func queuedTexts(b *Bot, chatID int64) []string {
+	var texts []string
+	for _, m := range b.storage.GetSendQueue() {
+		if m.ChatID == chatID {
+			texts = append(texts, m.Text)
+		}
+	}
+	return texts
+}
+
+func TestReplayOutboxResolvesQueuedTask(t *testing.T) {
+	yougile := &fakeYougile{down: true}
+	b := newTestBot(t, yougile)
+	const requester = 42
+
+	task := &models.Task{Title: "Протечка", RequesterID: requester}
+	ref, err := b.createTaskOrQueue(task, requester, "Протечка", nil)
+	if err != nil || ref == 0 {
+		t.Fatalf("expected task to be queued while Yougile is down, got ref=%d err=%v", ref, err)
+	}
+	b.queueComment("", ref, &models.Comment{Text: "Уточнение"}, requester)
+	if err := b.queueAttachment("", ref, &models.Attachment{ID: "img.jpg", Type: models.AttachmentTypeImage}, []byte("jpeg"), requester); err != nil {
+		t.Fatalf("queueAttachment failed: %v", err)
+	}
+
+	// Пока Yougile недоступен, очередь не двигается
+	b.replayOutbox()
+	if n := b.storage.OutboxLen(); n != 3 {
+		t.Fatalf("expected all operations to stay queued, got %d", n)
+	}
+
+	yougile.setDown(false)
+	b.replayOutbox()
+	if n := b.storage.OutboxLen(); n != 0 {
+		t.Fatalf("expected outbox to be drained, left %+v", b.storage.GetOutbox())
+	}
+	calls := strings.Join(yougile.calls(), "\n")
+	for _, want := range []string{"POST /api-v2/tasks/t-new/comments", "POST /api-v2/upload-file", "POST /api-v2/chats/t-new/messages"} {
+		if !strings.Contains(calls, want) {
+			t.Errorf("expected %q after replay, got:\n%s", want, calls)
+		}
+	}
+	for _, text := range queuedTexts(b, requester) {
+		if strings.Contains(text, "Не удалось") {
+			t.Errorf("requester must not be told that a queued operation failed: %q", text)
+		}
+	}
+}
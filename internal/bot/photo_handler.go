@@ -5,10 +5,10 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"path/filepath"
 	"strconv"
 	"time"
 
+	"yougile_bot4/internal/api"
 	"yougile_bot4/internal/models"
 
 	"gopkg.in/telebot.v3"
@@ -66,89 +66,19 @@ func (b *Bot) handlePhoto(c telebot.Context) error {
 			caption = "[Фотография к задаче]"
 		}
 
-		// Создаем задачу с фотографией
-		user, _ := b.storage.GetUser(c.Sender().ID)
-		desc := caption
-		if user != nil {
-			desc = b.formatTaskDescription(user, caption)
-		}
-		task := &models.Task{
-			Title:       b.formatTaskTitle(user, state.Title),
-			Description: desc,
-			Status:      models.TaskStatusNew,
-			BoardID:     b.boardID,
-			Priority:    1,
-			Assignee:    strconv.FormatInt(c.Sender().ID, 10),
-			Labels:      []string{},
-			CreatedAt:   time.Now(),
-		}
-
-		if task.ColumnID == "" {
-			task.ColumnID = b.defaultColumn
-		}
-
-		// Отправляем задачу в Yougile
-		if err := b.yougileClient.CreateTask(task); err != nil {
+		task, queued, uploaded, err := b.createPhotoTask(user, state, c.Sender().ID, caption, photo.FileID, fileData)
+		if err != nil {
 			log.Printf("Ошибка создания задачи в Yougile: %v", err)
-			return c.Send("Произошла ошибка при создании задачи. Пожалуйста, попробуйте позже.")
+			b.ReportAPIError(err)
+			return c.Send(yougileErrorText(err, "Произошла ошибка при создании задачи. Пожалуйста, попробуйте позже."))
 		}
 
-		// Подготовим идентификатор файла и FileID для комментария.
-		//
-		// Примечание: в текущей версии мы сохраняем фотографию локально и добавляем текстовую
-		// ссылку в комментарий задачи вместо прямой загрузки/прикрепления к Yougile.
-		// На практике это сделано потому, что интеграция загрузки вложений в API Yougile
-		// пока не реализована/не надёжна для этого инстанса — поэтому сохраняем копию
-		// на диск (`data/uploads`) и оставляем Telegram FileID в комментарии на случай,
-		// если позже захотим подтянуть файл из Telegram или реализовать uploadAttachment.
-		//
-		// Чтобы вернуть полноценную загрузку вложений, нужно реализовать в `internal/api`
-		// метод UploadAttachment(filePath string, taskID string) error и вызвать его здесь.
-		attID := fmt.Sprintf("img_%d.jpg", time.Now().Unix())
-		fileID := photo.FileID
-
-		// Save file locally and add a textual comment to the task with reference to the saved file
-		uploadsDir := "data/uploads"
-		if err := os.MkdirAll(uploadsDir, 0755); err != nil {
-			log.Printf("Ошибка создания каталога для загрузок: %v", err)
-		}
-		savedPath := filepath.Join(uploadsDir, attID)
-		if werr := os.WriteFile(savedPath, fileData, 0644); werr != nil {
-			log.Printf("Ошибка сохранения файла локально: %v", werr)
-		} else {
-			// create a comment referencing the saved file and telegram file id
-			commentText := fmt.Sprintf("%s\n[Фотография сохранена локально: %s]\n[Telegram FileID: %s]", caption, savedPath, fileID)
-			comment := &models.Comment{
-				TaskID:    task.ID,
-				AuthorID:  strconv.FormatInt(c.Sender().ID, 10),
-				Text:      commentText,
-				CreatedAt: time.Now(),
-				UpdatedAt: time.Now(),
-			}
-			taskIDStr := task.ExternalID
-			if taskIDStr == "" {
-				taskIDStr = strconv.FormatInt(task.ID, 10)
-			}
-			if cerr := b.yougileClient.AddComment(taskIDStr, comment); cerr != nil {
-				log.Printf("Ошибка добавления комментария к задаче (локальная ссылка): %v", cerr)
-			} else {
-				// persist locally
-				b.storage.AddTask(task)
-				task.Comments = append(task.Comments, *comment)
-				b.storage.UpdateTask(task)
-				// ensure we also update any cached tasks list
-				tasks := b.storage.GetTasks()
-				for _, t := range tasks {
-					if t.ID == task.ID {
-						t.Comments = append(t.Comments, *comment)
-						b.storage.UpdateTask(t)
-						break
-					}
-				}
-				if sErr := b.storage.SaveData(); sErr != nil {
-					log.Printf("Ошибка сохранения задачи с комментарием: %v", sErr)
-				}
+		if queued != 0 {
+			delete(b.taskCreationStates, c.Sender().ID)
+			if err := c.Send(msgTaskQueued, b.menuForContext(c)); err != nil {
+				log.Printf("Ошибка отправки пользователю подтверждения отправки задачи: %v", err)
 			}
+			return nil
 		}
 
 		// Сохраняем задачу локально
@@ -158,7 +88,7 @@ func (b *Bot) handlePhoto(c telebot.Context) error {
 		}
 
 		// Запускаем проверку создания задачи
-		b.startTaskVerification(*task, *user, caption, true, fileData)
+		b.startTaskVerification(*task, *user, caption, uploaded, fileData)
 
 		delete(b.taskCreationStates, c.Sender().ID)
 		if err := c.Send("Задача с фотографией отправлена на создание. Вы получите уведомление после её успешного создания.", b.menuForContext(c)); err != nil {
@@ -205,38 +135,33 @@ func (b *Bot) handlePhoto(c telebot.Context) error {
 			return c.Send("Ошибка при обработке фотографии.")
 		}
 
-		// Подготовим идентификатор файла и FileID для комментария (не используем загрузку/attach в Yougile сейчас)
-		attID := fmt.Sprintf("img_%d.jpg", time.Now().Unix())
-		fileID := photo.FileID
-
-		// Save file locally and add comment referencing it
-		uploadsDir := "data/uploads"
-		if err := os.MkdirAll(uploadsDir, 0755); err != nil {
-			log.Printf("Ошибка создания каталога для загрузок: %v", err)
-		}
-		savedPath := filepath.Join(uploadsDir, attID)
-		if werr := os.WriteFile(savedPath, fileData, 0644); werr != nil {
-			log.Printf("Ошибка сохранения файла локально: %v", werr)
-			if err2 := c.Send("Ошибка при сохранении фотографии."); err2 != nil {
-				log.Printf("Ошибка отправки сообщения об ошибке пользователю: %v", err2)
-			}
-			return nil
-		}
-
-		// Создаем комментарий с вложением (текстовая ссылка на локальную копию и Telegram FileID)
 		caption := c.Message().Caption
 		if caption == "" {
 			caption = "[Фотография]"
 		}
 
-		commentText := fmt.Sprintf("%s\n[Фотография сохранена локально: %s]\n[Telegram FileID: %s]", caption, savedPath, fileID)
+		// Загружаем фотографию в Yougile и добавляем ссылку на неё в комментарий
+		attachment := &models.Attachment{
+			ID:        fmt.Sprintf("img_%d.jpg", time.Now().Unix()),
+			Type:      models.AttachmentTypeImage,
+			CreatedAt: time.Now(),
+			FileID:    photo.FileID,
+		}
+		fileURL, err := b.yougileClient.UploadFile(b.ctx, attachment.ID, fileData)
+		if err != nil {
+			log.Printf("Ошибка загрузки фотографии в Yougile: %v", err)
+			b.ReportAPIError(err)
+			return c.Send(yougileErrorText(err, "Не удалось загрузить фотографию в Yougile. Пожалуйста, попробуйте позже."))
+		}
+		attachment.URL = fileURL
 
 		comment := &models.Comment{
-			TaskID:    taskID,
-			AuthorID:  strconv.FormatInt(c.Sender().ID, 10),
-			Text:      commentText,
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+			TaskID:      taskID,
+			AuthorID:    strconv.FormatInt(c.Sender().ID, 10),
+			Text:        caption + "\n" + api.AttachmentText(attachment),
+			Attachments: []models.Attachment{*attachment},
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
 		}
 
 		// Добавляем комментарий к задаче
@@ -249,8 +174,16 @@ func (b *Bot) handlePhoto(c telebot.Context) error {
 				break
 			}
 		}
-		if err := b.yougileClient.AddComment(taskIDStr, comment); err != nil {
+		if err := b.yougileClient.AddComment(b.ctx, taskIDStr, comment); err != nil {
 			log.Printf("Ошибка добавления комментария: %v", err)
+			if api.IsUnavailable(err) {
+				b.queueComment(taskIDStr, 0, comment, c.Sender().ID)
+				delete(b.commentStates, c.Sender().ID)
+				if err2 := c.Send(msgCommentQueued, b.menuForContext(c)); err2 != nil {
+					log.Printf("Ошибка отправки сообщения пользователю: %v", err2)
+				}
+				return nil
+			}
 			if err2 := c.Send("Ошибка при добавлении комментария с фотографией."); err2 != nil {
 				log.Printf("Ошибка отправки сообщения об ошибке пользователю: %v", err2)
 			}
@@ -270,7 +203,7 @@ func (b *Bot) handlePhoto(c telebot.Context) error {
 		}
 
 		delete(b.commentStates, c.Sender().ID)
-		if err := c.Send("Фотография успешно обработана и ссылка добавлена в комментарий задачи.", b.menuForContext(c)); err != nil {
+		if err := c.Send("Фотография загружена в Yougile и добавлена в комментарий задачи.", b.menuForContext(c)); err != nil {
 			log.Printf("Ошибка отправки подтверждения пользователю: %v", err)
 		}
 		return nil
@@ -281,3 +214,80 @@ func (b *Bot) handlePhoto(c telebot.Context) error {
 	}
 	return nil
 }
+
+// createPhotoTask создаёт задачу с фотографией или откладывает её, если Yougile
+// недоступен (queued != 0). Если фотографию не удалось загрузить (uploaded == false),
+// её загрузка ставится в очередь отложенных операций и выполняется после создания задачи.
+func (b *Bot) createPhotoTask(user *models.User, state *models.TaskCreationState, requester int64, caption, fileID string, fileData []byte) (task *models.Task, queued int64, uploaded bool, err error) {
+	boardID, columnID := b.taskDestination(state)
+	stickers, inStickers := b.taskStickers(user, state, boardID)
+	desc := caption
+	if user != nil {
+		desc = b.formatTaskDescription(user, caption, inStickers)
+	}
+	task = &models.Task{
+		Title:       b.formatTaskTitle(user, state.Title),
+		Description: desc,
+		Status:      models.TaskStatusNew,
+		BoardID:     boardID,
+		ColumnID:    columnID,
+		Priority:    1,
+		RequesterID: requester,
+		Assigned:    b.defaultAssigned(),
+		Stickers:    stickers,
+		Labels:      []string{},
+		CreatedAt:   time.Now(),
+	}
+
+	// Фотография загружается в Yougile до создания задачи, чтобы ссылка на неё
+	// попала в описание. Если загрузить не удалось, фото будет прикреплено
+	// к задаче из очереди отложенных операций после её создания.
+	attachment := &models.Attachment{
+		ID:        fmt.Sprintf("img_%d.jpg", time.Now().Unix()),
+		Type:      models.AttachmentTypeImage,
+		CreatedAt: time.Now(),
+		FileID:    fileID,
+	}
+	uploadErr := b.attachPhotoToTask(task, attachment, fileData)
+
+	// Отправляем задачу в Yougile (или откладываем, если он недоступен). Фотография
+	// сохраняется с задачей, только если уже загружена: иначе её загрузит queueAttachment
+	var image []byte
+	if uploadErr == nil {
+		image = fileData
+	}
+	queued, err = b.createTaskOrQueue(task, requester, caption, image)
+	if err != nil {
+		return task, 0, false, err
+	}
+
+	if uploadErr != nil {
+		if qerr := b.queueAttachment(outboxTaskIDIfCreated(task, queued), queued, attachment, fileData, requester); qerr != nil {
+			log.Printf("Ошибка постановки фотографии в очередь: %v", qerr)
+		}
+	}
+	return task, queued, uploadErr == nil, nil
+}
+
+// attachPhotoToTask загружает фотографию в Yougile и добавляет ссылку на неё
+// в описание и список вложений ещё не созданной задачи.
+func (b *Bot) attachPhotoToTask(task *models.Task, attachment *models.Attachment, data []byte) error {
+	fileURL, err := b.yougileClient.UploadFile(b.ctx, attachment.ID, data)
+	if err != nil {
+		log.Printf("Ошибка загрузки фотографии в Yougile: %v", err)
+		return err
+	}
+	attachment.URL = fileURL
+	task.Description += "\n\n" + api.AttachmentText(attachment)
+	task.Attachments = append(task.Attachments, fileURL)
+	return nil
+}
+
+// outboxTaskIDIfCreated возвращает ID созданной задачи или пустую строку,
+// если задача отложена (queued != 0) и ID станет известен позже.
+func outboxTaskIDIfCreated(task *models.Task, queued int64) string {
+	if queued != 0 {
+		return ""
+	}
+	return outboxTaskID(task)
+}
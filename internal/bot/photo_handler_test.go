@@ -0,0 +1,102 @@
+// Package bot содержит тесты создания задач с фотографиями.
+package bot
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"yougile_bot4/internal/models"
+)
+
+func TestPhotoTaskCreatedWhileYougileIsDown(t *testing.T) {
+	yougile := &fakeYougile{down: true}
+	b := newTestBot(t, yougile)
+	const requester = 42
+	user := &models.User{TelegramID: requester, FirstName: "Анна", Approved: true}
+	state := &models.TaskCreationState{Title: "Сломан принтер"}
+
+	_, queued, uploaded, err := b.createPhotoTask(user, state, requester, "Не печатает", "file-1", []byte("jpeg"))
+	if err != nil || queued == 0 || uploaded {
+		t.Fatalf("expected task and photo to be queued, got queued=%d uploaded=%v err=%v", queued, uploaded, err)
+	}
+	if n := b.storage.OutboxLen(); n != 2 {
+		t.Fatalf("expected task creation and photo upload in the outbox, got %d", n)
+	}
+
+	yougile.setDown(false)
+	b.replayOutbox()
+	if n := b.storage.OutboxLen(); n != 0 {
+		t.Fatalf("expected outbox to be drained, left %+v", b.storage.GetOutbox())
+	}
+	// фотография загружается в Yougile и публикуется в чате уже созданной задачи
+	calls := yougile.calls()
+	created := indexOf(calls, "POST /api-v2/tasks")
+	uploadedAt := lastIndexOf(calls, "POST /api-v2/upload-file")
+	posted := indexOf(calls, "POST /api-v2/chats/t-new/messages")
+	if created < 0 || uploadedAt < created || posted < uploadedAt {
+		t.Fatalf("expected task creation, photo upload and chat message in order, got:\n%s", strings.Join(calls, "\n"))
+	}
+	for _, text := range queuedTexts(b, requester) {
+		if strings.Contains(text, "Не удалось") {
+			t.Errorf("requester must not be told that the photo was lost: %q", text)
+		}
+	}
+}
+
+func TestQueuedPhotoTaskKeepsUploadedImage(t *testing.T) {
+	yougile := &fakeYougile{}
+	tasksDown := true
+	b := newTestBot(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tasksDown && r.URL.Path == "/api-v2/tasks" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		yougile.ServeHTTP(w, r)
+	}))
+	const requester = 42
+	user := &models.User{TelegramID: requester, FirstName: "Анна", Approved: true}
+	state := &models.TaskCreationState{Title: "Сломан принтер"}
+
+	_, queued, uploaded, err := b.createPhotoTask(user, state, requester, "Не печатает", "file-1", []byte("jpeg"))
+	if err != nil || queued == 0 || !uploaded {
+		t.Fatalf("expected uploaded photo and queued task, got queued=%d uploaded=%v err=%v", queued, uploaded, err)
+	}
+	items := b.storage.GetOutbox()
+	if len(items) != 1 || !items[0].HasImage {
+		t.Fatalf("expected a single task creation with the photo, got %+v", items)
+	}
+	// проверке задачи после создания нужна сама фотография, а не только отметка о ней
+	data, err := os.ReadFile(items[0].FilePath)
+	if err != nil || string(data) != "jpeg" {
+		t.Fatalf("expected the photo to be saved with the queued task, got %q, %v", data, err)
+	}
+
+	tasksDown = false
+	b.replayOutbox()
+	if n := b.storage.OutboxLen(); n != 0 {
+		t.Fatalf("expected outbox to be drained, left %+v", b.storage.GetOutbox())
+	}
+	if _, err := os.Stat(items[0].FilePath); !os.IsNotExist(err) {
+		t.Errorf("expected the saved photo to be removed after the task is created, got %v", err)
+	}
+}
+
+func indexOf(calls []string, call string) int {
+	for i, c := range calls {
+		if c == call {
+			return i
+		}
+	}
+	return -1
+}
+
+func lastIndexOf(calls []string, call string) int {
+	for i := len(calls) - 1; i >= 0; i-- {
+		if calls[i] == call {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,252 @@
+// Package bot содержит поиск задач Yougile администраторами: команда /search
+// и постраничный список найденных задач с кнопками.
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"yougile_bot4/internal/api"
+	"yougile_bot4/internal/models"
+
+	"gopkg.in/telebot.v3"
+)
+
+const (
+	// searchUnique — префикс данных кнопок списка найденных задач.
+	searchUnique = "ygs"
+	// searchPageSize — число задач на одной странице результатов.
+	searchPageSize = 8
+	// searchLimit — максимальное число задач в результатах поиска.
+	searchLimit = 80
+)
+
+// searchUsage — подсказка по команде /search.
+const searchUsage = "Использование: /search <текст> [#open|#done] [#7d] [#col]\n" +
+	"#open — только в работе, #done — только выполненные,\n" +
+	"#7d — созданные за последние 7 дней (любое число дней),\n" +
+	"#col — только в колонке, куда бот создаёт задачи."
+
+// TaskSearch хранит результаты последнего поиска администратора для листания.
+type TaskSearch struct {
+	Query     string
+	Tasks     []models.Task
+	StartTime time.Time
+}
+
+// parseSearchQuery разбирает аргументы /search в фильтр и текст запроса.
+// columnID — колонка, подставляемая по #col.
+func parseSearchQuery(args, columnID string, now time.Time) (api.TaskFilter, error) {
+	var filter api.TaskFilter
+	var words []string
+	for _, word := range strings.Fields(args) {
+		tag, ok := strings.CutPrefix(strings.ToLower(word), "#")
+		if !ok {
+			words = append(words, word)
+			continue
+		}
+		switch {
+		case tag == "open":
+			done := false
+			filter.Completed = &done
+		case tag == "done":
+			done := true
+			filter.Completed = &done
+		case tag == "col":
+			if columnID == "" {
+				return filter, fmt.Errorf("колонка не выбрана, выберите её командой /projects")
+			}
+			filter.ColumnID = columnID
+		case strings.HasSuffix(tag, "d"):
+			days, err := strconv.Atoi(strings.TrimSuffix(tag, "d"))
+			if err != nil || days <= 0 {
+				return filter, fmt.Errorf("неверный период %s", word)
+			}
+			filter.CreatedAfter = now.AddDate(0, 0, -days)
+		default:
+			return filter, fmt.Errorf("неизвестный фильтр %s", word)
+		}
+	}
+	filter.Title = strings.Join(words, " ")
+	if filter.Title == "" && filter.Completed == nil && filter.CreatedAfter.IsZero() && filter.ColumnID == "" {
+		return filter, fmt.Errorf("не задан текст поиска")
+	}
+	return filter, nil
+}
+
+// handleSearch ищет задачи текущей доски по тексту и фильтрам.
+func (b *Bot) handleSearch(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	args := strings.TrimSpace(strings.TrimPrefix(c.Text(), "/search"))
+	if args == "" {
+		return c.Send(searchUsage)
+	}
+	boardID, columnID := b.target()
+	filter, err := parseSearchQuery(args, columnID, time.Now())
+	if err != nil {
+		return c.Send(fmt.Sprintf("%s.\n\n%s", capitalize(err.Error()), searchUsage))
+	}
+	if filter.ColumnID == "" {
+		filter.BoardID = boardID
+	}
+
+	tasks, err := b.yougileClient.SearchTasks(b.ctx, filter, searchLimit)
+	if err != nil {
+		log.Printf("search: ошибка поиска задач %q: %v", args, err)
+		b.ReportAPIError(err)
+		return c.Send(yougileErrorText(err, "Не удалось выполнить поиск в Yougile. Пожалуйста, попробуйте позже."))
+	}
+	if len(tasks) == 0 {
+		return c.Send(fmt.Sprintf("🔎 По запросу «%s» задачи не найдены.", args))
+	}
+
+	search := &TaskSearch{Query: args, Tasks: tasks, StartTime: time.Now()}
+	b.pickMu.Lock()
+	b.searches[c.Sender().ID] = search
+	b.pickMu.Unlock()
+	text, menu := searchPage(search, 0)
+	return c.Send(text, menu)
+}
+
+// handleSearchCallback листает результаты поиска ("ygs|page|N") и открывает
+// задачу с кнопками действий ("ygs|open|I").
+func (b *Bot) handleSearchCallback(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Respond(&telebot.CallbackResponse{Text: "Действие доступно только администраторам.", ShowAlert: true})
+	}
+	action, arg, _ := strings.Cut(callbackArg(c), "|")
+	b.pickMu.Lock()
+	search := b.searches[c.Sender().ID]
+	b.pickMu.Unlock()
+	n, err := strconv.Atoi(arg)
+	if search == nil || err != nil || n < 0 {
+		return c.Respond(&telebot.CallbackResponse{Text: "Результаты поиска устарели, повторите /search.", ShowAlert: true})
+	}
+	switch action {
+	case "page":
+		text, menu := searchPage(search, n)
+		_ = c.Respond()
+		return c.Edit(text, menu)
+	case "open":
+		if n >= len(search.Tasks) {
+			return c.Respond(&telebot.CallbackResponse{Text: "Результаты поиска устарели, повторите /search.", ShowAlert: true})
+		}
+		task := search.Tasks[n]
+		_ = c.Respond()
+		id := outboxTaskID(&task)
+		if id == "" || id == "0" {
+			return c.Send(searchTaskCard(task))
+		}
+		return c.Send(searchTaskCard(task), taskActionsMarkup(id, task.Done || task.Archived))
+	}
+	return c.Respond(&telebot.CallbackResponse{Text: "Неизвестное действие."})
+}
+
+// searchPage формирует текст и кнопки страницы page результатов поиска.
+func searchPage(search *TaskSearch, page int) (string, *telebot.ReplyMarkup) {
+	pages := (len(search.Tasks) + searchPageSize - 1) / searchPageSize
+	if page >= pages {
+		page = pages - 1
+	}
+	start := page * searchPageSize
+	end := start + searchPageSize
+	if end > len(search.Tasks) {
+		end = len(search.Tasks)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "🔎 «%s»: найдено задач — %d", search.Query, len(search.Tasks))
+	if len(search.Tasks) >= searchLimit {
+		sb.WriteString(" (показаны первые, уточните запрос)")
+	}
+	if pages > 1 {
+		fmt.Fprintf(&sb, "\nСтраница %d из %d", page+1, pages)
+	}
+	sb.WriteString("\n")
+
+	menu := &telebot.ReplyMarkup{}
+	var rows []telebot.Row
+	var buttons []telebot.Btn
+	for i := start; i < end; i++ {
+		task := search.Tasks[i]
+		fmt.Fprintf(&sb, "\n%d. %s %s · %s", i+1, taskStatusIcon(task), taskKeyText(task), truncateRunes(task.Title, 60))
+		if !task.CreatedAt.IsZero() {
+			fmt.Fprintf(&sb, " (%s)", task.CreatedAt.Format("02.01.2006"))
+		}
+		buttons = append(buttons, menu.Data(strconv.Itoa(i+1), searchUnique, "open|"+strconv.Itoa(i)))
+	}
+	for len(buttons) > 0 {
+		n := 4
+		if len(buttons) < n {
+			n = len(buttons)
+		}
+		rows = append(rows, menu.Row(buttons[:n]...))
+		buttons = buttons[n:]
+	}
+	var nav []telebot.Btn
+	if page > 0 {
+		nav = append(nav, menu.Data("⬅️ Назад", searchUnique, "page|"+strconv.Itoa(page-1)))
+	}
+	if page < pages-1 {
+		nav = append(nav, menu.Data("Вперёд ➡️", searchUnique, "page|"+strconv.Itoa(page+1)))
+	}
+	if len(nav) > 0 {
+		rows = append(rows, menu.Row(nav...))
+	}
+	menu.Inline(rows...)
+	return sb.String(), menu
+}
+
+// searchTaskCard описывает найденную задачу для отдельного сообщения.
+func searchTaskCard(task models.Task) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s\n📝 %s", taskStatusIcon(task), taskKeyText(task), task.Title)
+	if desc := strings.TrimSpace(task.Description); desc != "" {
+		fmt.Fprintf(&sb, "\n\n%s", truncateRunes(desc, 500))
+	}
+	if !task.CreatedAt.IsZero() {
+		fmt.Fprintf(&sb, "\n\n🕒 Создана: %s", task.CreatedAt.Format("02.01.2006 15:04"))
+	}
+	if !task.DueDate.IsZero() {
+		fmt.Fprintf(&sb, "\n📅 Срок: %s", task.DueDate.Format("02.01.2006"))
+	}
+	return sb.String()
+}
+
+// taskStatusIcon возвращает значок статуса задачи.
+func taskStatusIcon(task models.Task) string {
+	switch {
+	case task.Archived:
+		return "🗄"
+	case task.Done:
+		return "✅"
+	}
+	return "🟡"
+}
+
+// taskKeyText возвращает короткий ключ задачи (ITS-12) или ID, если ключа нет.
+func taskKeyText(task models.Task) string {
+	if task.Key != "" {
+		return task.Key
+	}
+	if task.ID != 0 {
+		return "#" + strconv.FormatInt(task.ID, 10)
+	}
+	return "без ключа"
+}
+
+// capitalize делает заглавной первую букву сообщения об ошибке.
+func capitalize(s string) string {
+	r := []rune(s)
+	if len(r) == 0 {
+		return s
+	}
+	return strings.ToUpper(string(r[0])) + string(r[1:])
+}
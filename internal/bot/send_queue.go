@@ -0,0 +1,146 @@
+// Package bot содержит отправку сообщений бота через очередь с учётом ограничений Telegram.
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"yougile_bot4/internal/models"
+	"yougile_bot4/internal/sendq"
+
+	"gopkg.in/telebot.v3"
+)
+
+// defaultRetryAfter — пауза после 429, если Telegram не указал retry_after.
+const defaultRetryAfter = 5 * time.Second
+
+// telegramTransport отправляет сообщения очереди через Telegram и переводит
+// ошибки telebot в ошибки пакета sendq.
+type telegramTransport struct {
+	b *Bot
+}
+
+// Send отправляет сообщение и возвращает его ID в Telegram.
+func (t *telegramTransport) Send(m models.OutgoingMessage) (int, error) {
+	var opts []interface{}
+	if len(m.Markup) > 0 {
+		markup := &telebot.ReplyMarkup{}
+		if err := json.Unmarshal(m.Markup, markup); err != nil {
+			return 0, fmt.Errorf("%w: клавиатура повреждена: %v", sendq.ErrRejected, err)
+		}
+		opts = append(opts, markup)
+	}
+	sent, err := t.b.bot.Send(&telebot.Chat{ID: m.ChatID}, m.Text, opts...)
+	if err != nil {
+		return 0, sendError(err)
+	}
+	return sent.ID, nil
+}
+
+// Delivered запоминает пересланный комментарий, чтобы ответ на него ушёл в чат задачи.
+func (t *telegramTransport) Delivered(m models.OutgoingMessage, messageID int) {
+	if m.Relay == nil {
+		return
+	}
+	reply := *m.Relay
+	reply.SentAt = time.Now()
+	t.b.storage.AddRelayReply(m.ChatID, messageID, reply)
+}
+
+// ChatGone исключает недоступный боту чат из рассылок и правил маршрутизации.
+func (t *telegramTransport) ChatGone(chatID int64) {
+	if !t.b.storage.RemoveChat(chatID) {
+		return
+	}
+	if err := t.b.storage.SaveData(); err != nil {
+		log.Printf("Ошибка сохранения после удаления чата %d: %v", chatID, err)
+	}
+	log.Printf("Чат %d недоступен боту и удалён из рассылок", chatID)
+}
+
+// sendError переводит ошибку telebot в ошибку очереди отправки.
+func sendError(err error) error {
+	var flood telebot.FloodError
+	if errors.As(err, &flood) {
+		after := time.Duration(flood.RetryAfter) * time.Second
+		if after <= 0 {
+			after = defaultRetryAfter
+		}
+		return &sendq.RetryAfterError{After: after, Err: err}
+	}
+	var migrated telebot.GroupError
+	if errors.As(err, &migrated) {
+		return fmt.Errorf("%w: группа стала супергруппой %d", sendq.ErrRejected, migrated.MigratedTo)
+	}
+	switch telegramErrorCode(err) {
+	case http.StatusTooManyRequests:
+		return &sendq.RetryAfterError{After: defaultRetryAfter, Err: err}
+	case http.StatusForbidden:
+		return fmt.Errorf("%w: %v", sendq.ErrForbidden, err)
+	case http.StatusBadRequest:
+		return fmt.Errorf("%w: %v", sendq.ErrRejected, err)
+	}
+	return err
+}
+
+// telegramErrorSuffix — код ответа в конце ошибки Telegram, которую telebot не
+// распознал: "telegram: <описание> (<код>)".
+var telegramErrorSuffix = regexp.MustCompile(`^telegram: .* \((\d{3})\)$`)
+
+// telegramErrorCode возвращает HTTP-код ответа Telegram для ошибки отправки
+// (0 — не ответ Telegram, например сетевая ошибка). Известные telebot ошибки
+// приходят как *telebot.Error, остальные — только текстом с кодом в конце.
+func telegramErrorCode(err error) int {
+	var tgErr *telebot.Error
+	if errors.As(err, &tgErr) {
+		return tgErr.Code
+	}
+	if m := telegramErrorSuffix.FindStringSubmatch(err.Error()); m != nil {
+		code, _ := strconv.Atoi(m[1])
+		return code
+	}
+	return 0
+}
+
+// enqueue ставит сообщение в очередь отправки. Из opts учитывается клавиатура
+// (*telebot.ReplyMarkup); остальные параметры telebot не поддерживаются.
+func (b *Bot) enqueue(chatID int64, text string, opts ...interface{}) {
+	b.queueMessage(models.OutgoingMessage{ChatID: chatID, Text: text, Markup: markupOf(opts)})
+}
+
+// queueMessage ставит подготовленное сообщение в очередь отправки.
+func (b *Bot) queueMessage(m models.OutgoingMessage) {
+	b.queue.Enqueue(m)
+}
+
+// markupOf возвращает клавиатуру из параметров отправки в виде JSON.
+func markupOf(opts []interface{}) json.RawMessage {
+	for _, opt := range opts {
+		markup, ok := opt.(*telebot.ReplyMarkup)
+		if !ok || markup == nil {
+			continue
+		}
+		data, err := json.Marshal(markup)
+		if err != nil {
+			log.Printf("Ошибка сохранения клавиатуры сообщения: %v", err)
+			return nil
+		}
+		return data
+	}
+	return nil
+}
+
+// runSendQueue отправляет сообщения из очереди до отмены ctx.
+func (b *Bot) runSendQueue(ctx context.Context) {
+	if n := b.storage.SendQueueLen(); n > 0 {
+		log.Printf("В очереди отправки %d сообщений с прошлого запуска", n)
+	}
+	b.queue.Run(ctx)
+}
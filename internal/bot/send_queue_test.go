@@ -0,0 +1,69 @@
+// Package bot содержит тесты перевода ошибок Telegram в ошибки очереди отправки.
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"yougile_bot4/internal/models"
+	"yougile_bot4/internal/sendq"
+
+	"gopkg.in/telebot.v3"
+)
+
+// TestTelegramTransportClassifiesErrors проверяет, что ответы Telegram
+// классифицируются по коду, в том числе с описаниями, неизвестными telebot.
+func TestTelegramTransportClassifiesErrors(t *testing.T) {
+	var reply string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, reply)
+	}))
+	defer ts.Close()
+	tb, err := telebot.NewBot(telebot.Settings{URL: ts.URL, Token: "token", Offline: true})
+	if err != nil {
+		t.Fatalf("NewBot failed: %v", err)
+	}
+	transport := &telegramTransport{b: &Bot{bot: tb}}
+
+	for _, tc := range []struct {
+		reply string
+		want  error
+	}{
+		{`{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`, sendq.ErrForbidden},
+		{`{"ok":false,"error_code":403,"description":"Forbidden: bot is not a member of the channel chat"}`, sendq.ErrForbidden},
+		{`{"ok":false,"error_code":400,"description":"Bad Request: message is too long"}`, sendq.ErrRejected},
+		{`{"ok":false,"error_code":400,"description":"Bad Request: can't parse entities: unsupported start tag"}`, sendq.ErrRejected},
+		{`{"ok":false,"error_code":500,"description":"Internal Server Error"}`, nil},
+	} {
+		reply = tc.reply
+		_, err := transport.Send(models.OutgoingMessage{ChatID: 1, Text: "текст"})
+		if err == nil {
+			t.Fatalf("expected error for %s", tc.reply)
+		}
+		if tc.want == nil {
+			if errors.Is(err, sendq.ErrForbidden) || errors.Is(err, sendq.ErrRejected) {
+				t.Errorf("server error must stay temporary, got %v", err)
+			}
+			continue
+		}
+		if !errors.Is(err, tc.want) {
+			t.Errorf("reply %s: expected %v, got %v", tc.reply, tc.want, err)
+		}
+	}
+
+	reply = `{"ok":false,"error_code":429,"description":"Too Many Requests: retry later"}`
+	_, err = transport.Send(models.OutgoingMessage{ChatID: 1, Text: "текст"})
+	var retry *sendq.RetryAfterError
+	if !errors.As(err, &retry) || retry.After != defaultRetryAfter {
+		t.Fatalf("expected retry after %v, got %v", defaultRetryAfter, err)
+	}
+	reply = `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 7","parameters":{"retry_after":7}}`
+	_, err = transport.Send(models.OutgoingMessage{ChatID: 1, Text: "текст"})
+	if !errors.As(err, &retry) || retry.After != 7*time.Second {
+		t.Fatalf("expected retry after 7s, got %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+// Package bot содержит заполнение стикеров задач Yougile из данных пользователя
+// и ответов конструктора.
+package bot
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"yougile_bot4/internal/models"
+
+	"gopkg.in/telebot.v3"
+)
+
+// stickerSourceValue возвращает значение источника source для пользователя и
+// состояния конструктора (state может быть nil).
+func (b *Bot) stickerSourceValue(source string, user *models.User, state *models.TaskCreationState) string {
+	switch source {
+	case models.StickerSourceBuilding:
+		if user == nil {
+			return ""
+		}
+		if user.BuildingAddress != "" {
+			return user.BuildingAddress
+		}
+		return user.Address
+	case models.StickerSourceRoom:
+		if user == nil {
+			return ""
+		}
+		return user.RoomNumber
+	case models.StickerSourcePosition:
+		if user == nil {
+			return ""
+		}
+		return user.Position
+	}
+	if !strings.HasPrefix(source, models.StickerSourceStepPrefix) || state == nil {
+		return ""
+	}
+	stepKey := strings.TrimPrefix(source, models.StickerSourceStepPrefix)
+	answer := state.Answers[stepKey]
+	if answer == "" {
+		return ""
+	}
+	// для шагов с вариантами в стикер пишется текст варианта, а не его ID
+	if step, ok := b.storage.GetTaskTemplate(stepKey); ok {
+		for _, option := range step.Options {
+			if option.ID == answer {
+				return option.Text
+			}
+		}
+	}
+	return answer
+}
+
+// taskStickers формирует значения стикеров новой задачи на доске boardID по настроенному соответствию.
+// Второе значение — источники (building, room, ...), записанные в стикеры: их не нужно
+// дублировать в описании задачи. Ошибки Yougile не мешают созданию задачи: задача
+// создаётся без стикеров.
+func (b *Bot) taskStickers(user *models.User, state *models.TaskCreationState, boardID string) (map[string]string, map[string]bool) {
+	mapping := b.storage.GetStickerMap()
+	// соответствие стикеров настраивается для основной доски
+	if mainBoard, _ := b.target(); len(mapping) == 0 || boardID != mainBoard {
+		return nil, nil
+	}
+	values := make(map[string]string, len(mapping))
+	for source, stickerID := range mapping {
+		if v := b.stickerSourceValue(source, user, state); v != "" {
+			values[stickerID] = v
+		}
+	}
+	stickers, err := b.yougileClient.ResolveStickers(b.ctx, boardID, values, b.storage.GetStickerCreateStates())
+	if err != nil {
+		log.Printf("Ошибка заполнения стикеров задачи: %v", err)
+		return nil, nil
+	}
+	written := make(map[string]bool, len(stickers))
+	for source, stickerID := range mapping {
+		if _, ok := stickers[stickerID]; ok {
+			written[source] = true
+		}
+	}
+	return stickers, written
+}
+
+// stickerStatesArg — аргумент /sticker, включающий создание недостающих состояний.
+const stickerStatesArg = "states"
+
+// validStickerSource проверяет имя источника значения стикера.
+func validStickerSource(source string) bool {
+	switch source {
+	case models.StickerSourceBuilding, models.StickerSourceRoom, models.StickerSourcePosition:
+		return true
+	}
+	return strings.HasPrefix(source, models.StickerSourceStepPrefix) && len(source) > len(models.StickerSourceStepPrefix)
+}
+
+// handleStickers показывает стикеры текущей доски и настроенное соответствие.
+func (b *Bot) handleStickers(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	boardID, _ := b.target()
+	if boardID == "" {
+		return c.Send("Доска не выбрана. Выберите колонку командой /projects")
+	}
+	stickers, err := b.yougileClient.ListStickers(b.ctx, boardID)
+	if err != nil {
+		log.Printf("Ошибка получения стикеров доски %s: %v", boardID, err)
+		b.ReportAPIError(err)
+		return c.Send(yougileErrorText(err, "Не удалось получить стикеры доски Yougile."))
+	}
+	names := make(map[string]string, len(stickers))
+
+	var sb strings.Builder
+	sb.WriteString("Стикеры доски:\n")
+	if len(stickers) == 0 {
+		sb.WriteString("(нет текстовых стикеров)\n")
+	}
+	for _, s := range stickers {
+		names[s.ID] = s.Name
+		if len(s.States) == 0 {
+			sb.WriteString(fmt.Sprintf("• %s — свободное поле\n", s.Name))
+		} else {
+			sb.WriteString(fmt.Sprintf("• %s — состояний: %d\n", s.Name, len(s.States)))
+		}
+	}
+
+	mapping := b.storage.GetStickerMap()
+	sources := make([]string, 0, len(mapping))
+	for source := range mapping {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	sb.WriteString("\nЗаполнение стикеров:\n")
+	if len(sources) == 0 {
+		sb.WriteString("(не настроено)\n")
+	}
+	for _, source := range sources {
+		name := names[mapping[source]]
+		if name == "" {
+			name = mapping[source] + " (нет на доске)"
+		}
+		sb.WriteString(fmt.Sprintf("• %s → %s\n", source, name))
+	}
+	if b.storage.GetStickerCreateStates() {
+		sb.WriteString("\nНедостающие состояния стикеров создаются автоматически.\n")
+	} else {
+		sb.WriteString("\nНедостающие состояния стикеров не создаются: такие значения остаются в описании задачи.\n")
+	}
+	sb.WriteString("\nНастроить: /sticker <building|room|position|step:<шаг>> <название стикера|off>\n")
+	sb.WriteString("Создание состояний: /sticker states <on|off>")
+	return sendChunked(c, sb.String())
+}
+
+// handleSetSticker связывает источник значения со стикером текущей доски.
+func (b *Bot) handleSetSticker(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	args := strings.TrimSpace(strings.TrimPrefix(c.Text(), "/sticker"))
+	source, name, _ := strings.Cut(args, " ")
+	name = strings.TrimSpace(name)
+	if source == stickerStatesArg && (name == "on" || name == "off") {
+		b.storage.SetStickerCreateStates(name == "on")
+		if err := b.storage.SaveData(); err != nil {
+			log.Printf("Ошибка сохранения настроек: %v", err)
+		}
+		if name == "on" {
+			return c.Send("✅ Недостающие состояния стикеров будут создаваться на доске автоматически.")
+		}
+		return c.Send("Недостающие состояния стикеров больше не создаются.")
+	}
+	if !validStickerSource(source) || name == "" {
+		return c.Send("Использование: /sticker <building|room|position|step:<шаг>> <название стикера|off>")
+	}
+	if name == "off" {
+		b.storage.SetStickerMapping(source, "")
+		if err := b.storage.SaveData(); err != nil {
+			log.Printf("Ошибка сохранения настроек: %v", err)
+		}
+		return c.Send(fmt.Sprintf("Стикер для %s больше не заполняется.", source))
+	}
+
+	boardID, _ := b.target()
+	stickers, err := b.yougileClient.ListStickers(b.ctx, boardID)
+	if err != nil {
+		log.Printf("Ошибка получения стикеров доски %s: %v", boardID, err)
+		b.ReportAPIError(err)
+		return c.Send(yougileErrorText(err, "Не удалось получить стикеры доски Yougile."))
+	}
+	for _, s := range stickers {
+		if s.ID == name || strings.EqualFold(s.Name, name) {
+			b.storage.SetStickerMapping(source, s.ID)
+			if err := b.storage.SaveData(); err != nil {
+				log.Printf("Ошибка сохранения настроек: %v", err)
+			}
+			return c.Send(fmt.Sprintf("✅ Значение %s будет записываться в стикер «%s».", source, s.Name))
+		}
+	}
+	return c.Send(fmt.Sprintf("Стикер «%s» не найден на доске. Список стикеров: /stickers", name))
+}
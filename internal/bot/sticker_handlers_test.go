@@ -0,0 +1,68 @@
+// Package bot содержит тесты заполнения стикеров и описания задач.
+package bot
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"yougile_bot4/internal/models"
+)
+
+// stickerBoard отдаёт стикеры доски и запоминает попытки создать состояние.
+type stickerBoard struct {
+	createdStates int
+}
+
+func (s *stickerBoard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/api-v2/string-stickers":
+		fmt.Fprint(w, `{"paging":{"count":2,"limit":50,"offset":0,"next":false},"content":[
+			{"id":"s-building","name":"Здание","states":[{"id":"st1","name":"Корпус 1"}]},
+			{"id":"s-room","name":"Кабинет"}]}`)
+	case r.Method == http.MethodPost && r.URL.Path == "/api-v2/string-stickers/s-building/states":
+		s.createdStates++
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":"st-new"}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestTaskDescriptionSkipsFieldsWrittenToStickers(t *testing.T) {
+	board := &stickerBoard{}
+	b := newTestBot(t, board)
+	b.boardID = "board"
+	b.storage.SetStickerMapping(models.StickerSourceBuilding, "s-building")
+	b.storage.SetStickerMapping(models.StickerSourceRoom, "s-room")
+	user := &models.User{BuildingAddress: "Корпус 1", RoomNumber: "101", Position: "Бухгалтер"}
+
+	stickers, inStickers := b.taskStickers(user, nil, "board")
+	if stickers["s-building"] != "st1" || stickers["s-room"] != "101" {
+		t.Fatalf("unexpected stickers %v", stickers)
+	}
+	if got := b.formatTaskDescription(user, "Не работает принтер", inStickers); got != "Не работает принтер (Бухгалтер)" {
+		t.Fatalf("fields written to stickers must not be repeated in the description, got %q", got)
+	}
+
+	// Нового корпуса нет среди состояний: без разрешения состояние не создаётся,
+	// а адрес остаётся в описании
+	user.BuildingAddress = "Корпус 5"
+	stickers, inStickers = b.taskStickers(user, nil, "board")
+	if _, ok := stickers["s-building"]; ok || board.createdStates != 0 {
+		t.Fatalf("missing state must not be created without opt-in, got %v (created %d)", stickers, board.createdStates)
+	}
+	if got := b.formatTaskDescription(user, "Не работает принтер", inStickers); got != "Не работает принтер (Корпус 5, Бухгалтер)" {
+		t.Fatalf("building must stay in the description, got %q", got)
+	}
+
+	b.storage.SetStickerCreateStates(true)
+	if stickers, _ = b.taskStickers(user, nil, "board"); stickers["s-building"] != "st-new" || board.createdStates != 1 {
+		t.Fatalf("expected state to be created after opt-in, got %v", stickers)
+	}
+
+	// Без настроенных стикеров описание не меняется
+	if got := b.formatTaskDescription(user, "", nil); got != "(Корпус 5, каб. 101, Бухгалтер)" {
+		t.Fatalf("unexpected description without stickers: %q", got)
+	}
+}
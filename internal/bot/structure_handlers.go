@@ -0,0 +1,266 @@
+// Package bot содержит выбор колонки Yougile для новых задач.
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"yougile_bot4/internal/models"
+
+	"gopkg.in/telebot.v3"
+)
+
+// StructurePickState хранит ход выбора колонки администратором:
+// выбранные проект и доску и последние показанные списки.
+type StructurePickState struct {
+	ProjectID    string
+	ProjectTitle string
+	BoardID      string
+	BoardTitle   string
+	Projects     []models.Project
+	Boards       []models.Board
+	Columns      []models.Column
+	// RouteID и RouteTitle заданы, если колонка выбирается для направления (/addboard)
+	RouteID    string
+	RouteTitle string
+	StartTime  time.Time
+}
+
+// target возвращает доску и колонку, в которых создаются новые задачи.
+func (b *Bot) target() (boardID, columnID string) {
+	b.targetMu.RLock()
+	defer b.targetMu.RUnlock()
+	return b.boardID, b.defaultColumn
+}
+
+// applyTarget переключает бота и клиент Yougile на выбранную колонку.
+func (b *Bot) applyTarget(tc models.TargetColumn) {
+	b.targetMu.Lock()
+	b.boardID = tc.BoardID
+	b.defaultColumn = tc.ColumnID
+	b.targetMu.Unlock()
+	b.yougileClient.SetBoardID(tc.BoardID)
+	b.yougileClient.SetColumnID(tc.ColumnID)
+}
+
+// pickState возвращает состояние выбора колонки администратора, создавая его при необходимости.
+func (b *Bot) pickState(adminID int64) *StructurePickState {
+	b.pickMu.Lock()
+	defer b.pickMu.Unlock()
+	state, ok := b.structurePicks[adminID]
+	if !ok {
+		state = &StructurePickState{StartTime: time.Now()}
+		b.structurePicks[adminID] = state
+	}
+	return state
+}
+
+// callbackArg извлекает аргумент из данных callback вида "prefix|<arg>".
+func callbackArg(c telebot.Context) string {
+	raw := c.Callback().Data
+	if i := strings.Index(raw, "|"); i >= 0 {
+		return raw[i+1:]
+	}
+	return raw
+}
+
+// handleColumn показывает колонку, в которой сейчас создаются новые задачи.
+func (b *Bot) handleColumn(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	if tc, ok := b.storage.GetTargetColumn(); ok {
+		return c.Send(fmt.Sprintf("Новые задачи создаются в колонке «%s» доски «%s» (проект «%s»).\nИзменить: /projects",
+			tc.ColumnTitle, tc.BoardTitle, tc.ProjectTitle))
+	}
+	boardID, columnID := b.target()
+	if boardID == "" {
+		return c.Send("Колонка для новых задач не выбрана. Выберите её командой /projects")
+	}
+	return c.Send(fmt.Sprintf("Колонка задана переменными окружения: доска %s, колонка %s.\nВыбрать колонку в боте: /projects", boardID, columnID))
+}
+
+// handleProjects начинает выбор колонки основной доски для новых задач.
+func (b *Bot) handleProjects(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	state := b.pickState(c.Sender().ID)
+	b.pickMu.Lock()
+	state.RouteID, state.RouteTitle = "", ""
+	b.pickMu.Unlock()
+	return b.showProjects(c)
+}
+
+// showProjects показывает проекты Yougile для выбора колонки.
+func (b *Bot) showProjects(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	projects, err := b.yougileClient.ListProjects(b.ctx)
+	if err != nil {
+		log.Printf("Ошибка получения проектов Yougile: %v", err)
+		b.ReportAPIError(err)
+		return c.Send(yougileErrorText(err, "Не удалось получить список проектов Yougile."))
+	}
+	if len(projects) == 0 {
+		return c.Send("В Yougile нет доступных проектов.")
+	}
+	state := b.pickState(c.Sender().ID)
+	b.pickMu.Lock()
+	state.Projects = projects
+	state.StartTime = time.Now()
+	b.pickMu.Unlock()
+
+	menu := &telebot.ReplyMarkup{}
+	var rows []telebot.Row
+	for _, p := range projects {
+		rows = append(rows, menu.Row(menu.Data(p.Title, "ygproj", p.ID)))
+	}
+	menu.Inline(rows...)
+
+	if c.Callback() != nil {
+		_ = c.Respond()
+		return c.Edit("Выберите проект:", menu)
+	}
+	return c.Send("Выберите проект:", menu)
+}
+
+// handleProjectCallback показывает доски выбранного проекта.
+func (b *Bot) handleProjectCallback(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	_ = c.Respond()
+	projectID := callbackArg(c)
+	boards, err := b.yougileClient.ListBoards(b.ctx, projectID)
+	if err != nil {
+		log.Printf("Ошибка получения досок проекта %s: %v", projectID, err)
+		b.ReportAPIError(err)
+		return c.Send(yougileErrorText(err, "Не удалось получить список досок Yougile."))
+	}
+
+	state := b.pickState(c.Sender().ID)
+	b.pickMu.Lock()
+	state.ProjectID = projectID
+	state.ProjectTitle = projectID
+	for _, p := range state.Projects {
+		if p.ID == projectID {
+			state.ProjectTitle = p.Title
+			break
+		}
+	}
+	state.Boards = boards
+	title := state.ProjectTitle
+	b.pickMu.Unlock()
+
+	menu := &telebot.ReplyMarkup{}
+	var rows []telebot.Row
+	for _, board := range boards {
+		rows = append(rows, menu.Row(menu.Data(board.Title, "ygboard", board.ID)))
+	}
+	rows = append(rows, menu.Row(menu.Data("⬅️ К проектам", "ygprojects")))
+	menu.Inline(rows...)
+
+	if len(boards) == 0 {
+		return c.Edit(fmt.Sprintf("В проекте «%s» нет досок.", title), menu)
+	}
+	return c.Edit(fmt.Sprintf("Проект «%s». Выберите доску:", title), menu)
+}
+
+// handleBoardCallback показывает колонки выбранной доски.
+func (b *Bot) handleBoardCallback(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	_ = c.Respond()
+	boardID := callbackArg(c)
+	columns, err := b.yougileClient.ListColumns(b.ctx, boardID)
+	if err != nil {
+		log.Printf("Ошибка получения колонок доски %s: %v", boardID, err)
+		b.ReportAPIError(err)
+		return c.Send(yougileErrorText(err, "Не удалось получить список колонок Yougile."))
+	}
+
+	state := b.pickState(c.Sender().ID)
+	b.pickMu.Lock()
+	state.BoardID = boardID
+	state.BoardTitle = boardID
+	for _, board := range state.Boards {
+		if board.ID == boardID {
+			state.BoardTitle = board.Title
+			break
+		}
+	}
+	state.Columns = columns
+	title, projectID := state.BoardTitle, state.ProjectID
+	b.pickMu.Unlock()
+
+	menu := &telebot.ReplyMarkup{}
+	var rows []telebot.Row
+	for _, col := range columns {
+		rows = append(rows, menu.Row(menu.Data(col.Title, "ygcol", col.ID)))
+	}
+	rows = append(rows, menu.Row(menu.Data("⬅️ К доскам", "ygproj", projectID)))
+	menu.Inline(rows...)
+
+	if len(columns) == 0 {
+		return c.Edit(fmt.Sprintf("На доске «%s» нет колонок.", title), menu)
+	}
+	return c.Edit(fmt.Sprintf("Доска «%s». Выберите колонку для новых задач:", title), menu)
+}
+
+// handleColumnCallback сохраняет выбранную колонку и переключает на неё бота.
+func (b *Bot) handleColumnCallback(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	_ = c.Respond()
+	columnID := callbackArg(c)
+
+	state := b.pickState(c.Sender().ID)
+	b.pickMu.Lock()
+	var tc *models.TargetColumn
+	for _, col := range state.Columns {
+		if col.ID == columnID {
+			tc = &models.TargetColumn{
+				ProjectID:    state.ProjectID,
+				ProjectTitle: state.ProjectTitle,
+				BoardID:      state.BoardID,
+				BoardTitle:   state.BoardTitle,
+				ColumnID:     col.ID,
+				ColumnTitle:  col.Title,
+				UpdatedBy:    c.Sender().ID,
+				UpdatedAt:    time.Now(),
+			}
+			break
+		}
+	}
+	routeID, routeTitle := state.RouteID, state.RouteTitle
+	delete(b.structurePicks, c.Sender().ID)
+	b.pickMu.Unlock()
+
+	if tc == nil {
+		return c.Send("Список колонок устарел. Повторите выбор: /projects")
+	}
+	if routeID != "" {
+		return b.saveBoardRoute(c, routeID, routeTitle, *tc)
+	}
+	b.storage.SetTargetColumn(*tc)
+	if err := b.storage.SaveData(); err != nil {
+		log.Printf("Ошибка сохранения настроек: %v", err)
+	}
+	b.applyTarget(*tc)
+	log.Printf("Администратор %d выбрал колонку %s (%s) доски %s (%s)", c.Sender().ID, tc.ColumnID, tc.ColumnTitle, tc.BoardID, tc.BoardTitle)
+
+	return c.Edit(fmt.Sprintf("✅ Новые задачи будут создаваться в колонке «%s» доски «%s» (проект «%s»).",
+		tc.ColumnTitle, tc.BoardTitle, tc.ProjectTitle))
+}
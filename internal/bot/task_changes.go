@@ -0,0 +1,153 @@
+// Package bot содержит обработку изменений задач Yougile, найденных опросом и
+// вебхуками: перемещений, выполнения, сроков и назначений, — и сообщения о них
+// авторам заявок.
+package bot
+
+import (
+	"log"
+	"time"
+
+	"yougile_bot4/internal/api"
+	"yougile_bot4/internal/changes"
+	"yougile_bot4/internal/models"
+	"yougile_bot4/internal/notify"
+
+	"gopkg.in/telebot.v3"
+)
+
+// SetChangeNotifications включает сообщения об изменениях задач в чаты их досок.
+// Вызывается до Start.
+func (b *Bot) SetChangeNotifications(enabled bool) {
+	b.changeNotify = enabled
+}
+
+// HandleTaskChanges обрабатывает изменения задач: обновляет локальный кэш,
+// сообщает автору заявки об изменениях его задачи и, если включено, сообщает
+// о них в чаты доски задачи.
+func (b *Bot) HandleTaskChanges(changes []models.TaskChange) {
+	if len(changes) == 0 {
+		return
+	}
+	columns := make(map[string]string)
+	for _, ch := range changes {
+		id := outboxTaskID(&ch.Task)
+		log.Printf("Изменение задачи %s: %s", id, ch.Kind)
+		if ch.Kind == models.ChangeCompleted && b.storage.CompleteTask(id) {
+			if err := b.storage.SaveData(); err != nil {
+				log.Printf("Ошибка сохранения задачи %s: %v", id, err)
+			}
+		}
+		b.notifyRequester(ch, columns)
+		if !b.changeNotify {
+			continue
+		}
+		msg := b.formatTaskChange(ch, columns)
+		if msg == "" {
+			continue
+		}
+		b.sendToTaskChats(ch.Task, msg)
+	}
+}
+
+// formatTaskChange описывает изменение задачи по шаблону notify.TaskChange.
+// columns кэширует названия колонок на время обработки пачки изменений.
+func (b *Bot) formatTaskChange(ch models.TaskChange, columns map[string]string) string {
+	data := notify.TaskChangeData{Kind: ch.Kind, Key: taskKeyText(ch.Task), Task: ch.Task, Prev: ch.Prev}
+	switch ch.Kind {
+	case models.ChangeMoved:
+		data.From = b.columnTitle(ch.Prev.BoardID, ch.Prev.ColumnID, columns)
+		data.To = b.columnTitle(ch.Task.BoardID, ch.Task.ColumnID, columns)
+	case models.ChangeAssigned:
+		data.Added = b.yougileClient.UserNames(api.Background(b.ctx), ch.Added)
+	}
+	return b.messages.Render(notify.TaskChange, data)
+}
+
+// columnTitle возвращает название колонки доски boardID (или её ID, если
+// название получить не удалось).
+func (b *Bot) columnTitle(boardID, columnID string, cache map[string]string) string {
+	if title, ok := cache[columnID]; ok {
+		return "«" + title + "»"
+	}
+	if boardID != "" {
+		columns, err := b.yougileClient.ListColumns(api.Background(b.ctx), boardID)
+		if err != nil {
+			log.Printf("Ошибка получения колонок доски %s: %v", boardID, err)
+		}
+		for _, col := range columns {
+			cache[col.ID] = col.Title
+		}
+	}
+	if title, ok := cache[columnID]; ok {
+		return "«" + title + "»"
+	}
+	return columnID
+}
+
+// requesterChanges — изменения, о которых сообщается автору заявки.
+var requesterChanges = map[models.ChangeKind]bool{
+	models.ChangeMoved:     true,
+	models.ChangeDeadline:  true,
+	models.ChangeCompleted: true,
+	models.ChangeReopened:  true,
+}
+
+// notifyRequester отправляет автору заявки личное сообщение об изменении его
+// задачи, если он не отключил такие сообщения командой /updates.
+func (b *Bot) notifyRequester(ch models.TaskChange, columns map[string]string) {
+	if !requesterChanges[ch.Kind] {
+		return
+	}
+	owner := ch.Prev.RequesterID
+	if owner == 0 {
+		// задачи, созданные до появления снимков, известны по локальной копии
+		if task := b.findTask(outboxTaskID(&ch.Task)); task != nil {
+			owner = task.RequesterID
+		}
+	}
+	if owner == 0 {
+		return
+	}
+	if user, ok := b.storage.GetUser(owner); !ok || user.MuteTaskUpdates {
+		return
+	}
+	msg := "🔔 Ваша заявка изменилась:\n" + b.formatTaskChange(ch, columns) +
+		"\n\nОтключить такие сообщения: /updates"
+	b.enqueue(owner, msg)
+}
+
+// TrackTaskOwner запоминает автора заявки в снимке задачи, чтобы сообщать ему
+// об изменениях задачи в Yougile.
+func (b *Bot) TrackTaskOwner(task models.Task, requesterID int64) {
+	key := changes.TaskKey(task)
+	if key == "" || requesterID == 0 {
+		return
+	}
+	snap, ok := b.storage.GetTaskSnapshot(key)
+	if !ok {
+		snap = changes.Snapshot(task, time.Now())
+	}
+	if snap.BoardID == "" {
+		snap.BoardID, _ = b.target()
+	}
+	snap.RequesterID = requesterID
+	b.storage.SetTaskSnapshot(snap)
+}
+
+// handleTaskUpdates включает и отключает личные сообщения об изменениях заявок.
+func (b *Bot) handleTaskUpdates(c telebot.Context) error {
+	user, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists {
+		return c.Send("Пожалуйста, сначала зарегистрируйтесь с помощью команды /start")
+	}
+	updated := *user
+	updated.MuteTaskUpdates = !user.MuteTaskUpdates
+	b.storage.UpdateUser(&updated)
+	if err := b.storage.SaveData(); err != nil {
+		log.Printf("Ошибка сохранения настроек пользователя %d: %v", updated.TelegramID, err)
+	}
+	if updated.MuteTaskUpdates {
+		return c.Send("🔕 Сообщения об изменениях ваших заявок отключены. Включить снова: /updates")
+	}
+	return c.Send("🔔 Сообщения об изменениях ваших заявок включены: бот сообщит, когда заявку переместят, назначат срок, выполнят или вернут в работу.")
+}
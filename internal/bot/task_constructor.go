@@ -27,6 +27,16 @@ func (b *Bot) handleTaskConstructor(c telebot.Context) error {
 		IsTemplated: true,
 	}
 
+	// Если настроено несколько досок и доска не выбирается по ответам,
+	// пользователь сначала выбирает направление
+	if b.needsBoardPick() {
+		return c.Send("Выберите, куда направить заявку:", b.boardPickMarkup())
+	}
+	return b.sendInitialStep(c)
+}
+
+// sendInitialStep показывает первый шаг конструктора задач.
+func (b *Bot) sendInitialStep(c telebot.Context) error {
 	// Получаем первый шаг
 	step, exists := b.storage.GetTaskTemplate("initial")
 	if !exists {
@@ -4,7 +4,6 @@ package bot
 import (
 	"fmt"
 	"log"
-	"strconv"
 	"time"
 	"yougile_bot4/internal/models"
 
@@ -24,29 +23,39 @@ func (b *Bot) handleSkip(c telebot.Context) error {
 
 	// Создаем задачу без комментария
 	user, _ := b.storage.GetUser(c.Sender().ID)
+	boardID, columnID := b.taskDestination(state)
+	stickers, inStickers := b.taskStickers(user, state, boardID)
 	desc := ""
 	if user != nil {
-		desc = b.formatTaskDescription(user, "")
+		desc = b.formatTaskDescription(user, "", inStickers)
 	}
 	task := &models.Task{
 		Title:       b.formatTaskTitle(user, state.Title),
 		Description: desc,
 		Status:      models.TaskStatusNew,
-		BoardID:     b.boardID,
+		BoardID:     boardID,
 		Priority:    1,
-		Assignee:    strconv.FormatInt(c.Sender().ID, 10),
+		RequesterID: c.Sender().ID,
+		Assigned:    b.defaultAssigned(),
+		Stickers:    stickers,
 		Labels:      []string{},
 		CreatedAt:   time.Now(),
 	}
 
 	// set default column if configured
 	if task.ColumnID == "" {
-		task.ColumnID = b.defaultColumn
+		task.ColumnID = columnID
 	}
-	// Отправляем задачу в Yougile
-	if err := b.yougileClient.CreateTask(task); err != nil {
+	// Отправляем задачу в Yougile (или откладываем, если он недоступен)
+	queued, err := b.createTaskOrQueue(task, c.Sender().ID, "", nil)
+	if err != nil {
 		log.Printf("Ошибка создания задачи в Yougile: %v", err)
-		return c.Send("Произошла ошибка при создании задачи. Пожалуйста, попробуйте позже.")
+		b.ReportAPIError(err)
+		return c.Send(yougileErrorText(err, "Произошла ошибка при создании задачи. Пожалуйста, попробуйте позже."))
+	}
+	if queued != 0 {
+		delete(b.taskCreationStates, c.Sender().ID)
+		return c.Send(msgTaskQueued, b.menuForContext(c))
 	}
 
 	// Сохраняем задачу локально
@@ -92,28 +101,38 @@ func (b *Bot) handleTaskText(c telebot.Context) error {
 
 		// Создаем новую задачу с комментарием
 		user, _ := b.storage.GetUser(c.Sender().ID)
+		boardID, columnID := b.taskDestination(state)
+		stickers, inStickers := b.taskStickers(user, state, boardID)
 		desc := msg
 		if user != nil {
-			desc = b.formatTaskDescription(user, msg)
+			desc = b.formatTaskDescription(user, msg, inStickers)
 		}
 		task := &models.Task{
 			Title:       b.formatTaskTitle(user, state.Title),
 			Description: desc,
 			Status:      models.TaskStatusNew,
 			Priority:    1,
-			Assignee:    strconv.FormatInt(c.Sender().ID, 10),
-			BoardID:     b.boardID,
+			RequesterID: c.Sender().ID,
+			Assigned:    b.defaultAssigned(),
+			Stickers:    stickers,
+			BoardID:     boardID,
 			Labels:      []string{},
 			CreatedAt:   time.Now(),
 		}
 
 		if task.ColumnID == "" {
-			task.ColumnID = b.defaultColumn
+			task.ColumnID = columnID
 		}
-		// Отправляем задачу в Yougile
-		if err := b.yougileClient.CreateTask(task); err != nil {
+		// Отправляем задачу в Yougile (или откладываем, если он недоступен)
+		queued, err := b.createTaskOrQueue(task, c.Sender().ID, msg, nil)
+		if err != nil {
 			log.Printf("Ошибка создания задачи в Yougile: %v", err)
-			return c.Send("Произошла ошибка при создании задачи. Пожалуйста, попробуйте позже.")
+			b.ReportAPIError(err)
+			return c.Send(yougileErrorText(err, "Произошла ошибка при создании задачи. Пожалуйста, попробуйте позже."))
+		}
+		if queued != 0 {
+			delete(b.taskCreationStates, c.Sender().ID)
+			return c.Send(msgTaskQueued, b.menuForContext(c))
 		}
 
 		// Сохраняем задачу локально
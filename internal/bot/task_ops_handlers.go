@@ -0,0 +1,190 @@
+// Package bot содержит кнопки администраторов под уведомлениями о новых задачах:
+// выполнение, архив, перемещение, срок и возврат в работу.
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"yougile_bot4/internal/models"
+
+	"gopkg.in/telebot.v3"
+)
+
+// taskOpsUnique — префикс данных кнопок действий с задачей.
+const taskOpsUnique = "ygt"
+
+// deadlineOptions — варианты срока на кнопках: подпись и число дней от сегодня
+// (отрицательное значение снимает срок).
+var deadlineOptions = []struct {
+	Text string
+	Days int
+}{
+	{"Сегодня", 0},
+	{"Завтра", 1},
+	{"Через 3 дня", 3},
+	{"Через неделю", 7},
+	{"Снять срок", -1},
+}
+
+// TaskMovePick хранит выбор колонки при перемещении задачи администратором.
+type TaskMovePick struct {
+	TaskID    string
+	Columns   []models.Column
+	StartTime time.Time
+}
+
+// taskActionsMarkup возвращает кнопки действий с задачей taskID; для закрытой
+// задачи остаётся только возврат в работу.
+func taskActionsMarkup(taskID string, closed bool) *telebot.ReplyMarkup {
+	menu := &telebot.ReplyMarkup{}
+	if closed {
+		menu.Inline(menu.Row(menu.Data("↩️ Вернуть в работу", taskOpsUnique, "reopen|"+taskID)))
+		return menu
+	}
+	menu.Inline(
+		menu.Row(
+			menu.Data("✅ Выполнена", taskOpsUnique, "done|"+taskID),
+			menu.Data("🗄 В архив", taskOpsUnique, "archive|"+taskID),
+		),
+		menu.Row(
+			menu.Data("➡️ Переместить", taskOpsUnique, "move|"+taskID),
+			menu.Data("📅 Срок", taskOpsUnique, "due|"+taskID),
+		),
+	)
+	return menu
+}
+
+// handleTaskOpsCallback выполняет действие с задачей по кнопке "ygt|<действие>|<аргументы>".
+func (b *Bot) handleTaskOpsCallback(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Respond(&telebot.CallbackResponse{Text: "Действие доступно только администраторам.", ShowAlert: true})
+	}
+	action, arg, _ := strings.Cut(callbackArg(c), "|")
+	switch action {
+	case "done":
+		return b.runTaskOp(c, arg, b.yougileClient.SetTaskCompleted(b.ctx, arg, true), "✅ Выполнена", true, func() {
+			if b.storage.CompleteTask(arg) {
+				if err := b.storage.SaveData(); err != nil {
+					log.Printf("Ошибка сохранения задачи %s: %v", arg, err)
+				}
+			}
+		})
+	case "archive":
+		return b.runTaskOp(c, arg, b.yougileClient.ArchiveTask(b.ctx, arg, true), "🗄 Перенесена в архив", true, nil)
+	case "reopen":
+		return b.runTaskOp(c, arg, b.yougileClient.ReopenTask(b.ctx, arg), "↩️ Возвращена в работу", false, nil)
+	case "menu":
+		_ = c.Respond()
+		return c.Edit(c.Message().Text, taskActionsMarkup(arg, false))
+	case "move":
+		return b.showMoveColumns(c, arg)
+	case "col":
+		return b.handleMoveColumn(c, arg)
+	case "due":
+		menu := &telebot.ReplyMarkup{}
+		var rows []telebot.Row
+		for _, opt := range deadlineOptions {
+			rows = append(rows, menu.Row(menu.Data(opt.Text, taskOpsUnique, fmt.Sprintf("dl|%d|%s", opt.Days, arg))))
+		}
+		rows = append(rows, menu.Row(menu.Data("⬅️ Назад", taskOpsUnique, "menu|"+arg)))
+		menu.Inline(rows...)
+		_ = c.Respond()
+		return c.Edit(c.Message().Text, menu)
+	case "dl":
+		daysStr, taskID, _ := strings.Cut(arg, "|")
+		days, err := strconv.Atoi(daysStr)
+		if err != nil {
+			return c.Respond(&telebot.CallbackResponse{Text: "Неверный срок."})
+		}
+		if days < 0 {
+			return b.runTaskOp(c, taskID, b.yougileClient.SetTaskDeadline(b.ctx, taskID, time.Time{}, false), "📅 Срок снят", false, nil)
+		}
+		now := time.Now()
+		due := time.Date(now.Year(), now.Month(), now.Day()+days, 23, 59, 0, 0, now.Location())
+		status := fmt.Sprintf("📅 Срок: %s", due.Format("02.01.2006"))
+		return b.runTaskOp(c, taskID, b.yougileClient.SetTaskDeadline(b.ctx, taskID, due, false), status, false, nil)
+	}
+	return c.Respond(&telebot.CallbackResponse{Text: "Неизвестное действие."})
+}
+
+// runTaskOp завершает действие с задачей: при ошибке Yougile показывает её,
+// иначе выполняет after (может быть nil) и дописывает status к уведомлению.
+func (b *Bot) runTaskOp(c telebot.Context, taskID string, err error, status string, closed bool, after func()) error {
+	if err != nil {
+		log.Printf("Ошибка действия с задачей %s: %v", taskID, err)
+		b.ReportAPIError(err)
+		return c.Respond(&telebot.CallbackResponse{
+			Text:      yougileErrorText(err, "Не удалось изменить задачу в Yougile."),
+			ShowAlert: true,
+		})
+	}
+	if after != nil {
+		after()
+	}
+	_ = c.Respond(&telebot.CallbackResponse{Text: status})
+	text := fmt.Sprintf("%s\n%s — %s", c.Message().Text, status, strings.TrimSpace(c.Sender().FirstName+" "+c.Sender().LastName))
+	return c.Edit(text, taskActionsMarkup(taskID, closed))
+}
+
+// taskBoardID определяет доску задачи taskID: по сохранённому снимку, затем
+// по самой задаче в Yougile; если доску узнать не удалось — основная доска бота.
+func (b *Bot) taskBoardID(taskID string) string {
+	if snap, ok := b.storage.GetTaskSnapshot(taskID); ok && snap.BoardID != "" {
+		return snap.BoardID
+	}
+	if task, err := b.yougileClient.GetTaskByIDQuiet(b.ctx, taskID); err == nil && task.BoardID != "" {
+		return task.BoardID
+	}
+	boardID, _ := b.target()
+	return boardID
+}
+
+// showMoveColumns предлагает колонки доски, на которой находится задача, для её перемещения.
+func (b *Bot) showMoveColumns(c telebot.Context, taskID string) error {
+	boardID := b.taskBoardID(taskID)
+	if boardID == "" {
+		return c.Respond(&telebot.CallbackResponse{Text: "Доска не выбрана. Выберите колонку командой /projects", ShowAlert: true})
+	}
+	columns, err := b.yougileClient.ListColumns(b.ctx, boardID)
+	if err != nil {
+		log.Printf("Ошибка получения колонок доски %s: %v", boardID, err)
+		b.ReportAPIError(err)
+		return c.Respond(&telebot.CallbackResponse{
+			Text:      yougileErrorText(err, "Не удалось получить колонки доски Yougile."),
+			ShowAlert: true,
+		})
+	}
+	b.pickMu.Lock()
+	b.taskMoves[c.Sender().ID] = &TaskMovePick{TaskID: taskID, Columns: columns, StartTime: time.Now()}
+	b.pickMu.Unlock()
+
+	menu := &telebot.ReplyMarkup{}
+	var rows []telebot.Row
+	for i, col := range columns {
+		rows = append(rows, menu.Row(menu.Data(col.Title, taskOpsUnique, "col|"+strconv.Itoa(i))))
+	}
+	rows = append(rows, menu.Row(menu.Data("⬅️ Назад", taskOpsUnique, "menu|"+taskID)))
+	menu.Inline(rows...)
+	_ = c.Respond()
+	return c.Edit(c.Message().Text, menu)
+}
+
+// handleMoveColumn перемещает задачу в выбранную администратором колонку.
+func (b *Bot) handleMoveColumn(c telebot.Context, arg string) error {
+	b.pickMu.Lock()
+	pick := b.taskMoves[c.Sender().ID]
+	delete(b.taskMoves, c.Sender().ID)
+	b.pickMu.Unlock()
+	i, err := strconv.Atoi(arg)
+	if pick == nil || err != nil || i < 0 || i >= len(pick.Columns) {
+		return c.Respond(&telebot.CallbackResponse{Text: "Список колонок устарел, нажмите «Переместить» ещё раз.", ShowAlert: true})
+	}
+	col := pick.Columns[i]
+	status := fmt.Sprintf("➡️ Перемещена в «%s»", col.Title)
+	return b.runTaskOp(c, pick.TaskID, b.yougileClient.MoveTask(b.ctx, pick.TaskID, col.ID), status, false, nil)
+}
@@ -7,9 +7,9 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"yougile_bot4/internal/api"
 	"yougile_bot4/internal/models"
-
-	"gopkg.in/telebot.v3"
+	"yougile_bot4/internal/notify"
 )
 
 // TaskVerification описывает состояние проверки корректности созданной задачи
@@ -44,39 +44,37 @@ func (b *Bot) startTaskVerification(task models.Task, sender models.User, conten
 
 // verifyTask проверяет корректность создания задачи
 func (b *Bot) verifyTask(v *TaskVerification) {
-	// Получаем актуальную задачу из Yougile
-	tasks, err := b.yougileClient.GetTasks(100) // Получаем последние задачи
-	if err != nil {
-		b.notifyError(v, "Ошибка при получении задач из Yougile")
-		return
-	}
-
+	// Ищем задачу постранично среди задач доски; обход прекращается, как только
+	// задача найдена по идентификатору. Попутно запоминаем кандидата,
+	// совпадающего по названию/описанию, — на случай, если ID не совпадут.
 	var foundTask *models.Task
-	for i := range tasks {
-		// Match by numeric ID
-		if tasks[i].ID != 0 && v.OriginalTask.ID != 0 && tasks[i].ID == v.OriginalTask.ID {
-			foundTask = &tasks[i]
+	var candidate *models.Task
+	it := b.yougileClient.IterateTasks(api.Background(b.ctx), b.yougileClient.DefaultFilter())
+	for it.Next() {
+		t := it.Task()
+		if matchesVerifiedTask(t, v.OriginalTask) {
+			foundTask = &t
 			break
 		}
-		// Match by ExternalID (UUID or string id)
-		if v.OriginalTask.ExternalID != "" && tasks[i].ExternalID != "" && tasks[i].ExternalID == v.OriginalTask.ExternalID {
-			foundTask = &tasks[i]
-			break
-		}
-		// Some servers may return numeric ID while original stored ExternalID as string; compare string forms
-		if v.OriginalTask.ExternalID != "" {
-			if fmt.Sprintf("%d", tasks[i].ID) == v.OriginalTask.ExternalID {
-				foundTask = &tasks[i]
-				break
-			}
+		if candidate == nil && (t.Title == v.OriginalTask.Title || strings.Contains(t.Description, v.OriginalContent)) {
+			candidate = &t
 		}
 	}
+	if b.ctx.Err() != nil {
+		// бот завершает работу: результат проверки недостоверен, повторно задачу не создаём
+		log.Printf("verifyTask: проверка задачи %q прервана: %v", v.OriginalTask.Title, b.ctx.Err())
+		return
+	}
+	if err := it.Err(); err != nil && foundTask == nil && candidate == nil {
+		b.notifyError(v, "Ошибка при получении задач из Yougile")
+		return
+	}
 
 	if foundTask == nil {
 		log.Printf("verifyTask: initial list scan did not find task. Original IDs: ID=%d ExternalID=%s Title=%s", v.OriginalTask.ID, v.OriginalTask.ExternalID, v.OriginalTask.Title)
 		// Try direct lookup by ExternalID or string ID as a fallback
 		if v.OriginalTask.ExternalID != "" {
-			if t, err := b.yougileClient.GetTaskByID(v.OriginalTask.ExternalID); err == nil && t != nil {
+			if t, err := b.yougileClient.GetTaskByID(b.ctx, v.OriginalTask.ExternalID); err == nil && t != nil {
 				log.Printf("verifyTask: found task via GetTaskByID by ExternalID=%s -> ID=%d Title=%s", v.OriginalTask.ExternalID, t.ID, t.Title)
 				foundTask = t
 			} else if err != nil {
@@ -84,15 +82,10 @@ func (b *Bot) verifyTask(v *TaskVerification) {
 			}
 		}
 
-		// As a last resort, try to find by title or description substring in the list we already fetched
-		if foundTask == nil {
-			for i := range tasks {
-				if tasks[i].Title == v.OriginalTask.Title || strings.Contains(tasks[i].Description, v.OriginalContent) {
-					log.Printf("verifyTask: matched by title/description -> ID=%d ExternalID=%s Title=%s", tasks[i].ID, tasks[i].ExternalID, tasks[i].Title)
-					foundTask = &tasks[i]
-					break
-				}
-			}
+		// As a last resort, use the task matched by title or description substring during the scan
+		if foundTask == nil && candidate != nil {
+			log.Printf("verifyTask: matched by title/description -> ID=%d ExternalID=%s Title=%s", candidate.ID, candidate.ExternalID, candidate.Title)
+			foundTask = candidate
 		}
 
 		if foundTask == nil {
@@ -108,7 +101,7 @@ func (b *Bot) verifyTask(v *TaskVerification) {
 	}
 
 	if v.HasImage {
-		if !verifyTaskAttachments(foundTask) {
+		if !verifyTaskAttachments(foundTask, v.OriginalTask) {
 			b.handleVerificationFailure(v, "Отсутствует или некорректно загружено изображение")
 			return
 		}
@@ -123,10 +116,22 @@ func (b *Bot) verifyTask(v *TaskVerification) {
 		taskIDStr = strconv.FormatInt(foundTask.ID, 10)
 	}
 
-	successMsg := fmt.Sprintf("✅ Задача успешно создана в Yougile:\n📎 %s\n🆔 %s", foundTask.Title, taskIDStr)
-	if _, err := b.bot.Send(&telebot.User{ID: v.OriginalSender.TelegramID}, successMsg); err != nil {
-		log.Printf("verifyTask: ошибка отправки подтверждения отправителю %d: %v", v.OriginalSender.TelegramID, err)
+	// Автор заявки будет получать сообщения об изменениях задачи
+	owned := *foundTask
+	if owned.BoardID == "" {
+		owned.BoardID = v.OriginalTask.BoardID
 	}
+	b.TrackTaskOwner(owned, v.OriginalSender.TelegramID)
+
+	successMsg := b.messages.Render(notify.VerifySuccess, notify.VerifyData{
+		Task:      *foundTask,
+		TaskID:    taskIDStr,
+		Sender:    v.OriginalSender,
+		Content:   v.OriginalContent,
+		Attempts:  v.RetryCount + 1,
+		CreatedAt: v.CreatedAt,
+	})
+	b.enqueue(v.OriginalSender.TelegramID, successMsg)
 
 	// Уведомим администраторов краткой заметкой
 	adminNote := fmt.Sprintf("Пользователь %s %s создал задачу: %s (ID: %s)", v.OriginalSender.FirstName, v.OriginalSender.LastName, foundTask.Title, taskIDStr)
@@ -137,13 +142,25 @@ func (b *Bot) verifyTask(v *TaskVerification) {
 			if user.TelegramID == v.OriginalSender.TelegramID {
 				continue
 			}
-			if _, err := b.bot.Send(&telebot.User{ID: user.TelegramID}, adminNote); err != nil {
-				log.Printf("verifyTask: ошибка отправки уведомления администратору %d: %v", user.TelegramID, err)
-			}
+			b.enqueue(user.TelegramID, adminNote)
 		}
 	}
 }
 
+// matchesVerifiedTask сравнивает задачу из списка с отправленной по идентификаторам.
+func matchesVerifiedTask(t, original models.Task) bool {
+	// Match by numeric ID
+	if t.ID != 0 && original.ID != 0 && t.ID == original.ID {
+		return true
+	}
+	// Match by ExternalID (UUID or string id)
+	if original.ExternalID != "" && t.ExternalID != "" && t.ExternalID == original.ExternalID {
+		return true
+	}
+	// Some servers may return numeric ID while original stored ExternalID as string; compare string forms
+	return original.ExternalID != "" && fmt.Sprintf("%d", t.ID) == original.ExternalID
+}
+
 // verifyTaskContent проверяет соответствие содержимого задачи
 func verifyTaskContent(task *models.Task, v *TaskVerification) bool {
 	// Проверяем название и описание задачи
@@ -167,9 +184,18 @@ func verifyTaskContent(task *models.Task, v *TaskVerification) bool {
 	return true
 }
 
-// verifyTaskAttachments проверяет наличие вложений в задаче
-func verifyTaskAttachments(task *models.Task) bool {
-	return len(task.Attachments) > 0
+// verifyTaskAttachments проверяет, что ссылки на загруженные в Yougile файлы
+// отправленной задачи (original.Attachments) есть в описании найденной задачи.
+func verifyTaskAttachments(task *models.Task, original models.Task) bool {
+	if len(original.Attachments) == 0 {
+		return false
+	}
+	for _, fileURL := range original.Attachments {
+		if !strings.Contains(task.Description, fileURL) {
+			return false
+		}
+	}
+	return true
 }
 
 // handleVerificationFailure обрабатывает неудачную проверку
@@ -180,17 +206,17 @@ func (b *Bot) handleVerificationFailure(v *TaskVerification, reason string) {
 		newTask.Title = fmt.Sprintf("%s (повторно исправлено)", newTask.Title)
 		// ensure column is present for recreation
 		if newTask.ColumnID == "" {
-			newTask.ColumnID = b.defaultColumn
+			_, newTask.ColumnID = b.target()
 		}
-		// we intentionally do not set Assigned here
 
-		err := b.yougileClient.CreateTask(&newTask)
+		err := b.yougileClient.CreateTask(b.ctx, &newTask)
 		if err != nil {
 			b.notifyError(v, fmt.Sprintf("Ошибка при повторном создании задачи: %v", err))
 			return
 		}
 
-		if v.HasImage {
+		// ссылка на уже загруженное изображение переходит в новую задачу вместе с описанием
+		if v.HasImage && len(newTask.Attachments) == 0 {
 			// Повторно прикрепляем изображение
 			attachment := &models.Attachment{
 				ID:   fmt.Sprintf("retry_%d", time.Now().Unix()),
@@ -201,7 +227,15 @@ func (b *Bot) handleVerificationFailure(v *TaskVerification, reason string) {
 			if taskIDStr == "" {
 				taskIDStr = strconv.FormatInt(newTask.ID, 10)
 			}
-			err = b.yougileClient.UploadAttachment(taskIDStr, attachment, v.ImageData)
+			err = b.yougileClient.UploadAttachment(b.ctx, taskIDStr, attachment, v.ImageData)
+			if err != nil && api.IsUnavailable(err) {
+				// Yougile недоступен: загрузим изображение позже из outbox
+				if qerr := b.queueAttachment(taskIDStr, 0, attachment, v.ImageData, v.OriginalSender.TelegramID); qerr == nil {
+					err = nil
+				} else {
+					log.Printf("Ошибка постановки вложения в очередь: %v", qerr)
+				}
+			}
 			if err != nil {
 				b.notifyError(v, fmt.Sprintf("Ошибка при повторной загрузке изображения: %v", err))
 				return
@@ -223,46 +257,25 @@ func (b *Bot) handleVerificationFailure(v *TaskVerification, reason string) {
 // notifyError уведомляет пользователя и администраторов об ошибке
 func (b *Bot) notifyError(v *TaskVerification, reason string) {
 	// Уведомляем отправителя
-	errorMsg := fmt.Sprintf("Произошла проблема с созданием задачи: %s\nПожалуйста, обратитесь к администратору.", reason)
-	if _, err := b.bot.Send(&telebot.User{ID: v.OriginalSender.TelegramID}, errorMsg); err != nil {
-		// Логируем ошибку, но продолжаем уведомлять администраторов
-		// чтобы они могли принять меры вручную.
-		// Не возвращаем ошибку, потому что вызывающая горутина ожидает завершения.
-		// Просто логируем проблему.
-		// Используем стандартный log здесь — пакет bot не импортирует log ранее.
-		// Добавим импорт логирования сверху файла.
-		// (Импорт предварительно уже есть в файле; если нет — поправим отдельно.)
-		// Но log импорт отсутствует, добавим его.
-		// Для совместимости: используем fmt.Printf как fallback.
-		// Поскольку в этом файле нет импорта log, выполню простое fmt.Printf.
-		fmt.Printf("Ошибка отправки уведомления отправителю %d: %v\n", v.OriginalSender.TelegramID, err)
+	data := notify.VerifyData{
+		Task:      v.OriginalTask,
+		Sender:    v.OriginalSender,
+		Content:   v.OriginalContent,
+		Reason:    reason,
+		Attempts:  v.RetryCount + 1,
+		CreatedAt: v.CreatedAt,
 	}
+	errorMsg := b.messages.Render(notify.VerifyFailure, data)
+	b.enqueue(v.OriginalSender.TelegramID, errorMsg)
 
 	// Формируем сообщение для администраторов
-	adminMsg := fmt.Sprintf(`❌ Ошибка создания задачи
-Причина: %s
-
-📤 Отправитель: %s %s
-📝 Исходный текст: %s
-📋 Текст в Yougile: %s
-
-🔄 Количество попыток: %d
-⏰ Время создания: %s`,
-		reason,
-		v.OriginalSender.FirstName,
-		v.OriginalSender.LastName,
-		v.OriginalContent,
-		v.OriginalTask.Title,
-		v.RetryCount+1,
-		v.CreatedAt.Format("2006-01-02 15:04:05"))
+	adminMsg := b.messages.Render(notify.VerifyFailureAdmin, data)
 
 	// Отправляем сообщение всем администраторам
 	users := b.storage.GetUsers()
 	for _, user := range users {
 		if user.Role == models.RoleAdmin {
-			if _, err := b.bot.Send(&telebot.User{ID: user.TelegramID}, adminMsg); err != nil {
-				fmt.Printf("Ошибка отправки уведомления администратору %d: %v\n", user.TelegramID, err)
-			}
+			b.enqueue(user.TelegramID, adminMsg)
 		}
 	}
 }
@@ -0,0 +1,219 @@
+// Package bot содержит подключение наблюдателя за задачами Yougile и команды
+// администраторов для управления им: /watcher, /rescan, /fullscan, /notify.
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"yougile_bot4/internal/api"
+	"yougile_bot4/internal/models"
+	"yougile_bot4/internal/notify"
+	"yougile_bot4/internal/watcher"
+
+	"gopkg.in/telebot.v3"
+)
+
+const (
+	// fullScanName — имя разового задания перебора ключей по команде /fullscan.
+	fullScanName = "fullscan"
+	// fullScanRange — число ключей, проверяемых /fullscan по умолчанию.
+	fullScanRange = 100
+	// noWatcherText — ответ на команды, когда наблюдатель не подключён.
+	noWatcherText = "Наблюдатель за задачами не подключён."
+)
+
+// SetWatcher подключает наблюдатель за задачами, которым управляют команды администраторов.
+func (b *Bot) SetWatcher(w *watcher.Watcher) {
+	b.watcher = w
+}
+
+// NotifyNewTask рассылает уведомление о новой задаче в чаты её доски.
+func (b *Bot) NotifyNewTask(task models.Task) {
+	b.ResolveExecutors(&task)
+	b.SendTaskNotification(task, b.messages.Render(notify.NewTask, notify.NewTaskData{Task: task}))
+}
+
+// MainBoard возвращает основную отслеживаемую доску (пустую, если она не выбрана).
+func (b *Bot) MainBoard() models.BoardRoute {
+	for _, board := range b.WatchedBoards() {
+		if board.ID == "" {
+			return board
+		}
+	}
+	return models.BoardRoute{}
+}
+
+// boardOfTask возвращает отслеживаемую доску задачи (основную, если доска не отслеживается).
+func (b *Bot) boardOfTask(task models.Task) models.BoardRoute {
+	if r, ok := b.routeForTask(task.BoardID, task.ColumnID); ok {
+		return r
+	}
+	return b.MainBoard()
+}
+
+// handleWatcher показывает состояние наблюдателя; "/watcher stop" и
+// "/watcher start" останавливают и запускают плановые проверки.
+func (b *Bot) handleWatcher(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	if b.watcher == nil {
+		return c.Send(noWatcherText)
+	}
+	switch strings.TrimSpace(strings.TrimPrefix(c.Text(), "/watcher")) {
+	case "":
+	case "stop":
+		b.watcher.Stop()
+		return c.Send("⏹ Плановые проверки задач остановлены. Запустить снова: /watcher start")
+	case "start":
+		if err := b.watcher.Start(b.ctx); err != nil {
+			return c.Send(fmt.Sprintf("Не удалось запустить наблюдатель: %v", err))
+		}
+		return c.Send("▶️ Плановые проверки задач запущены.")
+	default:
+		return c.Send("Использование: /watcher [start|stop]")
+	}
+	return c.Send(formatWatcherStatus(b.watcher.Status()))
+}
+
+// formatWatcherStatus описывает состояние наблюдателя для администратора.
+func formatWatcherStatus(st watcher.Status) string {
+	var sb strings.Builder
+	if st.Running {
+		fmt.Fprintf(&sb, "👁 Наблюдатель работает, проверка каждые %s", st.Interval)
+	} else {
+		sb.WriteString("⏹ Плановые проверки остановлены")
+	}
+	if time.Now().Before(st.PausedUntil) {
+		fmt.Fprintf(&sb, "\n⏸ Приостановлен до %s: Yougile отклонил ключ", st.PausedUntil.Format("15:04"))
+	}
+	for _, s := range st.Sources {
+		name := s.Name
+		if s.Job {
+			name += " (задание)"
+		}
+		switch {
+		case s.Running:
+			fmt.Fprintf(&sb, "\n• %s: идёт с %s, найдено %d", name, s.LastStart.Format("15:04:05"), s.Seen)
+		case s.LastEnd.IsZero():
+			fmt.Fprintf(&sb, "\n• %s: ещё не запускался", name)
+		default:
+			fmt.Fprintf(&sb, "\n• %s: %s, задач %d, новых %d, уведомлений %d",
+				name, s.LastEnd.Format("15:04:05"), s.Seen, s.New, s.Notified)
+		}
+		if s.LastErr != "" && !s.Running {
+			fmt.Fprintf(&sb, "\n  ⚠️ %s", s.LastErr)
+		}
+	}
+	return sb.String()
+}
+
+// handleRescan немедленно опрашивает все плановые источники задач.
+func (b *Bot) handleRescan(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	if b.watcher == nil {
+		return c.Send(noWatcherText)
+	}
+	if err := b.watcher.RunOnce(b.ctx); err != nil {
+		log.Printf("rescan: error: %v", err)
+		b.ReportAPIError(err)
+		return c.Send(yougileErrorText(err, fmt.Sprintf("Ошибка при сканировании: %v", err)))
+	}
+	return c.Send("Рескан завершён. Подробности: /watcher")
+}
+
+// handleFullScan запускает фоновый перебор ключей ITS-<n> ("/fullscan [число]").
+// Пока находятся задачи — один запрос в секунду, после минуты без находок — раз в минуту.
+func (b *Bot) handleFullScan(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	if b.watcher == nil {
+		return c.Send(noWatcherText)
+	}
+	rng := fullScanRange
+	if arg := strings.TrimSpace(strings.TrimPrefix(c.Text(), "/fullscan")); arg != "" {
+		if v, err := strconv.Atoi(arg); err == nil && v > 0 {
+			rng = v
+		}
+	}
+	src := &watcher.KeySource{
+		Label:    fullScanName,
+		Client:   b.yougileClient,
+		Progress: b.storage,
+		Board:    b.MainBoard,
+		Prefix:   "ITS-",
+		Range:    rng,
+		Pace:     time.Second,
+		IdlePace: time.Minute,
+	}
+	if err := b.watcher.StartJob(b.ctx, src); err != nil {
+		return c.Send(fmt.Sprintf("Не удалось запустить fullscan: %v", err))
+	}
+	return c.Send(fmt.Sprintf("Full scan запущен (%d запросов)", rng))
+}
+
+// handleStopFullScan прерывает запущенный /fullscan.
+func (b *Bot) handleStopFullScan(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	if b.watcher == nil {
+		return c.Send(noWatcherText)
+	}
+	if err := b.watcher.StopJob(fullScanName); err != nil {
+		return c.Send(fmt.Sprintf("Не удалось остановить fullscan: %v", err))
+	}
+	return c.Send("Full scan остановлен")
+}
+
+// handleNotify помечает задачу как известную и рассылает уведомление о ней,
+// даже если бот уже видел задачу ("/notify <ключ_или_id>").
+func (b *Bot) handleNotify(c telebot.Context) error {
+	sender, exists := b.storage.GetUser(c.Sender().ID)
+	if !exists || sender.Role != models.RoleAdmin {
+		return c.Send("Команда доступна только администраторам.")
+	}
+	if b.watcher == nil {
+		return c.Send(noWatcherText)
+	}
+	key := strings.TrimSpace(strings.TrimPrefix(c.Text(), "/notify"))
+	if key == "" {
+		return c.Send("Использование: /notify <ключ_или_id> — пометить задачу как новую и разослать уведомление")
+	}
+	task, err := b.yougileClient.GetTaskByID(b.ctx, key)
+	if errors.Is(err, api.ErrNotFound) || (err == nil && task == nil) {
+		return c.Send("Задача не найдена через API Yougile.")
+	}
+	if err != nil {
+		log.Printf("notify: GetTaskByID(%s) error: %v", key, err)
+		b.ReportAPIError(err)
+		return c.Send(yougileErrorText(err, fmt.Sprintf("Ошибка при запросе задачи: %v", err)))
+	}
+	if len(watcher.Identities(*task)) == 0 {
+		return c.Send("Не удалось определить ключ задачи для отслеживания.")
+	}
+
+	_, notified := b.watcher.Handle(watcher.Found{Board: b.boardOfTask(*task), Task: *task})
+	if err := b.storage.SaveData(); err != nil {
+		log.Printf("notify: error saving storage: %v", err)
+	}
+	if task.Done {
+		return c.Send("Задача помечена как известная, но не отправлено уведомление — задача помечена как завершённая/удалённая.")
+	}
+	if !notified {
+		b.NotifyNewTask(*task)
+	}
+	return c.Send(fmt.Sprintf("Уведомление отправлено для %s", watcher.Identities(*task)[0]))
+}
@@ -0,0 +1,46 @@
+// Package bot содержит обработку событий Yougile, полученных через вебхуки.
+package bot
+
+import (
+	"log"
+
+	"yougile_bot4/internal/api"
+	"yougile_bot4/internal/models"
+)
+
+// findTask возвращает локальную копию задачи по ID Yougile (внешнему или числовому).
+func (b *Bot) findTask(id string) *models.Task {
+	for _, task := range b.storage.GetTasks() {
+		if outboxTaskID(task) == id {
+			return task
+		}
+	}
+	return nil
+}
+
+// TaskCompleted отмечает выполненной локальную копию задачи id, чтобы для неё
+// прекратилась пересылка комментариев. Возвращает false, если задача создана не ботом.
+func (b *Bot) TaskCompleted(id string) bool {
+	if !b.storage.CompleteTask(id) {
+		return false
+	}
+	if err := b.storage.SaveData(); err != nil {
+		log.Printf("Ошибка сохранения задачи %s: %v", id, err)
+	}
+	return true
+}
+
+// RelayTaskComments сразу пересылает новые комментарии задачи chatID автору заявки,
+// не дожидаясь периодической проверки.
+func (b *Bot) RelayTaskComments(chatID string) {
+	task := b.findTask(chatID)
+	if task == nil {
+		return
+	}
+	b.relayMu.Lock()
+	defer b.relayMu.Unlock()
+	b.relayTask(api.Background(b.ctx), task)
+	if err := b.storage.SaveData(); err != nil {
+		log.Printf("Ошибка сохранения состояния пересылки комментариев: %v", err)
+	}
+}
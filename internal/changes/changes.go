@@ -0,0 +1,142 @@
+// Package changes определяет изменения задач Yougile: сравнивает текущее
+// состояние задачи с последним сохранённым снимком и выдаёт типизированные
+// события (перемещение, выполнение, возобновление, срок, исполнители, название).
+package changes
+
+import (
+	"strconv"
+	"time"
+
+	"yougile_bot4/internal/models"
+)
+
+// seenRefresh — как часто обновляется время последней встречи неизменённой
+// задачи. Реже, чем каждый опрос, чтобы не перезаписывать снимки без изменений.
+const seenRefresh = time.Hour
+
+// Store хранит снимки задач (реализуется storage.Storage).
+type Store interface {
+	GetTaskSnapshot(key string) (models.TaskSnapshot, bool)
+	SetTaskSnapshot(snap models.TaskSnapshot)
+}
+
+// TaskKey возвращает ключ задачи для снимков: ExternalID, короткий ключ или
+// числовой ID. Пустая строка — у задачи нет идентификатора.
+func TaskKey(task models.Task) string {
+	switch {
+	case task.ExternalID != "":
+		return task.ExternalID
+	case task.Key != "":
+		return task.Key
+	case task.ID != 0:
+		return strconv.FormatInt(task.ID, 10)
+	}
+	return ""
+}
+
+// Snapshot формирует снимок текущего состояния задачи.
+func Snapshot(task models.Task, now time.Time) models.TaskSnapshot {
+	return models.TaskSnapshot{
+		Key:         TaskKey(task),
+		BoardID:     task.BoardID,
+		ColumnID:    task.ColumnID,
+		Title:       task.Title,
+		Done:        task.Done,
+		Archived:    task.Archived,
+		DueDate:     task.DueDate,
+		Assigned:    append([]string(nil), task.Assigned...),
+		HasDeadline: hasDeadline(task.HasDeadline, task.DueDate),
+		// автор заявки известен только боту, создавшему задачу
+		RequesterID: task.RequesterID,
+		SeenAt:      now,
+	}
+}
+
+// Diff возвращает изменения задачи task относительно снимка prev. Поля, которых
+// нет в ответе (пустая колонка, пустое название, срок), изменением не считаются.
+func Diff(prev models.TaskSnapshot, task models.Task) []models.TaskChange {
+	var result []models.TaskChange
+	add := func(kind models.ChangeKind) *models.TaskChange {
+		result = append(result, models.TaskChange{Kind: kind, Task: task, Prev: prev})
+		return &result[len(result)-1]
+	}
+	if task.ColumnID != "" && prev.ColumnID != "" && task.ColumnID != prev.ColumnID {
+		add(models.ChangeMoved)
+	}
+	switch {
+	case task.Done && !prev.Done:
+		add(models.ChangeCompleted)
+	case !task.Done && prev.Done:
+		add(models.ChangeReopened)
+	}
+	if hasDeadline(task.HasDeadline, task.DueDate) && hasDeadline(prev.HasDeadline, prev.DueDate) && !task.DueDate.Equal(prev.DueDate) {
+		add(models.ChangeDeadline)
+	}
+	if added := missing(task.Assigned, prev.Assigned); len(added) > 0 {
+		add(models.ChangeAssigned).Added = added
+	}
+	if task.Title != "" && prev.Title != "" && task.Title != prev.Title {
+		add(models.ChangeTitle)
+	}
+	return result
+}
+
+// Track сравнивает задачу с сохранённым снимком, сохраняет новый снимок и
+// возвращает изменения. Первая встреча задачи только запоминает её состояние.
+func Track(store Store, task models.Task, now time.Time) []models.TaskChange {
+	key := TaskKey(task)
+	if key == "" {
+		return nil
+	}
+	prev, ok := store.GetTaskSnapshot(key)
+	if !ok {
+		store.SetTaskSnapshot(Snapshot(task, now))
+		return nil
+	}
+	if task.BoardID == "" {
+		task.BoardID = prev.BoardID
+	}
+	if task.ColumnID == "" {
+		task.ColumnID = prev.ColumnID
+	}
+	if task.Title == "" {
+		task.Title = prev.Title
+	}
+	if task.RequesterID == 0 {
+		task.RequesterID = prev.RequesterID
+	}
+	if !hasDeadline(task.HasDeadline, task.DueDate) {
+		task.DueDate, task.HasDeadline = prev.DueDate, prev.HasDeadline
+	}
+	changes := Diff(prev, task)
+	if len(changes) > 0 || task.BoardID != prev.BoardID || task.Archived != prev.Archived || now.Sub(prev.SeenAt) >= seenRefresh {
+		snap := Snapshot(task, now)
+		snap.CheckedAt = prev.CheckedAt
+		store.SetTaskSnapshot(snap)
+	}
+	return changes
+}
+
+// hasDeadline сообщает, известен ли срок задачи: он пришёл в ответе или задан.
+// Снимки, сохранённые до появления HasDeadline, знают срок, только если он задан.
+func hasDeadline(present bool, due time.Time) bool {
+	return present || !due.IsZero()
+}
+
+// missing возвращает элементы list, которых нет в base.
+func missing(list, base []string) []string {
+	var result []string
+	for _, v := range list {
+		found := false
+		for _, b := range base {
+			if b == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, v)
+		}
+	}
+	return result
+}
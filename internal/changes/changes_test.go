@@ -0,0 +1,141 @@
+// Package changes содержит тесты определения изменений задач.
+package changes
+
+import (
+	"testing"
+	"time"
+
+	"yougile_bot4/internal/models"
+)
+
+// memStore — хранилище снимков в памяти для тестов.
+type memStore map[string]models.TaskSnapshot
+
+func (m memStore) GetTaskSnapshot(key string) (models.TaskSnapshot, bool) {
+	snap, ok := m[key]
+	return snap, ok
+}
+
+func (m memStore) SetTaskSnapshot(snap models.TaskSnapshot) {
+	m[snap.Key] = snap
+}
+
+func kinds(changes []models.TaskChange) []models.ChangeKind {
+	result := make([]models.ChangeKind, len(changes))
+	for i, ch := range changes {
+		result[i] = ch.Kind
+	}
+	return result
+}
+
+func TestTrackEmitsTypedChanges(t *testing.T) {
+	store := memStore{}
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	// срок пришёл в ответе пустым: у задачи его нет
+	task := models.Task{ExternalID: "t1", BoardID: "b1", ColumnID: "new", Title: "Протечка", Assigned: []string{"u1"}, HasDeadline: true}
+
+	if got := Track(store, task, now); len(got) != 0 {
+		t.Fatalf("first sighting must only record a snapshot, got %v", kinds(got))
+	}
+	if got := Track(store, task, now.Add(time.Minute)); len(got) != 0 {
+		t.Fatalf("unchanged task must not emit changes, got %v", kinds(got))
+	}
+
+	due := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)
+	task.ColumnID = "work"
+	task.DueDate = due
+	task.Assigned = []string{"u1", "u2"}
+	got := Track(store, task, now.Add(2*time.Minute))
+	want := []models.ChangeKind{models.ChangeMoved, models.ChangeDeadline, models.ChangeAssigned}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, kinds(got))
+	}
+	for i := range want {
+		if got[i].Kind != want[i] {
+			t.Fatalf("expected %v, got %v", want, kinds(got))
+		}
+	}
+	if got[0].Prev.ColumnID != "new" || got[0].Task.ColumnID != "work" {
+		t.Fatalf("moved change must carry both columns: %+v", got[0])
+	}
+	if len(got[2].Added) != 1 || got[2].Added[0] != "u2" {
+		t.Fatalf("expected only the new assignee, got %v", got[2].Added)
+	}
+
+	// Частичные данные (без колонки и названия) не считаются изменением
+	partial := models.Task{ExternalID: "t1", Done: true, DueDate: due, Assigned: []string{"u1", "u2"}}
+	got = Track(store, partial, now.Add(3*time.Minute))
+	if len(got) != 1 || got[0].Kind != models.ChangeCompleted {
+		t.Fatalf("expected completed only, got %v", kinds(got))
+	}
+	if snap := store["t1"]; snap.ColumnID != "work" || snap.BoardID != "b1" || snap.Title != "Протечка" || !snap.Done {
+		t.Fatalf("snapshot must keep known fields: %+v", snap)
+	}
+
+	partial.Done = false
+	partial.Title = "Протечка на 3 этаже"
+	got = Track(store, partial, now.Add(4*time.Minute))
+	if len(got) != 2 || got[0].Kind != models.ChangeReopened || got[1].Kind != models.ChangeTitle {
+		t.Fatalf("expected reopened and title, got %v", kinds(got))
+	}
+}
+
+func TestDiffIgnoresMissingDeadline(t *testing.T) {
+	due := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)
+	prev := Snapshot(models.Task{ExternalID: "t1", ColumnID: "work", DueDate: due, HasDeadline: true}, time.Now())
+
+	// в ответе списка или частичном событии вебхука срока нет
+	if got := Diff(prev, models.Task{ExternalID: "t1", ColumnID: "work"}); len(got) != 0 {
+		t.Fatalf("missing deadline must not be reported as removed, got %v", kinds(got))
+	}
+	// снимок без срока не даёт считать срок из ответа новым
+	if got := Diff(Snapshot(models.Task{ExternalID: "t1"}, time.Now()), models.Task{ExternalID: "t1", DueDate: due, HasDeadline: true}); len(got) != 0 {
+		t.Fatalf("deadline unknown in the snapshot must not be reported as set, got %v", kinds(got))
+	}
+	// срок, снятый в Yougile, приходит в ответе пустым
+	if got := Diff(prev, models.Task{ExternalID: "t1", HasDeadline: true}); len(got) != 1 || got[0].Kind != models.ChangeDeadline {
+		t.Fatalf("expected removed deadline, got %v", kinds(got))
+	}
+
+	store := memStore{"t1": prev}
+	Track(store, models.Task{ExternalID: "t1", ColumnID: "work"}, time.Now().Add(2*seenRefresh))
+	if snap := store["t1"]; !snap.HasDeadline || !snap.DueDate.Equal(due) {
+		t.Fatalf("snapshot must keep the known deadline: %+v", snap)
+	}
+	if got := Track(store, models.Task{ExternalID: "t1", ColumnID: "work", DueDate: due, HasDeadline: true}, time.Now()); len(got) != 0 {
+		t.Fatalf("deadline seen again must not be reported, got %v", kinds(got))
+	}
+}
+
+func TestTrackRefreshesSeenAtRarely(t *testing.T) {
+	store := memStore{}
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	task := models.Task{Key: "ITS-7", Title: "Картридж"}
+	Track(store, task, now)
+	Track(store, task, now.Add(10*time.Minute))
+	if !store["ITS-7"].SeenAt.Equal(now) {
+		t.Fatalf("unchanged task must not rewrite its snapshot every poll")
+	}
+	Track(store, task, now.Add(2*time.Hour))
+	if !store["ITS-7"].SeenAt.Equal(now.Add(2 * time.Hour)) {
+		t.Fatalf("SeenAt must be refreshed after an hour")
+	}
+	if got := Track(store, models.Task{}, now); got != nil {
+		t.Fatalf("task without identifiers must be ignored")
+	}
+}
+
+func TestTrackKeepsRequester(t *testing.T) {
+	store := memStore{}
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	checked := now.Add(-time.Minute)
+	store.SetTaskSnapshot(models.TaskSnapshot{Key: "t1", ColumnID: "new", Title: "Окно", RequesterID: 42, CheckedAt: checked, SeenAt: now})
+
+	got := Track(store, models.Task{ExternalID: "t1", ColumnID: "done", Done: true}, now.Add(time.Minute))
+	if len(got) != 2 || got[0].Prev.RequesterID != 42 || got[1].Task.RequesterID != 42 {
+		t.Fatalf("changes must carry the requester: %+v", got)
+	}
+	if snap := store["t1"]; snap.RequesterID != 42 || !snap.CheckedAt.Equal(checked) {
+		t.Fatalf("snapshot lost requester data: %+v", snap)
+	}
+}
@@ -10,13 +10,21 @@ import (
 // Metrics хранит метрики и счётчики, собираемые приложением.
 // Поля изменяются атомарно или через mutex в зависимости от типа.
 type Metrics struct {
-	ActiveUsers    int64
-	TasksCreated   int64
-	AdminActions   int64
-	APIRequests    int64
-	APIErrors      int64
-	AverageLatency time.Duration
-	mu             sync.RWMutex
+	ActiveUsers      int64
+	TasksCreated     int64
+	AdminActions     int64
+	APIRequests      int64
+	APIErrors        int64
+	APIRateBudget    int64 // доступные запросы в корзине ограничителя Yougile API
+	APIRateWaits     int64 // запросы, ожидавшие ограничителя
+	APIRateLimited   int64 // ответы 429 от Yougile API
+	SendQueueDepth   int64 // сообщения Telegram, ожидающие отправки
+	SendRetries      int64 // повторные попытки отправки сообщений
+	SendFailures     int64 // сообщения, отправить которые не удалось
+	SendRateLimited  int64 // ответы 429 от Telegram
+	SendChatsRemoved int64 // чаты, удалённые из рассылки после ответа 403
+	AverageLatency   time.Duration
+	mu               sync.RWMutex
 }
 
 // NewMetrics создает и возвращает новый объект Metrics.
@@ -42,6 +50,30 @@ func (m *Metrics) IncAPIRequests() { atomic.AddInt64(&m.APIRequests, 1) }
 // IncAPIErrors увеличивает счетчик ошибок API
 func (m *Metrics) IncAPIErrors() { atomic.AddInt64(&m.APIErrors, 1) }
 
+// SetAPIRateBudget сохраняет текущий бюджет ограничителя запросов
+func (m *Metrics) SetAPIRateBudget(n int64) { atomic.StoreInt64(&m.APIRateBudget, n) }
+
+// IncAPIRateWaits увеличивает счетчик запросов, ожидавших ограничителя
+func (m *Metrics) IncAPIRateWaits() { atomic.AddInt64(&m.APIRateWaits, 1) }
+
+// IncAPIRateLimited увеличивает счетчик ответов 429
+func (m *Metrics) IncAPIRateLimited() { atomic.AddInt64(&m.APIRateLimited, 1) }
+
+// SetSendQueueDepth сохраняет число сообщений в очереди отправки
+func (m *Metrics) SetSendQueueDepth(n int64) { atomic.StoreInt64(&m.SendQueueDepth, n) }
+
+// IncSendRetries увеличивает счетчик повторных попыток отправки сообщений
+func (m *Metrics) IncSendRetries() { atomic.AddInt64(&m.SendRetries, 1) }
+
+// AddSendFailures увеличивает счетчик неотправленных сообщений на n
+func (m *Metrics) AddSendFailures(n int64) { atomic.AddInt64(&m.SendFailures, n) }
+
+// IncSendRateLimited увеличивает счетчик ответов 429 от Telegram
+func (m *Metrics) IncSendRateLimited() { atomic.AddInt64(&m.SendRateLimited, 1) }
+
+// IncSendChatsRemoved увеличивает счетчик чатов, удалённых из рассылки
+func (m *Metrics) IncSendChatsRemoved() { atomic.AddInt64(&m.SendChatsRemoved, 1) }
+
 // UpdateLatency обновляет среднее время ответа
 func (m *Metrics) UpdateLatency(d time.Duration) {
 	m.mu.Lock()
@@ -66,6 +98,14 @@ func (m *Metrics) GetStats() map[string]interface{} {
 		"admin_actions":   atomic.LoadInt64(&m.AdminActions),
 		"api_requests":    atomic.LoadInt64(&m.APIRequests),
 		"api_errors":      atomic.LoadInt64(&m.APIErrors),
+		"api_rate_budget": atomic.LoadInt64(&m.APIRateBudget),
+		"api_rate_waits":  atomic.LoadInt64(&m.APIRateWaits),
+		"api_rate_429":    atomic.LoadInt64(&m.APIRateLimited),
+		"send_queue":      atomic.LoadInt64(&m.SendQueueDepth),
+		"send_retries":    atomic.LoadInt64(&m.SendRetries),
+		"send_failures":   atomic.LoadInt64(&m.SendFailures),
+		"send_429":        atomic.LoadInt64(&m.SendRateLimited),
+		"send_chats_gone": atomic.LoadInt64(&m.SendChatsRemoved),
 		"average_latency": m.AverageLatency.String(),
 	}
 }
@@ -0,0 +1,18 @@
+// Package models содержит структуры авторизации в Yougile: компании и ключи API.
+package models
+
+// Company — компания, доступная пользователю Yougile по логину и паролю.
+type Company struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	IsAdmin bool   `json:"isAdmin"`
+}
+
+// AuthKey — ключ API Yougile, выпущенный для компании.
+type AuthKey struct {
+	Key       string `json:"key"`
+	CompanyID string `json:"companyId"`
+	// Timestamp — время создания ключа (Unix, миллисекунды).
+	Timestamp float64 `json:"timestamp"`
+	Deleted   bool    `json:"deleted,omitempty"`
+}
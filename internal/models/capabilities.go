@@ -0,0 +1,17 @@
+// Package models содержит описание возможностей API Yougile, обнаруженных клиентом.
+package models
+
+import "time"
+
+// ListCapability описывает рабочий способ получения списка задач для конкретной
+// области (доска/колонка), найденный клиентом при опросе API.
+type ListCapability struct {
+	Strategy     string    `json:"strategy"`            // имя стратегии запроса (например task_list_get)
+	BoardID      string    `json:"board_id,omitempty"`  // доска, для которой выполнялся опрос
+	ColumnID     string    `json:"column_id,omitempty"` // колонка, для которой выполнялся опрос
+	DiscoveredAt time.Time `json:"discovered_at"`       // время обнаружения
+	Probes       int       `json:"probes"`              // сколько запросов потребовалось для обнаружения
+}
+
+// APICapabilities хранит обнаруженные возможности API, индексированные по ключу области.
+type APICapabilities map[string]ListCapability
@@ -0,0 +1,27 @@
+// Package models содержит описание сообщений чата задачи Yougile и состояния их пересылки.
+package models
+
+import "time"
+
+// ChatMessage — сообщение чата Yougile (у задачи чат совпадает с ID задачи).
+type ChatMessage struct {
+	ID         int64  `json:"id"` // ID сообщения, он же время создания в миллисекундах
+	FromUserID string `json:"fromUserId"`
+	Text       string `json:"text"`
+	TextHTML   string `json:"textHtml,omitempty"`
+	Label      string `json:"label,omitempty"`
+	Deleted    bool   `json:"deleted,omitempty"`
+}
+
+// CreatedAt возвращает время создания сообщения.
+func (m ChatMessage) CreatedAt() time.Time {
+	return time.UnixMilli(m.ID)
+}
+
+// RelayReply связывает сообщение бота с пересланным комментарием и чатом задачи,
+// чтобы ответ пользователя на это сообщение ушёл в Yougile.
+type RelayReply struct {
+	TaskID string    `json:"task_id"` // ID задачи (чата) в Yougile
+	Title  string    `json:"title"`
+	SentAt time.Time `json:"sent_at"`
+}
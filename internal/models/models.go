@@ -2,7 +2,10 @@
 // задачи, пользователи, комментарии и конфигурация.
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // TaskStatus представляет статус задачи.
 // Возможные значения определены константами ниже (TaskStatusNew, TaskStatusInWork и т.д.).
@@ -64,12 +67,24 @@ type Task struct {
 	Description string     `json:"description"`
 	Status      TaskStatus `json:"status"`
 	Done        bool       `json:"done"`
+	Archived    bool       `json:"archived,omitempty"`
+	Deleted     bool       `json:"deleted,omitempty"`
 	ParentID    int64      `json:"parent_id,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 	DueDate     time.Time  `json:"due_date,omitempty"`
 	Priority    int        `json:"priority"`
-	Assignee    string     `json:"assignee,omitempty"`
+	// HasDeadline — срок пришёл в ответе Yougile (нулевой DueDate тогда означает,
+	// что срок снят, а не что его нет в ответе).
+	HasDeadline bool `json:"has_deadline,omitempty"`
+	// Assignee — имена исполнителей для отображения (см. Assigned).
+	Assignee string `json:"assignee,omitempty"`
+	// Assigned — идентификаторы исполнителей в Yougile (assigned).
+	Assigned []string `json:"assigned,omitempty"`
+	// RequesterID — Telegram ID пользователя, оставившего заявку.
+	RequesterID int64 `json:"requester_id,omitempty"`
+	// Stickers — значения стикеров: ID стикера → ID состояния или строка свободного поля.
+	Stickers map[string]string `json:"stickers,omitempty"`
 	// ColumnID — идентификатор колонки (columnId) для создания/перемещения задачи.
 	ColumnID    string    `json:"columnId,omitempty"`
 	Labels      []string  `json:"labels,omitempty"`
@@ -77,6 +92,8 @@ type Task struct {
 	TimeSpent   float64   `json:"time_spent,omitempty"` // затраченное время в часах
 	Comments    []Comment `json:"comments,omitempty"`
 	Attachments []string  `json:"attachments,omitempty"` // URLs вложений
+	// Extra — поля ответа Yougile, не отображённые в модель (заполняет api.DecodeTask).
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // UserRole определяет роль пользователя в системе (админ или пользователь).
@@ -102,6 +119,8 @@ type User struct {
 	Role            UserRole `json:"role"`
 	Approved        bool     `json:"approved"`
 	AddressChange   bool     `json:"address_change"` // Ожидает подтверждения изменения адреса
+	// MuteTaskUpdates отключает личные сообщения об изменениях заявок пользователя.
+	MuteTaskUpdates bool `json:"mute_task_updates,omitempty"`
 }
 
 // PendingRequest представляет запрос пользователя, требующий подтверждения администратора.
@@ -147,4 +166,13 @@ type Config struct {
 	RetryCount      int
 	RetryWait       time.Duration
 	MaxRetryElapsed time.Duration
+	// RateLimit — лимит запросов к Yougile в минуту (0 — без ограничения)
+	RateLimit int
+	// Вебхуки Yougile: публичный адрес подписки (пустой — только опрос),
+	// адрес прослушивания встроенного сервера и секрет, передаваемый в адресе
+	WebhookURL    string
+	WebhookListen string
+	WebhookSecret string
+	// ReconcileInterval — интервал сверки опросом при работающих вебхуках
+	ReconcileInterval time.Duration
 }
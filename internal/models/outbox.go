@@ -0,0 +1,43 @@
+// Package models содержит описание отложенных операций с Yougile (outbox).
+package models
+
+import "time"
+
+// OutboxKind задаёт тип отложенной операции.
+type OutboxKind string
+
+const (
+	// OutboxCreateTask — создание задачи.
+	OutboxCreateTask OutboxKind = "create_task"
+	// OutboxAddComment — добавление комментария к задаче.
+	OutboxAddComment OutboxKind = "add_comment"
+	// OutboxUploadAttachment — загрузка вложения в задачу.
+	OutboxUploadAttachment OutboxKind = "upload_attachment"
+)
+
+// OutboxItem — операция, которую не удалось выполнить из-за недоступности Yougile.
+// Операции сохраняются на диск и повторяются строго в порядке поступления.
+type OutboxItem struct {
+	ID          int64      `json:"id"` // порядковый номер в очереди
+	Kind        OutboxKind `json:"kind"`
+	RequesterID int64      `json:"requester_id"` // Telegram ID пользователя, которого уведомить о результате
+	CreatedAt   time.Time  `json:"created_at"`
+	Attempts    int        `json:"attempts"`
+	LastError   string     `json:"last_error,omitempty"`
+
+	Task       *Task       `json:"task,omitempty"`       // для create_task
+	Comment    *Comment    `json:"comment,omitempty"`    // для add_comment
+	Attachment *Attachment `json:"attachment,omitempty"` // для upload_attachment
+
+	// TaskID — идентификатор задачи в Yougile для комментария или вложения.
+	TaskID string `json:"task_id,omitempty"`
+	// TaskRef — номер операции create_task в очереди, результатом которой станет
+	// TaskID (комментарий к задаче, ещё не созданной в Yougile).
+	TaskRef int64 `json:"task_ref,omitempty"`
+	// FilePath — путь к сохранённому содержимому вложения или фотографии задачи.
+	FilePath string `json:"file_path,omitempty"`
+	// Content — исходный текст пользователя, нужен для проверки созданной задачи.
+	Content string `json:"content,omitempty"`
+	// HasImage — задача создавалась с фотографией.
+	HasImage bool `json:"has_image,omitempty"`
+}
@@ -0,0 +1,24 @@
+// Package models содержит описание правил маршрутизации уведомлений о задачах по чатам.
+package models
+
+// ChatRule — правило подписки чата на уведомления о задачах. Пустое условие
+// не ограничивает; все заданные условия должны выполняться, внутри условия
+// достаточно совпадения с любым из значений. Чат без правил получает все
+// уведомления, чат с правилами — только подходящие хотя бы под одно из них.
+type ChatRule struct {
+	ChatID int64 `json:"chat_id"`
+	// Boards — ID досок Yougile.
+	Boards []string `json:"boards,omitempty"`
+	// Columns — ID колонок.
+	Columns []string `json:"columns,omitempty"`
+	// Priorities — приоритеты задачи (1 — высокий, 2 — средний, 0 — обычный).
+	Priorities []int `json:"priorities,omitempty"`
+	// Labels — метки задачи или названия значений её стикеров.
+	Labels []string `json:"labels,omitempty"`
+	// Buildings — здания: значение стикера здания или адрес автора заявки.
+	Buildings []string `json:"buildings,omitempty"`
+	// Pattern — регулярное выражение для названия и описания (без учёта регистра).
+	Pattern string `json:"pattern,omitempty"`
+	// Done — только выполненные (true) или только незавершённые (false) задачи.
+	Done *bool `json:"done,omitempty"`
+}
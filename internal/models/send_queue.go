@@ -0,0 +1,25 @@
+// Package models содержит описание сообщений бота в очереди отправки в Telegram.
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutgoingMessage — сообщение, которое бот отправляет по своей инициативе
+// (уведомления, ответы администраторов, пересланные комментарии). Сообщения
+// сохраняются на диск и отправляются с учётом ограничений Telegram.
+type OutgoingMessage struct {
+	ID     int64  `json:"id"` // порядковый номер в очереди
+	ChatID int64  `json:"chat_id"`
+	Text   string `json:"text"`
+	// Markup — клавиатура сообщения (telebot.ReplyMarkup в JSON).
+	Markup json.RawMessage `json:"markup,omitempty"`
+	// Relay — пересланный комментарий: после отправки ответ на сообщение уйдёт в чат задачи.
+	Relay     *RelayReply `json:"relay,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	Attempts  int         `json:"attempts,omitempty"`
+	// NotBefore — раньше этого времени сообщение не отправляется (повтор или 429).
+	NotBefore time.Time `json:"not_before,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+}
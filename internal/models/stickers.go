@@ -0,0 +1,31 @@
+// Package models содержит описание стикеров (пользовательских полей) Yougile.
+package models
+
+// StickerState — состояние стикера с набором значений (например, «Корпус 1»).
+type StickerState struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Color   string `json:"color,omitempty"`
+	Deleted bool   `json:"deleted,omitempty"`
+}
+
+// Sticker — текстовый стикер Yougile. Стикер без состояний — свободное поле,
+// в задачу записывается произвольная строка.
+type Sticker struct {
+	ID      string         `json:"id"`
+	Name    string         `json:"name"`
+	Deleted bool           `json:"deleted,omitempty"`
+	States  []StickerState `json:"states,omitempty"`
+}
+
+// Источники значений стикеров задачи.
+const (
+	// StickerSourceBuilding — адрес здания пользователя (User.BuildingAddress).
+	StickerSourceBuilding = "building"
+	// StickerSourceRoom — номер кабинета пользователя (User.RoomNumber).
+	StickerSourceRoom = "room"
+	// StickerSourcePosition — должность пользователя (User.Position).
+	StickerSourcePosition = "position"
+	// StickerSourceStepPrefix — префикс источника «ответ на шаг конструктора»: "step:<ключ шага>".
+	StickerSourceStepPrefix = "step:"
+)
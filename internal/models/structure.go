@@ -0,0 +1,76 @@
+// Package models содержит описание структуры Yougile: проекты, доски и колонки.
+package models
+
+import "time"
+
+// Project — проект Yougile.
+type Project struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Deleted bool   `json:"deleted,omitempty"`
+}
+
+// Board — доска Yougile внутри проекта.
+type Board struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	ProjectID string `json:"projectId"`
+	Deleted   bool   `json:"deleted,omitempty"`
+}
+
+// Column — колонка доски Yougile.
+type Column struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	BoardID string `json:"boardId"`
+	Color   int    `json:"color,omitempty"`
+	Deleted bool   `json:"deleted,omitempty"`
+}
+
+// TargetColumn — колонка для новых задач, выбранная администратором в боте.
+// Названия сохраняются для отображения, чтобы не запрашивать их у API.
+type TargetColumn struct {
+	ProjectID    string    `json:"project_id,omitempty"`
+	ProjectTitle string    `json:"project_title,omitempty"`
+	BoardID      string    `json:"board_id"`
+	BoardTitle   string    `json:"board_title,omitempty"`
+	ColumnID     string    `json:"column_id"`
+	ColumnTitle  string    `json:"column_title,omitempty"`
+	UpdatedBy    int64     `json:"updated_by,omitempty"` // Telegram ID администратора
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// BoardRoute — дополнительная отслеживаемая доска (например, АХО или охрана):
+// куда создаются задачи этого направления и кого уведомлять о новых задачах.
+// Основная доска задаётся TargetColumn (или YOUGILE_BOARD) и маршрутом не является.
+type BoardRoute struct {
+	// ID — короткое имя направления для команд (it, aho, security)
+	ID string `json:"id"`
+	// Title — отображаемое название направления
+	Title    string `json:"title"`
+	BoardID  string `json:"board_id"`
+	ColumnID string `json:"column_id,omitempty"`
+	// ChatIDs — чаты уведомлений о новых задачах доски; пусто — общие чаты бота
+	ChatIDs []int64 `json:"chat_ids,omitempty"`
+	// Answers — ответы конструктора задач вида "шаг:вариант", при которых
+	// задача создаётся на этой доске
+	Answers []string `json:"answers,omitempty"`
+}
+
+// Settings — настройки бота, изменяемые администраторами через Telegram.
+type Settings struct {
+	TargetColumn *TargetColumn `json:"target_column,omitempty"`
+	// Boards — дополнительные отслеживаемые доски
+	Boards []BoardRoute `json:"boards,omitempty"`
+	// UserLinks — связи пользователей Telegram с сотрудниками Yougile, ключ — Telegram ID
+	UserLinks map[int64]UserLink `json:"user_links,omitempty"`
+	// StickerMap — соответствие источника значения (см. StickerSource*) и ID стикера доски
+	StickerMap map[string]string `json:"sticker_map,omitempty"`
+	// StickerCreateStates разрешает добавлять стикерам доски недостающие состояния
+	// (например, новый корпус); без него такое значение в стикер не записывается
+	StickerCreateStates bool `json:"sticker_create_states,omitempty"`
+	// DefaultExecutor — Telegram ID исполнителя, назначаемого на новые задачи (0 — не назначать)
+	DefaultExecutor int64 `json:"default_executor,omitempty"`
+	// ChatRules — правила подписки чатов на уведомления о задачах
+	ChatRules []ChatRule `json:"chat_rules,omitempty"`
+}
@@ -0,0 +1,54 @@
+// Package models содержит снимки состояния задач Yougile и изменения между ними.
+package models
+
+import "time"
+
+// TaskSnapshot — последнее увиденное ботом состояние задачи, по которому
+// определяются её изменения.
+type TaskSnapshot struct {
+	// Key — ключ задачи: ExternalID, короткий ключ или числовой ID.
+	Key      string    `json:"key"`
+	BoardID  string    `json:"board_id,omitempty"`
+	ColumnID string    `json:"column_id,omitempty"`
+	Title    string    `json:"title"`
+	Done     bool      `json:"done,omitempty"`
+	Archived bool      `json:"archived,omitempty"`
+	DueDate  time.Time `json:"due_date,omitempty"`
+	Assigned []string  `json:"assigned,omitempty"`
+	// HasDeadline — срок задачи известен (см. models.Task.HasDeadline).
+	HasDeadline bool `json:"has_deadline,omitempty"`
+	// RequesterID — Telegram ID автора заявки, если задачу создал бот.
+	RequesterID int64 `json:"requester_id,omitempty"`
+	// CheckedAt — когда задача автора последний раз запрашивалась отдельно
+	// (вне отслеживаемой колонки).
+	CheckedAt time.Time `json:"checked_at,omitempty"`
+	// SeenAt — когда задача последний раз встречалась боту (для очистки старых снимков).
+	SeenAt time.Time `json:"seen_at"`
+}
+
+// ChangeKind — тип изменения задачи.
+type ChangeKind string
+
+const (
+	// ChangeMoved — задача перемещена в другую колонку.
+	ChangeMoved ChangeKind = "moved"
+	// ChangeCompleted — задача отмечена выполненной.
+	ChangeCompleted ChangeKind = "completed"
+	// ChangeReopened — с задачи снята отметка о выполнении.
+	ChangeReopened ChangeKind = "reopened"
+	// ChangeDeadline — срок задачи установлен, изменён или снят.
+	ChangeDeadline ChangeKind = "deadline"
+	// ChangeAssigned — задаче назначены новые исполнители.
+	ChangeAssigned ChangeKind = "assigned"
+	// ChangeTitle — изменено название задачи.
+	ChangeTitle ChangeKind = "title"
+)
+
+// TaskChange — изменение задачи между прошлым снимком Prev и текущим состоянием Task.
+type TaskChange struct {
+	Kind ChangeKind
+	Task Task
+	Prev TaskSnapshot
+	// Added — новые исполнители (только для ChangeAssigned).
+	Added []string
+}
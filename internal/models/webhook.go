@@ -0,0 +1,15 @@
+// Package models содержит описание подписок Yougile на события компании.
+package models
+
+// Webhook — подписка Yougile на события компании (вебхук).
+type Webhook struct {
+	ID       string `json:"id"`
+	URL      string `json:"url"`
+	Event    string `json:"event"` // <тип_объекта>-<событие> или регулярное выражение
+	Deleted  bool   `json:"deleted,omitempty"`
+	Disabled bool   `json:"disabled,omitempty"`
+	// LastSuccess — время последнего успешного вызова (Unix, секунды).
+	LastSuccess float64 `json:"lastSuccess,omitempty"`
+	// FailuresSinceLastSuccess — число неуспешных вызовов подряд.
+	FailuresSinceLastSuccess int `json:"failuresSinceLastSuccess"`
+}
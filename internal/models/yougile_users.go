@@ -0,0 +1,35 @@
+// Package models содержит описание сотрудников Yougile и их связи с пользователями бота.
+package models
+
+import "time"
+
+// YougileUser — сотрудник компании в Yougile (UserDto).
+type YougileUser struct {
+	ID       string `json:"id"`
+	Email    string `json:"email"`
+	RealName string `json:"realName"`
+	IsAdmin  bool   `json:"isAdmin,omitempty"`
+	Status   string `json:"status,omitempty"` // online/offline
+}
+
+// DisplayName возвращает ФИО сотрудника, а если оно не заполнено — почту.
+func (u YougileUser) DisplayName() string {
+	if u.RealName != "" {
+		return u.RealName
+	}
+	if u.Email != "" {
+		return u.Email
+	}
+	return u.ID
+}
+
+// UserLink связывает пользователя Telegram с сотрудником Yougile.
+// Задаётся администратором; используется для назначения исполнителей задач.
+type UserLink struct {
+	TelegramID    int64     `json:"telegram_id"`
+	YougileUserID string    `json:"yougile_user_id"`
+	Email         string    `json:"email,omitempty"`
+	RealName      string    `json:"real_name,omitempty"`
+	LinkedBy      int64     `json:"linked_by,omitempty"` // Telegram ID администратора
+	LinkedAt      time.Time `json:"linked_at"`
+}
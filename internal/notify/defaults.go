@@ -0,0 +1,80 @@
+// Package notify содержит встроенные шаблоны уведомлений и данные для их предпросмотра.
+package notify
+
+import (
+	"time"
+
+	"yougile_bot4/internal/models"
+)
+
+// defaults — встроенные шаблоны; файл <имя>.tmpl в каталоге шаблонов заменяет шаблон с тем же именем.
+var defaults = map[string]string{
+	NewTask: `{{if .Task.Done}}✅{{else}}🔵{{end}} Новая задача
+📎 {{.Task.Title}}
+🏷 {{if eq .Task.Priority 1}}⚡️ Высокий{{else if eq .Task.Priority 2}}⭐️ Средний{{else}}📌 Обычный{{end}}
+{{- with date .Task.DueDate}}
+📅 Срок: {{.}}{{end}}
+{{- with .Task.Assignee}}
+👤 Исполнитель: {{.}}{{end}}
+{{- with .Task.Description}}
+
+📝 {{truncate 200 .}}{{end}}`,
+
+	TaskChange: `{{$t := printf "%s «%s»" .Key (truncate 80 .Task.Title)}}
+{{- if eq .Kind "moved"}}➡️ {{$t}}: {{.From}} → {{.To}}
+{{- else if eq .Kind "completed"}}✅ {{$t}} выполнена
+{{- else if eq .Kind "reopened"}}🔄 {{$t}} снова в работе
+{{- else if eq .Kind "deadline"}}📅 {{$t}}: {{with date .Task.DueDate}}срок {{.}}{{else}}срок снят{{end}}
+{{- else if eq .Kind "assigned"}}👤 {{$t}}: назначены {{join .Added ", "}}
+{{- else if eq .Kind "title"}}✏️ {{$t}}: название изменено (было «{{truncate 80 .Prev.Title}}»)
+{{- end}}`,
+
+	VerifySuccess: `✅ Задача успешно создана в Yougile:
+📎 {{.Task.Title}}
+🆔 {{.TaskID}}`,
+
+	VerifyFailure: `Произошла проблема с созданием задачи: {{.Reason}}
+Пожалуйста, обратитесь к администратору.`,
+
+	VerifyFailureAdmin: `❌ Ошибка создания задачи
+Причина: {{.Reason}}
+
+📤 Отправитель: {{.Sender.FirstName}} {{.Sender.LastName}}
+📝 Исходный текст: {{.Content}}
+📋 Текст в Yougile: {{.Task.Title}}
+
+🔄 Количество попыток: {{.Attempts}}
+⏰ Время создания: {{datetime .CreatedAt}}`,
+
+	RegistrationRequest: `Новая заявка на регистрацию:
+👤 {{.User.FirstName}} {{.User.LastName}} ({{.User.TelegramID}})
+💼 Должность: {{.User.Position}}`,
+}
+
+// Sample возвращает пример данных шаблона name: по нему шаблоны проверяются
+// при загрузке и показываются администратору командой /template.
+func Sample(name string) interface{} {
+	created := time.Date(2024, 5, 10, 9, 30, 0, 0, time.Local)
+	task := models.Task{
+		Key:         "ITS-42",
+		Title:       "Протечка в кабинете 101",
+		Description: "С потолка капает вода рядом с окном, нужно перекрыть стояк и вызвать сантехника. Мебель уже отодвинули, под протечку поставили ведро.",
+		Priority:    1,
+		DueDate:     created.AddDate(0, 0, 3),
+		Assignee:    "Иван Петров",
+		CreatedAt:   created,
+	}
+	user := models.User{TelegramID: 123456789, FirstName: "Анна", LastName: "Смирнова", Position: "Бухгалтер"}
+	switch name {
+	case NewTask:
+		return NewTaskData{Task: task}
+	case TaskChange:
+		prev := models.TaskSnapshot{Key: task.Key, ColumnID: "c-new", Title: "Протечка"}
+		return TaskChangeData{Kind: models.ChangeMoved, Key: task.Key, Task: task, Prev: prev, From: "«Новые»", To: "«В работе»"}
+	case VerifySuccess, VerifyFailure, VerifyFailureAdmin:
+		return VerifyData{Task: task, TaskID: task.Key, Sender: user, Content: "В кабинете 101 протечка", Reason: "задача не найдена после создания", Attempts: 2, CreatedAt: created}
+	case RegistrationRequest:
+		return RegistrationData{User: user}
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+// Package notify формирует тексты уведомлений бота по шаблонам text/template:
+// встроенным или заданным администратором файлами <имя>.tmpl в каталоге
+// данных. Шаблоны проверяются при загрузке на примере данных; ошибочный файл
+// не применяется, вместо него используется встроенный шаблон.
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"yougile_bot4/internal/models"
+)
+
+// Имена шаблонов (и файлов <имя>.tmpl).
+const (
+	// NewTask — уведомление о новой задаче в чаты доски (NewTaskData).
+	NewTask = "new_task"
+	// TaskChange — сообщение об изменении задачи в чаты доски (TaskChangeData).
+	TaskChange = "task_change"
+	// VerifySuccess — подтверждение автору, что задача создана в Yougile (VerifyData).
+	VerifySuccess = "verify_success"
+	// VerifyFailure — сообщение автору о неудачном создании задачи (VerifyData).
+	VerifyFailure = "verify_failure"
+	// VerifyFailureAdmin — отчёт администраторам о неудачном создании задачи (VerifyData).
+	VerifyFailureAdmin = "verify_failure_admin"
+	// RegistrationRequest — заявка на регистрацию для администраторов (RegistrationData).
+	RegistrationRequest = "registration_request"
+)
+
+// DefaultDir — каталог шаблонов по умолчанию.
+const DefaultDir = "data/templates"
+
+// NewTaskData — данные шаблона NewTask. Имена исполнителей (Task.Assignee)
+// заполняются заранее.
+type NewTaskData struct {
+	Task models.Task
+}
+
+// TaskChangeData — данные шаблона TaskChange. From и To (названия колонок)
+// заполняются для перемещения, Added (имена исполнителей) — для назначения.
+type TaskChangeData struct {
+	Kind  models.ChangeKind
+	Key   string // короткий ключ или ID задачи
+	Task  models.Task
+	Prev  models.TaskSnapshot
+	From  string
+	To    string
+	Added []string
+}
+
+// VerifyData — данные шаблонов проверки созданной задачи.
+type VerifyData struct {
+	Task      models.Task // задача в Yougile (или отправленная, если не найдена)
+	TaskID    string
+	Sender    models.User
+	Content   string // исходный текст заявки
+	Reason    string // причина неудачи
+	Attempts  int
+	CreatedAt time.Time
+}
+
+// RegistrationData — данные шаблона RegistrationRequest.
+type RegistrationData struct {
+	User models.User
+}
+
+// funcs — функции, доступные в шаблонах.
+var funcs = template.FuncMap{
+	"truncate": Truncate,
+	"date":     func(t time.Time) string { return formatTime(t, "02.01.2006") },
+	"datetime": func(t time.Time) string { return formatTime(t, "02.01.2006 15:04") },
+	"join":     strings.Join,
+	"upper":    strings.ToUpper,
+	"lower":    strings.ToLower,
+}
+
+// formatTime форматирует время; нулевое время — пустая строка.
+func formatTime(t time.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(layout)
+}
+
+// Truncate обрезает s до n символов (рун, а не байт), добавляя многоточие.
+func Truncate(n int, s string) string {
+	if n <= 0 || utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "…"
+}
+
+// Names возвращает имена всех шаблонов по алфавиту.
+func Names() []string {
+	names := make([]string, 0, len(defaults))
+	for name := range defaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Set — набор шаблонов уведомлений. Безопасен для одновременного использования.
+type Set struct {
+	mu     sync.RWMutex
+	dir    string
+	tmpl   map[string]*template.Template
+	source map[string]string // текст шаблона, заданного файлом
+	errs   map[string]error  // ошибки файлов, не прошедших проверку
+}
+
+// builtin — разобранные встроенные шаблоны.
+var builtin = func() map[string]*template.Template {
+	result := make(map[string]*template.Template, len(defaults))
+	for name, text := range defaults {
+		result[name] = template.Must(parse(name, text))
+	}
+	return result
+}()
+
+// Default возвращает набор встроенных шаблонов.
+func Default() *Set {
+	return &Set{tmpl: builtin, source: map[string]string{}, errs: map[string]error{}}
+}
+
+// parse разбирает шаблон с функциями пакета.
+func parse(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+}
+
+// Load загружает шаблоны из каталога dir поверх встроенных. Отсутствующий
+// каталог не ошибка. Файлы, не прошедшие проверку, и файлы с неизвестными
+// именами пропускаются; ошибка перечисляет их, а набор остаётся пригодным.
+func Load(dir string) (*Set, error) {
+	s := &Set{dir: dir}
+	return s, s.Reload()
+}
+
+// Reload перечитывает шаблоны из каталога набора.
+func (s *Set) Reload() error {
+	tmpl := make(map[string]*template.Template, len(builtin))
+	for name, t := range builtin {
+		tmpl[name] = t
+	}
+	source := make(map[string]string)
+	errs := make(map[string]error)
+
+	var problems []string
+	var files []string
+	if s.dir != "" {
+		var err error
+		if files, err = filepath.Glob(filepath.Join(s.dir, "*.tmpl")); err != nil {
+			return err
+		}
+	}
+	for _, path := range files {
+		name := strings.TrimSuffix(filepath.Base(path), ".tmpl")
+		if _, ok := defaults[name]; !ok {
+			problems = append(problems, fmt.Sprintf("%s: неизвестный шаблон (есть: %s)", filepath.Base(path), strings.Join(Names(), ", ")))
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", filepath.Base(path), err))
+			continue
+		}
+		t, err := Validate(name, string(data))
+		if err != nil {
+			errs[name] = err
+			problems = append(problems, fmt.Sprintf("%s: %v", filepath.Base(path), err))
+			continue
+		}
+		tmpl[name] = t
+		source[name] = string(data)
+	}
+
+	s.mu.Lock()
+	s.tmpl, s.source, s.errs = tmpl, source, errs
+	s.mu.Unlock()
+	if len(problems) > 0 {
+		return errors.New("шаблоны уведомлений не применены: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Validate разбирает шаблон name и проверяет его на примере данных (Sample).
+func Validate(name, text string) (*template.Template, error) {
+	t, err := parse(name, text)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, Sample(name)); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(buf.String()) == "" {
+		return nil, fmt.Errorf("шаблон даёт пустое сообщение")
+	}
+	return t, nil
+}
+
+// Render формирует текст уведомления по шаблону name. Если заданный файлом
+// шаблон не сработал на реальных данных, используется встроенный.
+func (s *Set) Render(name string, data interface{}) string {
+	s.mu.RLock()
+	t, ok := s.tmpl[name]
+	s.mu.RUnlock()
+	if !ok {
+		log.Printf("notify: неизвестный шаблон %s", name)
+		return ""
+	}
+	var buf bytes.Buffer
+	err := t.Execute(&buf, data)
+	if err == nil {
+		return strings.TrimSpace(buf.String())
+	}
+	log.Printf("notify: ошибка шаблона %s: %v", name, err)
+	buf.Reset()
+	if err := builtin[name].Execute(&buf, data); err != nil {
+		log.Printf("notify: ошибка встроенного шаблона %s: %v", name, err)
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+// Dir возвращает каталог, из которого загружаются шаблоны ("" — только встроенные).
+func (s *Set) Dir() string {
+	return s.dir
+}
+
+// Source возвращает текст шаблона name и признак, что он задан файлом.
+func (s *Set) Source(name string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if text, ok := s.source[name]; ok {
+		return text, true
+	}
+	return defaults[name], false
+}
+
+// Err возвращает ошибку проверки файла шаблона name (nil, если файл применён или его нет).
+func (s *Set) Err(name string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.errs[name]
+}
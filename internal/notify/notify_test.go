@@ -0,0 +1,116 @@
+// Package notify содержит тесты шаблонов уведомлений.
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"yougile_bot4/internal/models"
+)
+
+func TestDefaultNewTaskTruncatesByRunes(t *testing.T) {
+	desc := strings.Repeat("ж", 250)
+	task := models.Task{Title: "Протечка", Priority: 2, Description: desc, DueDate: time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)}
+	got := Default().Render(NewTask, NewTaskData{Task: task})
+
+	want := "🔵 Новая задача\n📎 Протечка\n🏷 ⭐️ Средний\n📅 Срок: 15.05.2024\n\n📝 " + strings.Repeat("ж", 200) + "…"
+	if got != want {
+		t.Fatalf("unexpected message:\n%q\nwant:\n%q", got, want)
+	}
+	if !utf8.ValidString(got) {
+		t.Fatalf("message must stay valid UTF-8")
+	}
+
+	got = Default().Render(NewTask, NewTaskData{Task: models.Task{Title: "Принтер", Done: true}})
+	if got != "✅ Новая задача\n📎 Принтер\n🏷 📌 Обычный" {
+		t.Fatalf("optional lines must be omitted, got %q", got)
+	}
+}
+
+func TestDefaultTaskChange(t *testing.T) {
+	set := Default()
+	task := models.Task{Key: "ITS-1", Title: "Кран"}
+	cases := []struct {
+		data TaskChangeData
+		want string
+	}{
+		{TaskChangeData{Kind: models.ChangeMoved, Key: "ITS-1", Task: task, From: "«Новые»", To: "«Готово»"}, "➡️ ITS-1 «Кран»: «Новые» → «Готово»"},
+		{TaskChangeData{Kind: models.ChangeCompleted, Key: "ITS-1", Task: task}, "✅ ITS-1 «Кран» выполнена"},
+		{TaskChangeData{Kind: models.ChangeDeadline, Key: "ITS-1", Task: task}, "📅 ITS-1 «Кран»: срок снят"},
+		{TaskChangeData{Kind: models.ChangeAssigned, Key: "ITS-1", Task: task, Added: []string{"Иван", "Пётр"}}, "👤 ITS-1 «Кран»: назначены Иван, Пётр"},
+		{TaskChangeData{Kind: "unknown", Key: "ITS-1", Task: task}, ""},
+	}
+	for _, tc := range cases {
+		if got := set.Render(TaskChange, tc.data); got != tc.want {
+			t.Errorf("%s: expected %q, got %q", tc.data.Kind, tc.want, got)
+		}
+	}
+}
+
+func TestDefaultsRenderSamples(t *testing.T) {
+	for _, name := range Names() {
+		if _, err := Validate(name, defaults[name]); err != nil {
+			t.Errorf("built-in template %s is invalid: %v", name, err)
+		}
+	}
+}
+
+func TestLoadValidatesFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, text string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+			t.Fatalf("Ошибка записи шаблона: %v", err)
+		}
+	}
+	write("new_task.tmpl", "🆕 {{.Task.Title}} ({{truncate 3 .Task.Assignee}})")
+	write("registration_request.tmpl", "{{.User.Missing}}")
+	write("verify_success.tmpl", "{{if}")
+	write("unknown.tmpl", "text")
+
+	set, err := Load(dir)
+	if err == nil {
+		t.Fatalf("expected load errors for broken files")
+	}
+	for _, file := range []string{"registration_request.tmpl", "verify_success.tmpl", "unknown.tmpl"} {
+		if !strings.Contains(err.Error(), file) {
+			t.Errorf("expected %s in error %q", file, err)
+		}
+	}
+
+	got := set.Render(NewTask, NewTaskData{Task: models.Task{Title: "Кран", Assignee: "Иван Петров"}})
+	if got != "🆕 Кран (Ива…)" {
+		t.Fatalf("expected custom template, got %q", got)
+	}
+	if _, custom := set.Source(NewTask); !custom {
+		t.Fatalf("expected new_task to come from file")
+	}
+	if _, custom := set.Source(RegistrationRequest); custom || set.Err(RegistrationRequest) == nil {
+		t.Fatalf("broken file must be reported and replaced by the built-in template")
+	}
+	if got := set.Render(RegistrationRequest, Sample(RegistrationRequest)); !strings.HasPrefix(got, "Новая заявка на регистрацию") {
+		t.Fatalf("expected built-in registration template, got %q", got)
+	}
+
+	// Исправленный файл применяется после перезагрузки
+	write("registration_request.tmpl", "Заявка: {{.User.FirstName}}")
+	if err := os.Remove(filepath.Join(dir, "verify_success.tmpl")); err != nil {
+		t.Fatalf("Ошибка удаления шаблона: %v", err)
+	}
+	if err := os.Remove(filepath.Join(dir, "unknown.tmpl")); err != nil {
+		t.Fatalf("Ошибка удаления шаблона: %v", err)
+	}
+	if err := set.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if got := set.Render(RegistrationRequest, RegistrationData{User: models.User{FirstName: "Анна"}}); got != "Заявка: Анна" {
+		t.Fatalf("expected reloaded template, got %q", got)
+	}
+
+	if _, err := Load(filepath.Join(dir, "missing")); err != nil {
+		t.Fatalf("missing directory must not be an error: %v", err)
+	}
+}
@@ -0,0 +1,262 @@
+// Package routing выбирает чаты для уведомлений о задаче по правилам подписки
+// чатов (models.ChatRule): доске, колонке, приоритету, меткам и стикерам,
+// зданию, ключевым словам и статусу выполнения.
+package routing
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"yougile_bot4/internal/models"
+)
+
+// Facts — сведения о задаче, по которым проверяются правила. Заполняются ботом:
+// названия значений стикеров и здание требуют обращения к Yougile и хранилищу.
+type Facts struct {
+	BoardID  string
+	ColumnID string
+	Priority int
+	// Labels — метки задачи и названия значений её стикеров.
+	Labels []string
+	// Building — здание: значение стикера здания или адрес автора заявки.
+	Building string
+	// Text — название и описание задачи.
+	Text string
+	Done bool
+}
+
+// FactsOf возвращает сведения, известные из самой задачи (без стикеров и здания).
+func FactsOf(task models.Task) Facts {
+	return Facts{
+		BoardID:  task.BoardID,
+		ColumnID: task.ColumnID,
+		Priority: task.Priority,
+		Labels:   append([]string(nil), task.Labels...),
+		Text:     strings.TrimSpace(task.Title + "\n" + task.Description),
+		Done:     task.Done || task.Archived,
+	}
+}
+
+// priorityNames — названия приоритетов в правилах.
+var priorityNames = map[string]int{
+	"high": 1, "высокий": 1,
+	"medium": 2, "средний": 2,
+	"normal": 0, "обычный": 0,
+}
+
+// normPriority сводит приоритет задачи к 1, 2 или 0 (обычный), как в уведомлениях.
+func normPriority(p int) int {
+	if p == 1 || p == 2 {
+		return p
+	}
+	return 0
+}
+
+// patterns кэширует скомпилированные выражения правил.
+var patterns sync.Map
+
+// compile возвращает регистронезависимое выражение правила.
+func compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
+
+// Match проверяет, подходит ли задача под правило. Правило с некорректным
+// выражением не подходит ни под одну задачу.
+func Match(rule models.ChatRule, f Facts) bool {
+	if len(rule.Boards) > 0 && !containsFold(rule.Boards, f.BoardID) {
+		return false
+	}
+	if len(rule.Columns) > 0 && !containsFold(rule.Columns, f.ColumnID) {
+		return false
+	}
+	if len(rule.Priorities) > 0 {
+		ok := false
+		for _, p := range rule.Priorities {
+			ok = ok || normPriority(p) == normPriority(f.Priority)
+		}
+		if !ok {
+			return false
+		}
+	}
+	if len(rule.Labels) > 0 {
+		ok := false
+		for _, l := range f.Labels {
+			ok = ok || containsFold(rule.Labels, l)
+		}
+		if !ok {
+			return false
+		}
+	}
+	if len(rule.Buildings) > 0 && !containsFold(rule.Buildings, f.Building) {
+		return false
+	}
+	if rule.Done != nil && *rule.Done != f.Done {
+		return false
+	}
+	if rule.Pattern != "" {
+		re, err := compile(rule.Pattern)
+		if err != nil || !re.MatchString(f.Text) {
+			return false
+		}
+	}
+	return true
+}
+
+// Chats отбирает из chats те, что должны получить уведомление о задаче: чат без
+// правил получает всё, чат с правилами — если задача подходит хотя бы под одно.
+func Chats(chats []int64, rules []models.ChatRule, f Facts) []int64 {
+	if len(rules) == 0 {
+		return chats
+	}
+	byChat := make(map[int64][]models.ChatRule)
+	for _, r := range rules {
+		byChat[r.ChatID] = append(byChat[r.ChatID], r)
+	}
+	var result []int64
+	for _, chatID := range chats {
+		own, ok := byChat[chatID]
+		if !ok {
+			result = append(result, chatID)
+			continue
+		}
+		for _, r := range own {
+			if Match(r, f) {
+				result = append(result, chatID)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// containsFold проверяет, есть ли v среди values (без учёта регистра и пробелов по краям).
+func containsFold(values []string, v string) bool {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return false
+	}
+	for _, x := range values {
+		if strings.EqualFold(strings.TrimSpace(x), v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse разбирает условия правила вида
+// "board=ID; column=ID; prio=high,medium; label=Сантехника; building=Корпус 1; re=протеч|кран; done=no".
+// Условия разделяются ";", значения внутри условия — ",". Доски и колонки
+// задаются ID Yougile; короткие имена направлений подставляет вызывающий.
+func Parse(spec string) (models.ChatRule, error) {
+	var rule models.ChatRule
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		if !ok || value == "" {
+			return rule, fmt.Errorf("условие %q должно иметь вид ключ=значение", part)
+		}
+		switch key {
+		case "board", "доска":
+			rule.Boards = append(rule.Boards, splitValues(value)...)
+		case "column", "col", "колонка":
+			rule.Columns = append(rule.Columns, splitValues(value)...)
+		case "prio", "priority", "приоритет":
+			for _, v := range splitValues(value) {
+				p, ok := priorityNames[strings.ToLower(v)]
+				if !ok {
+					return rule, fmt.Errorf("неизвестный приоритет %q (high, medium, normal)", v)
+				}
+				rule.Priorities = append(rule.Priorities, p)
+			}
+		case "label", "sticker", "метка":
+			rule.Labels = append(rule.Labels, splitValues(value)...)
+		case "building", "здание":
+			rule.Buildings = append(rule.Buildings, splitValues(value)...)
+		case "re", "text", "текст":
+			if _, err := compile(value); err != nil {
+				return rule, fmt.Errorf("некорректное выражение %q: %v", value, err)
+			}
+			rule.Pattern = value
+		case "done", "выполнена":
+			switch strings.ToLower(value) {
+			case "yes", "да", "true":
+				done := true
+				rule.Done = &done
+			case "no", "нет", "false":
+				done := false
+				rule.Done = &done
+			default:
+				return rule, fmt.Errorf("done принимает yes или no, получено %q", value)
+			}
+		default:
+			return rule, fmt.Errorf("неизвестное условие %q", key)
+		}
+	}
+	return rule, nil
+}
+
+// splitValues разбивает значения условия по запятым.
+func splitValues(value string) []string {
+	var result []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// Describe описывает правило в том же виде, в каком его принимает Parse.
+func Describe(rule models.ChatRule) string {
+	var parts []string
+	if len(rule.Boards) > 0 {
+		parts = append(parts, "board="+strings.Join(rule.Boards, ","))
+	}
+	if len(rule.Columns) > 0 {
+		parts = append(parts, "column="+strings.Join(rule.Columns, ","))
+	}
+	if len(rule.Priorities) > 0 {
+		names := make([]string, len(rule.Priorities))
+		for i, p := range rule.Priorities {
+			switch normPriority(p) {
+			case 1:
+				names[i] = "high"
+			case 2:
+				names[i] = "medium"
+			default:
+				names[i] = "normal"
+			}
+		}
+		parts = append(parts, "prio="+strings.Join(names, ","))
+	}
+	if len(rule.Labels) > 0 {
+		parts = append(parts, "label="+strings.Join(rule.Labels, ","))
+	}
+	if len(rule.Buildings) > 0 {
+		parts = append(parts, "building="+strings.Join(rule.Buildings, ","))
+	}
+	if rule.Pattern != "" {
+		parts = append(parts, "re="+rule.Pattern)
+	}
+	if rule.Done != nil {
+		parts = append(parts, "done="+map[bool]string{true: "yes", false: "no"}[*rule.Done])
+	}
+	if len(parts) == 0 {
+		return "все задачи"
+	}
+	return strings.Join(parts, "; ")
+}
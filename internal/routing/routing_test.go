@@ -0,0 +1,105 @@
+// Package routing содержит тесты правил маршрутизации уведомлений.
+package routing
+
+import (
+	"reflect"
+	"testing"
+
+	"yougile_bot4/internal/models"
+)
+
+func TestMatchConditions(t *testing.T) {
+	facts := Facts{
+		BoardID:  "b-aho",
+		ColumnID: "c-new",
+		Priority: 1,
+		Labels:   []string{"Сантехника"},
+		Building: "Корпус 1",
+		Text:     "Протечка в туалете\nКапает кран",
+	}
+	no, yes := false, true
+	cases := []struct {
+		name string
+		rule models.ChatRule
+		want bool
+	}{
+		{"empty rule", models.ChatRule{}, true},
+		{"board", models.ChatRule{Boards: []string{"b-it", "b-aho"}}, true},
+		{"other board", models.ChatRule{Boards: []string{"b-it"}}, false},
+		{"column", models.ChatRule{Columns: []string{"c-new"}}, true},
+		{"high priority", models.ChatRule{Priorities: []int{1}}, true},
+		{"normal priority", models.ChatRule{Priorities: []int{0}}, false},
+		{"label any case", models.ChatRule{Labels: []string{"сантехника"}}, true},
+		{"missing label", models.ChatRule{Labels: []string{"Электрика"}}, false},
+		{"building", models.ChatRule{Buildings: []string{" корпус 1 "}}, true},
+		{"other building", models.ChatRule{Buildings: []string{"Корпус 2"}}, false},
+		{"keyword", models.ChatRule{Pattern: "ПРОТЕЧ|затоп"}, true},
+		{"keyword in description", models.ChatRule{Pattern: "(?m)^капает"}, true},
+		{"missing keyword", models.ChatRule{Pattern: "принтер"}, false},
+		{"broken pattern", models.ChatRule{Pattern: "("}, false},
+		{"open only", models.ChatRule{Done: &no}, true},
+		{"done only", models.ChatRule{Done: &yes}, false},
+		{"all conditions", models.ChatRule{Boards: []string{"b-aho"}, Priorities: []int{1, 2}, Buildings: []string{"Корпус 1"}, Done: &no}, true},
+		{"one failing condition", models.ChatRule{Boards: []string{"b-aho"}, Priorities: []int{2}}, false},
+	}
+	for _, tc := range cases {
+		if got := Match(tc.rule, facts); got != tc.want {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+
+	// Неизвестный приоритет задачи считается обычным
+	if !Match(models.ChatRule{Priorities: []int{0}}, Facts{Priority: 5}) {
+		t.Errorf("expected unknown priority to match normal")
+	}
+}
+
+func TestChatsFiltersByRules(t *testing.T) {
+	rules := []models.ChatRule{
+		{ChatID: 10, Labels: []string{"Сантехника"}},
+		{ChatID: 10, Buildings: []string{"Корпус 2"}},
+		{ChatID: 20, Priorities: []int{1}},
+	}
+	chats := []int64{10, 20, 30}
+
+	got := Chats(chats, rules, Facts{Building: "Корпус 2", Priority: 2})
+	if !reflect.DeepEqual(got, []int64{10, 30}) {
+		t.Fatalf("expected facilities and unfiltered chats, got %v", got)
+	}
+	got = Chats(chats, rules, Facts{Priority: 1})
+	if !reflect.DeepEqual(got, []int64{20, 30}) {
+		t.Fatalf("expected on-call and unfiltered chats, got %v", got)
+	}
+	if got := Chats(chats, nil, Facts{}); !reflect.DeepEqual(got, chats) {
+		t.Fatalf("expected all chats without rules, got %v", got)
+	}
+}
+
+func TestParseAndDescribe(t *testing.T) {
+	rule, err := Parse("board=b1; prio=high,средний; label=Сантехника, Электрика; building=Корпус 1; re=протеч|кран; done=no")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if !reflect.DeepEqual(rule.Boards, []string{"b1"}) || !reflect.DeepEqual(rule.Priorities, []int{1, 2}) ||
+		!reflect.DeepEqual(rule.Labels, []string{"Сантехника", "Электрика"}) || !reflect.DeepEqual(rule.Buildings, []string{"Корпус 1"}) ||
+		rule.Pattern != "протеч|кран" || rule.Done == nil || *rule.Done {
+		t.Fatalf("unexpected rule %+v", rule)
+	}
+	want := "board=b1; prio=high,medium; label=Сантехника,Электрика; building=Корпус 1; re=протеч|кран; done=no"
+	if got := Describe(rule); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	again, err := Parse(Describe(rule))
+	if err != nil || !reflect.DeepEqual(again, rule) {
+		t.Fatalf("expected description to parse back, got %+v, %v", again, err)
+	}
+	if Describe(models.ChatRule{}) != "все задачи" {
+		t.Fatalf("unexpected description of an empty rule")
+	}
+
+	for _, spec := range []string{"prio=urgent", "re=(", "done=maybe", "color=red", "board"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("expected error for %q", spec)
+		}
+	}
+}
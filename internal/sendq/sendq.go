@@ -0,0 +1,280 @@
+// Package sendq — сохраняемая на диск очередь исходящих сообщений бота.
+// Сообщения отправляются по одному в порядке поступления (в пределах чата)
+// с учётом ограничений Telegram: общей частоты отправки и частоты для одного
+// чата. Ответ 429 приостанавливает отправку на retry_after, временные ошибки
+// повторяются с растущей паузой, а чат, ответивший 403 (бот заблокирован или
+// удалён из группы), исключается из рассылки.
+package sendq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"yougile_bot4/internal/metrics"
+	"yougile_bot4/internal/models"
+)
+
+// Ограничения Telegram по умолчанию: не более 30 сообщений в секунду всего,
+// одного сообщения в секунду в личный чат и 20 сообщений в минуту в группу.
+const (
+	GlobalInterval  = time.Second / 30
+	PrivateInterval = time.Second
+	GroupInterval   = 3 * time.Second
+)
+
+const (
+	// MaxAttempts — после стольких временных ошибок сообщение удаляется из очереди.
+	MaxAttempts = 8
+	// baseBackoff и maxBackoff — пауза перед первым повтором и её предел.
+	baseBackoff = 2 * time.Second
+	maxBackoff  = 10 * time.Minute
+)
+
+var (
+	// ErrForbidden — Telegram запретил отправку в чат (403): бот заблокирован
+	// пользователем или удалён из группы. Сообщения в чат удаляются из очереди.
+	ErrForbidden = errors.New("чат недоступен боту")
+	// ErrRejected — Telegram отклонил сообщение (например, 400): повтор не поможет.
+	ErrRejected = errors.New("сообщение отклонено Telegram")
+)
+
+// RetryAfterError — Telegram ограничил частоту отправки (429) и просит подождать After.
+type RetryAfterError struct {
+	After time.Duration
+	Err   error
+}
+
+// Error возвращает описание ошибки.
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("слишком много запросов, повтор через %s: %v", e.After, e.Err)
+}
+
+// Unwrap возвращает исходную ошибку.
+func (e *RetryAfterError) Unwrap() error { return e.Err }
+
+// Store хранит очередь сообщений (реализуется storage.Storage).
+type Store interface {
+	EnqueueMessage(m models.OutgoingMessage) int64
+	GetSendQueue() []models.OutgoingMessage
+	SendQueueLen() int
+	UpdateMessage(m models.OutgoingMessage)
+	RemoveMessage(id int64)
+	RemoveChatMessages(chatID int64) int
+	SaveSendQueue() error
+}
+
+// Transport отправляет сообщения (реализуется ботом). Ошибки Telegram
+// переводятся в ErrForbidden, ErrRejected и *RetryAfterError; остальные
+// ошибки считаются временными.
+type Transport interface {
+	// Send отправляет сообщение и возвращает его ID в Telegram.
+	Send(m models.OutgoingMessage) (int, error)
+	// Delivered вызывается после успешной отправки.
+	Delivered(m models.OutgoingMessage, messageID int)
+	// ChatGone вызывается, когда чат ответил 403 и исключён из рассылки.
+	ChatGone(chatID int64)
+}
+
+// Queue — очередь исходящих сообщений. Enqueue безопасен для одновременного
+// вызова; отправкой занимается одна горутина Run.
+type Queue struct {
+	store     Store
+	transport Transport
+	metrics   *metrics.Metrics
+	wake      chan struct{}
+
+	global, private, group time.Duration
+
+	// Состояние отправки; меняется только горутиной Run.
+	lastSent    time.Time
+	chatSent    map[int64]time.Time
+	pausedUntil time.Time
+}
+
+// New создаёт очередь с ограничениями Telegram по умолчанию.
+func New(store Store, transport Transport, m *metrics.Metrics) *Queue {
+	return &Queue{
+		store:     store,
+		transport: transport,
+		metrics:   m,
+		wake:      make(chan struct{}, 1),
+		global:    GlobalInterval,
+		private:   PrivateInterval,
+		group:     GroupInterval,
+		chatSent:  make(map[int64]time.Time),
+	}
+}
+
+// SetLimits задаёт минимальные интервалы между сообщениями: всего, в личный
+// чат и в группу. Вызывается до Run.
+func (q *Queue) SetLimits(global, private, group time.Duration) {
+	q.global, q.private, q.group = global, private, group
+}
+
+// Enqueue ставит сообщение в очередь, сразу сохраняет очередь на диск и
+// возвращает номер сообщения.
+func (q *Queue) Enqueue(m models.OutgoingMessage) int64 {
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = time.Now()
+	}
+	id := q.store.EnqueueMessage(m)
+	if err := q.store.SaveSendQueue(); err != nil {
+		log.Printf("sendq: ошибка сохранения очереди: %v", err)
+	}
+	q.setDepth()
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return id
+}
+
+// Run отправляет сообщения до отмены ctx. Неотправленные сообщения остаются
+// в сохранённой очереди и будут отправлены после перезапуска.
+func (q *Queue) Run(ctx context.Context) {
+	q.setDepth()
+	for {
+		wait, sent := q.step(time.Now())
+		if sent {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+		var timer *time.Timer
+		var fire <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			fire = timer.C
+		}
+		select {
+		case <-ctx.Done():
+		case <-q.wake:
+		case <-fire:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// step отправляет одно сообщение, которое можно отправить в момент now.
+// Возвращает, было ли отправлено сообщение, и иначе — через сколько появится
+// следующее (0 — очередь пуста).
+func (q *Queue) step(now time.Time) (time.Duration, bool) {
+	items := q.store.GetSendQueue()
+	if len(items) == 0 {
+		return 0, false
+	}
+	if now.Before(q.pausedUntil) {
+		return q.pausedUntil.Sub(now), false
+	}
+	if next := q.lastSent.Add(q.global); now.Before(next) {
+		return next.Sub(now), false
+	}
+
+	var wait time.Duration
+	heads := make(map[int64]bool)
+	for _, m := range items {
+		// Сообщения в один чат уходят строго по порядку
+		if heads[m.ChatID] {
+			continue
+		}
+		heads[m.ChatID] = true
+		ready := q.chatSent[m.ChatID].Add(q.chatInterval(m.ChatID))
+		if m.NotBefore.After(ready) {
+			ready = m.NotBefore
+		}
+		if ready.After(now) {
+			if d := ready.Sub(now); wait == 0 || d < wait {
+				wait = d
+			}
+			continue
+		}
+		q.send(m, now)
+		return 0, true
+	}
+	return wait, false
+}
+
+// chatInterval возвращает минимальный интервал между сообщениями в чат:
+// у групп и каналов ID отрицательный.
+func (q *Queue) chatInterval(chatID int64) time.Duration {
+	if chatID < 0 {
+		return q.group
+	}
+	return q.private
+}
+
+// send отправляет сообщение и обрабатывает результат.
+func (q *Queue) send(m models.OutgoingMessage, now time.Time) {
+	q.lastSent = now
+	q.chatSent[m.ChatID] = now
+	id, err := q.transport.Send(m)
+
+	var retry *RetryAfterError
+	switch {
+	case err == nil:
+		q.store.RemoveMessage(m.ID)
+		q.transport.Delivered(m, id)
+	case errors.As(err, &retry):
+		// Ограничение может относиться ко всему боту: пауза для всей очереди
+		q.pausedUntil = now.Add(retry.After)
+		m.NotBefore = q.pausedUntil
+		m.LastError = err.Error()
+		q.store.UpdateMessage(m)
+		q.metrics.IncSendRateLimited()
+		log.Printf("sendq: Telegram ограничил отправку, пауза %s", retry.After)
+	case errors.Is(err, ErrForbidden):
+		n := q.store.RemoveChatMessages(m.ChatID)
+		q.metrics.AddSendFailures(int64(n))
+		q.metrics.IncSendChatsRemoved()
+		log.Printf("sendq: чат %d недоступен (%v), удалено сообщений: %d", m.ChatID, err, n)
+		q.transport.ChatGone(m.ChatID)
+	case errors.Is(err, ErrRejected):
+		q.store.RemoveMessage(m.ID)
+		q.metrics.AddSendFailures(1)
+		log.Printf("sendq: сообщение %d в чат %d отклонено: %v", m.ID, m.ChatID, err)
+	default:
+		m.Attempts++
+		m.LastError = err.Error()
+		if m.Attempts >= MaxAttempts {
+			q.store.RemoveMessage(m.ID)
+			q.metrics.AddSendFailures(1)
+			log.Printf("sendq: сообщение %d в чат %d не отправлено после %d попыток: %v", m.ID, m.ChatID, m.Attempts, err)
+			break
+		}
+		m.NotBefore = now.Add(Backoff(m.Attempts))
+		q.store.UpdateMessage(m)
+		q.metrics.IncSendRetries()
+		log.Printf("sendq: ошибка отправки в чат %d (попытка %d), повтор в %s: %v", m.ChatID, m.Attempts, m.NotBefore.Format("15:04:05"), err)
+	}
+	if err := q.store.SaveSendQueue(); err != nil {
+		log.Printf("sendq: ошибка сохранения очереди: %v", err)
+	}
+	q.setDepth()
+}
+
+// Backoff возвращает паузу перед повтором после attempts неудачных попыток:
+// 2 с, 4 с, 8 с… но не больше 10 минут.
+func Backoff(attempts int) time.Duration {
+	d := baseBackoff
+	for i := 1; i < attempts && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	return d
+}
+
+// setDepth обновляет метрику длины очереди.
+func (q *Queue) setDepth() {
+	q.metrics.SetSendQueueDepth(int64(q.store.SendQueueLen()))
+}
@@ -0,0 +1,238 @@
+// Package sendq содержит тесты очереди исходящих сообщений.
+package sendq
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"yougile_bot4/internal/metrics"
+	"yougile_bot4/internal/models"
+)
+
+// memStore — очередь сообщений в памяти для тестов.
+type memStore struct {
+	mu    sync.Mutex
+	items []models.OutgoingMessage
+	seq   int64
+	saves int
+}
+
+func (s *memStore) EnqueueMessage(m models.OutgoingMessage) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	m.ID = s.seq
+	s.items = append(s.items, m)
+	return m.ID
+}
+
+func (s *memStore) GetSendQueue() []models.OutgoingMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]models.OutgoingMessage(nil), s.items...)
+}
+
+func (s *memStore) SendQueueLen() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.items)
+}
+
+func (s *memStore) UpdateMessage(m models.OutgoingMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == m.ID {
+			s.items[i] = m
+		}
+	}
+}
+
+func (s *memStore) RemoveMessage(id int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == id {
+			s.items = append(s.items[:i], s.items[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *memStore) RemoveChatMessages(chatID int64) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.items[:0]
+	for _, m := range s.items {
+		if m.ChatID != chatID {
+			kept = append(kept, m)
+		}
+	}
+	n := len(s.items) - len(kept)
+	s.items = kept
+	return n
+}
+
+func (s *memStore) SaveSendQueue() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saves++
+	return nil
+}
+
+// fakeTransport возвращает заданные ошибки по порядку и запоминает отправленное.
+type fakeTransport struct {
+	errs      []error
+	sent      []models.OutgoingMessage
+	delivered []int64
+	gone      []int64
+}
+
+func (t *fakeTransport) Send(m models.OutgoingMessage) (int, error) {
+	t.sent = append(t.sent, m)
+	if len(t.errs) > 0 {
+		err := t.errs[0]
+		t.errs = t.errs[1:]
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(t.sent), nil
+}
+
+func (t *fakeTransport) Delivered(m models.OutgoingMessage, messageID int) {
+	t.delivered = append(t.delivered, m.ID)
+}
+
+func (t *fakeTransport) ChatGone(chatID int64) { t.gone = append(t.gone, chatID) }
+
+func newQueue(errs ...error) (*Queue, *memStore, *fakeTransport, *metrics.Metrics) {
+	store, transport, m := &memStore{}, &fakeTransport{errs: errs}, &metrics.Metrics{}
+	return New(store, transport, m), store, transport, m
+}
+
+func TestStepRespectsLimitsAndOrder(t *testing.T) {
+	q, store, transport, m := newQueue()
+	q.Enqueue(models.OutgoingMessage{ChatID: 1, Text: "a1"})
+	q.Enqueue(models.OutgoingMessage{ChatID: 1, Text: "a2"})
+	q.Enqueue(models.OutgoingMessage{ChatID: -100, Text: "g1"})
+	if store.saves != 3 || m.SendQueueDepth != 3 {
+		t.Fatalf("expected queue to be saved on enqueue, saves=%d depth=%d", store.saves, m.SendQueueDepth)
+	}
+
+	now := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+	if _, sent := q.step(now); !sent {
+		t.Fatalf("expected first message to be sent")
+	}
+	// Общий интервал ещё не прошёл
+	if wait, sent := q.step(now); sent || wait != GlobalInterval {
+		t.Fatalf("expected global spacing, got wait=%s sent=%v", wait, sent)
+	}
+	// Второе сообщение в чат 1 ждёт, а группа уже может получить своё
+	now = now.Add(GlobalInterval)
+	if _, sent := q.step(now); !sent || transport.sent[1].Text != "g1" {
+		t.Fatalf("expected group message while chat 1 waits, got %+v", transport.sent)
+	}
+	now = now.Add(GlobalInterval)
+	if wait, sent := q.step(now); sent || wait != PrivateInterval-2*GlobalInterval {
+		t.Fatalf("expected per-chat spacing, got wait=%s sent=%v", wait, sent)
+	}
+	now = now.Add(PrivateInterval)
+	if _, sent := q.step(now); !sent || transport.sent[2].Text != "a2" {
+		t.Fatalf("expected messages to one chat in order, got %+v", transport.sent)
+	}
+	if wait, sent := q.step(now); sent || wait != 0 {
+		t.Fatalf("expected empty queue, got wait=%s sent=%v", wait, sent)
+	}
+	if len(transport.delivered) != 3 || m.SendQueueDepth != 0 {
+		t.Fatalf("expected all messages delivered, got %v depth=%d", transport.delivered, m.SendQueueDepth)
+	}
+}
+
+func TestStepPausesOnRetryAfter(t *testing.T) {
+	q, store, transport, m := newQueue(&RetryAfterError{After: 5 * time.Second, Err: errors.New("429")})
+	q.Enqueue(models.OutgoingMessage{ChatID: 1, Text: "a"})
+	q.Enqueue(models.OutgoingMessage{ChatID: 2, Text: "b"})
+
+	now := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+	q.step(now)
+	if m.SendRateLimited != 1 || store.SendQueueLen() != 2 {
+		t.Fatalf("expected message to stay queued after 429, limited=%d len=%d", m.SendRateLimited, store.SendQueueLen())
+	}
+	// Пауза распространяется на все чаты
+	if wait, sent := q.step(now.Add(time.Second)); sent || wait != 4*time.Second {
+		t.Fatalf("expected global pause, got wait=%s sent=%v", wait, sent)
+	}
+	now = now.Add(5 * time.Second)
+	if _, sent := q.step(now); !sent || transport.sent[1].Text != "a" {
+		t.Fatalf("expected the limited message to go first, got %+v", transport.sent)
+	}
+	if store.items[0].Text != "b" || store.items[0].Attempts != 0 {
+		t.Fatalf("429 must not count as a failed attempt, got %+v", store.items)
+	}
+}
+
+func TestStepDropsForbiddenChat(t *testing.T) {
+	q, store, transport, m := newQueue(ErrForbidden)
+	q.Enqueue(models.OutgoingMessage{ChatID: -5, Text: "1"})
+	q.Enqueue(models.OutgoingMessage{ChatID: 7, Text: "2"})
+	q.Enqueue(models.OutgoingMessage{ChatID: -5, Text: "3"})
+
+	q.step(time.Now())
+	if len(transport.gone) != 1 || transport.gone[0] != -5 {
+		t.Fatalf("expected chat -5 to be reported gone, got %v", transport.gone)
+	}
+	if store.SendQueueLen() != 1 || store.items[0].ChatID != 7 {
+		t.Fatalf("expected only chat 7 to stay queued, got %+v", store.items)
+	}
+	if m.SendFailures != 2 || m.SendChatsRemoved != 1 || m.SendQueueDepth != 1 {
+		t.Fatalf("unexpected metrics %+v", m)
+	}
+}
+
+func TestStepRetriesWithBackoff(t *testing.T) {
+	errs := make([]error, MaxAttempts)
+	for i := range errs {
+		errs[i] = errors.New("timeout")
+	}
+	q, store, _, m := newQueue(errs...)
+	q.Enqueue(models.OutgoingMessage{ChatID: 1, Text: "a"})
+
+	now := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+	q.step(now)
+	if got := store.items[0]; got.Attempts != 1 || !got.NotBefore.Equal(now.Add(2*time.Second)) || got.LastError != "timeout" {
+		t.Fatalf("unexpected retry state %+v", got)
+	}
+	if wait, sent := q.step(now.Add(time.Second)); sent || wait != time.Second {
+		t.Fatalf("expected backoff wait, got wait=%s sent=%v", wait, sent)
+	}
+	for i := 1; i < MaxAttempts; i++ {
+		now = store.items[0].NotBefore
+		q.step(now)
+	}
+	if store.SendQueueLen() != 0 || m.SendRetries != MaxAttempts-1 || m.SendFailures != 1 {
+		t.Fatalf("expected message to be dropped after %d attempts, len=%d retries=%d failures=%d",
+			MaxAttempts, store.SendQueueLen(), m.SendRetries, m.SendFailures)
+	}
+
+	q, store, _, m = newQueue(ErrRejected)
+	q.Enqueue(models.OutgoingMessage{ChatID: 1, Text: "<b"})
+	q.step(now)
+	if store.SendQueueLen() != 0 || m.SendFailures != 1 || m.SendRetries != 0 {
+		t.Fatalf("rejected message must be dropped without retries")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	want := []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second}
+	for i, w := range want {
+		if got := Backoff(i + 1); got != w {
+			t.Errorf("attempt %d: expected %s, got %s", i+1, w, got)
+		}
+	}
+	if got := Backoff(100); got != maxBackoff {
+		t.Errorf("expected backoff to be capped, got %s", got)
+	}
+}
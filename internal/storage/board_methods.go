@@ -0,0 +1,120 @@
+// Package storage содержит методы хранения отслеживаемых досок и известных задач по доскам.
+package storage
+
+import "yougile_bot4/internal/models"
+
+// boardKey возвращает ключ известной задачи в рамках доски.
+func boardKey(boardID, key string) string {
+	if boardID == "" {
+		return key
+	}
+	return boardID + ":" + key
+}
+
+// AddKnownBoardKey отмечает задачу key доски boardID как известную.
+// Пустой boardID равносилен AddKnownKey.
+func (s *Storage) AddKnownBoardKey(boardID, key string) {
+	if key == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.knownTasks[boardKey(boardID, key)] = true
+	s.isDirty = true
+}
+
+// IsKnownBoardKey проверяет, известна ли задача key доски boardID. Ключи,
+// сохранённые до появления нескольких досок (без доски), тоже учитываются.
+func (s *Storage) IsKnownBoardKey(boardID, key string) bool {
+	if key == "" {
+		return false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.knownTasks[boardKey(boardID, key)] || s.knownTasks[key]
+}
+
+// IsScopeSeeded сообщает, завершён ли первый обход доски key.
+func (s *Storage) IsScopeSeeded(key string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.seededScopes[key]
+}
+
+// MarkScopeSeeded отмечает, что первый обход доски key завершён.
+func (s *Storage) MarkScopeSeeded(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seededScopes[key] = true
+	s.isDirty = true
+}
+
+// AdoptSeededScopes отмечает доски keys пройденными, если известные задачи
+// сохранены версией без учёта первого обхода: эти доски уже опрашивались,
+// и их задачи не нужно запоминать заново без уведомлений. Возвращает true,
+// если доски отмечены.
+func (s *Storage) AdoptSeededScopes(keys []string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.seededScopes) > 0 || len(s.knownTasks) == 0 {
+		return false
+	}
+	for _, key := range keys {
+		s.seededScopes[key] = true
+	}
+	s.isDirty = true
+	return len(keys) > 0
+}
+
+// GetBoardRoutes возвращает копию списка дополнительных отслеживаемых досок.
+func (s *Storage) GetBoardRoutes() []models.BoardRoute {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]models.BoardRoute, len(s.settings.Boards))
+	for i, r := range s.settings.Boards {
+		r.ChatIDs = append([]int64(nil), r.ChatIDs...)
+		r.Answers = append([]string(nil), r.Answers...)
+		result[i] = r
+	}
+	return result
+}
+
+// GetBoardRoute возвращает доску по короткому имени направления.
+func (s *Storage) GetBoardRoute(id string) (models.BoardRoute, bool) {
+	for _, r := range s.GetBoardRoutes() {
+		if r.ID == id {
+			return r, true
+		}
+	}
+	return models.BoardRoute{}, false
+}
+
+// SetBoardRoute добавляет доску или заменяет доску с тем же ID.
+// Данные будут записаны на диск при следующем вызове SaveData.
+func (s *Storage) SetBoardRoute(route models.BoardRoute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, r := range s.settings.Boards {
+		if r.ID == route.ID {
+			s.settings.Boards[i] = route
+			s.isDirty = true
+			return
+		}
+	}
+	s.settings.Boards = append(s.settings.Boards, route)
+	s.isDirty = true
+}
+
+// RemoveBoardRoute удаляет доску по ID. Возвращает false, если такой доски нет.
+func (s *Storage) RemoveBoardRoute(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, r := range s.settings.Boards {
+		if r.ID == id {
+			s.settings.Boards = append(s.settings.Boards[:i], s.settings.Boards[i+1:]...)
+			s.isDirty = true
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+// Package storage содержит методы хранения обнаруженных возможностей API Yougile.
+package storage
+
+import "yougile_bot4/internal/models"
+
+// GetAPICapabilities возвращает копию сохранённых возможностей API.
+func (s *Storage) GetAPICapabilities() models.APICapabilities {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make(models.APICapabilities, len(s.apiCapabilities))
+	for k, v := range s.apiCapabilities {
+		result[k] = v
+	}
+	return result
+}
+
+// SetAPICapabilities заменяет сохранённые возможности API.
+// Данные будут записаны на диск при следующем вызове SaveData.
+func (s *Storage) SetAPICapabilities(caps models.APICapabilities) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apiCapabilities = make(models.APICapabilities, len(caps))
+	for k, v := range caps {
+		s.apiCapabilities[k] = v
+	}
+	s.isDirty = true
+}
@@ -0,0 +1,55 @@
+// Package storage содержит методы хранения состояния пересылки комментариев Yougile.
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"yougile_bot4/internal/models"
+)
+
+// relayReplyTTL — сколько хранится связь сообщения бота с чатом задачи.
+const relayReplyTTL = 30 * 24 * time.Hour
+
+// relayReplyKey формирует ключ связи: сообщения Telegram нумеруются в пределах чата.
+func relayReplyKey(chatID int64, messageID int) string {
+	return fmt.Sprintf("%d:%d", chatID, messageID)
+}
+
+// GetChatCursor возвращает ID последнего обработанного сообщения чата задачи.
+func (s *Storage) GetChatCursor(taskID string) (int64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.chatCursors[taskID]
+	return v, ok
+}
+
+// SetChatCursor запоминает ID последнего обработанного сообщения чата задачи.
+func (s *Storage) SetChatCursor(taskID string, messageID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chatCursors[taskID] = messageID
+	s.isDirty = true
+}
+
+// AddRelayReply запоминает, что сообщение messageID в чате chatID — пересланный
+// комментарий задачи. Устаревшие связи удаляются.
+func (s *Storage) AddRelayReply(chatID int64, messageID int, reply models.RelayReply) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, r := range s.relayReplies {
+		if time.Since(r.SentAt) > relayReplyTTL {
+			delete(s.relayReplies, k)
+		}
+	}
+	s.relayReplies[relayReplyKey(chatID, messageID)] = reply
+	s.isDirty = true
+}
+
+// GetRelayReply возвращает задачу, комментарий которой был переслан сообщением messageID.
+func (s *Storage) GetRelayReply(chatID int64, messageID int) (models.RelayReply, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r, ok := s.relayReplies[relayReplyKey(chatID, messageID)]
+	return r, ok
+}
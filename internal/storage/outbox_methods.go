@@ -0,0 +1,85 @@
+// Package storage содержит методы очереди отложенных операций с Yougile (outbox).
+package storage
+
+import "yougile_bot4/internal/models"
+
+// EnqueueOutbox добавляет операцию в конец очереди и возвращает её номер.
+// Чтобы операция пережила перезапуск, вызывающий должен сохранить данные (SaveData).
+func (s *Storage) EnqueueOutbox(item models.OutboxItem) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outboxSeq++
+	item.ID = s.outboxSeq
+	s.outbox = append(s.outbox, item)
+	s.isDirty = true
+	return item.ID
+}
+
+// GetOutbox возвращает копию очереди в порядке поступления.
+func (s *Storage) GetOutbox() []models.OutboxItem {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]models.OutboxItem, len(s.outbox))
+	copy(result, s.outbox)
+	return result
+}
+
+// GetOutboxItem возвращает текущее состояние операции id
+// (например, с проставленным после создания задачи TaskID).
+func (s *Storage) GetOutboxItem(id int64) (models.OutboxItem, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, item := range s.outbox {
+		if item.ID == id {
+			return item, true
+		}
+	}
+	return models.OutboxItem{}, false
+}
+
+// OutboxLen возвращает количество операций в очереди.
+func (s *Storage) OutboxLen() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.outbox)
+}
+
+// UpdateOutbox заменяет операцию с тем же номером (например, счётчик попыток).
+func (s *Storage) UpdateOutbox(item models.OutboxItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.outbox {
+		if s.outbox[i].ID == item.ID {
+			s.outbox[i] = item
+			s.isDirty = true
+			return
+		}
+	}
+}
+
+// RemoveOutbox удаляет операцию из очереди.
+func (s *Storage) RemoveOutbox(id int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.outbox {
+		if s.outbox[i].ID == id {
+			s.outbox = append(s.outbox[:i], s.outbox[i+1:]...)
+			s.isDirty = true
+			return
+		}
+	}
+}
+
+// ResolveOutboxRef проставляет идентификатор созданной задачи во все операции,
+// ожидающие результата операции ref.
+func (s *Storage) ResolveOutboxRef(ref int64, taskID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.outbox {
+		if s.outbox[i].TaskRef == ref {
+			s.outbox[i].TaskID = taskID
+			s.outbox[i].TaskRef = 0
+			s.isDirty = true
+		}
+	}
+}
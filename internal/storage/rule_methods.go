@@ -0,0 +1,58 @@
+// Package storage содержит методы хранения правил маршрутизации уведомлений по чатам.
+package storage
+
+import "yougile_bot4/internal/models"
+
+// GetChatRules возвращает копию всех правил подписки чатов.
+func (s *Storage) GetChatRules() []models.ChatRule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]models.ChatRule, len(s.settings.ChatRules))
+	copy(result, s.settings.ChatRules)
+	return result
+}
+
+// GetChatRulesFor возвращает правила подписки чата chatID в порядке добавления.
+func (s *Storage) GetChatRulesFor(chatID int64) []models.ChatRule {
+	var result []models.ChatRule
+	for _, r := range s.GetChatRules() {
+		if r.ChatID == chatID {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// AddChatRule добавляет правило подписки чата.
+// Данные будут записаны на диск при следующем вызове SaveData.
+func (s *Storage) AddChatRule(rule models.ChatRule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settings.ChatRules = append(s.settings.ChatRules, rule)
+	s.isDirty = true
+}
+
+// RemoveChatRule удаляет n-е (с нуля) правило чата chatID; n < 0 удаляет все
+// правила чата. Возвращает число удалённых правил.
+func (s *Storage) RemoveChatRule(chatID int64, n int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.settings.ChatRules[:0]
+	removed, i := 0, 0
+	for _, r := range s.settings.ChatRules {
+		if r.ChatID == chatID {
+			match := n < 0 || i == n
+			i++
+			if match {
+				removed++
+				continue
+			}
+		}
+		kept = append(kept, r)
+	}
+	s.settings.ChatRules = kept
+	if removed > 0 {
+		s.isDirty = true
+	}
+	return removed
+}
@@ -0,0 +1,122 @@
+// Package storage содержит методы очереди исходящих сообщений Telegram.
+package storage
+
+import "yougile_bot4/internal/models"
+
+// EnqueueMessage добавляет сообщение в конец очереди отправки и возвращает его номер.
+// Чтобы сообщение пережило перезапуск, вызывающий сохраняет очередь (SaveSendQueue).
+func (s *Storage) EnqueueMessage(m models.OutgoingMessage) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sendSeq++
+	m.ID = s.sendSeq
+	s.sendQueue = append(s.sendQueue, m)
+	s.isDirty = true
+	return m.ID
+}
+
+// GetSendQueue возвращает копию очереди отправки в порядке поступления.
+func (s *Storage) GetSendQueue() []models.OutgoingMessage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]models.OutgoingMessage, len(s.sendQueue))
+	copy(result, s.sendQueue)
+	return result
+}
+
+// SendQueueLen возвращает количество сообщений в очереди отправки.
+func (s *Storage) SendQueueLen() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.sendQueue)
+}
+
+// UpdateMessage заменяет сообщение с тем же номером (счётчик попыток, время повтора).
+func (s *Storage) UpdateMessage(m models.OutgoingMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.sendQueue {
+		if s.sendQueue[i].ID == m.ID {
+			s.sendQueue[i] = m
+			s.isDirty = true
+			return
+		}
+	}
+}
+
+// RemoveMessage удаляет сообщение из очереди отправки.
+func (s *Storage) RemoveMessage(id int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.sendQueue {
+		if s.sendQueue[i].ID == id {
+			s.sendQueue = append(s.sendQueue[:i], s.sendQueue[i+1:]...)
+			s.isDirty = true
+			return
+		}
+	}
+}
+
+// RemoveChatMessages удаляет все сообщения в чат chatID и возвращает их число.
+func (s *Storage) RemoveChatMessages(chatID int64) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.sendQueue[:0]
+	for _, m := range s.sendQueue {
+		if m.ChatID != chatID {
+			kept = append(kept, m)
+		}
+	}
+	removed := len(s.sendQueue) - len(kept)
+	s.sendQueue = kept
+	if removed > 0 {
+		s.isDirty = true
+	}
+	return removed
+}
+
+// SaveSendQueue сразу записывает на диск только очередь отправки: она меняется
+// с каждым сообщением, и ждать общего сохранения (SaveData) нельзя.
+func (s *Storage) SaveSendQueue() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.saveJSON(s.sendQueueFile, map[string]interface{}{"seq": s.sendSeq, "items": s.sendQueue})
+}
+
+// RemoveChat убирает чат из всех списков рассылки: общих чатов уведомлений,
+// чатов направлений и правил подписки. Возвращает false, если чата нигде не было.
+func (s *Storage) RemoveChat(chatID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	found := false
+	for i, id := range s.chatIDs {
+		if id == chatID {
+			s.chatIDs = append(s.chatIDs[:i], s.chatIDs[i+1:]...)
+			found = true
+			break
+		}
+	}
+	for i := range s.settings.Boards {
+		ids := s.settings.Boards[i].ChatIDs
+		for j, id := range ids {
+			if id == chatID {
+				s.settings.Boards[i].ChatIDs = append(ids[:j:j], ids[j+1:]...)
+				found = true
+				break
+			}
+		}
+	}
+	rules := s.settings.ChatRules[:0]
+	for _, r := range s.settings.ChatRules {
+		if r.ChatID == chatID {
+			found = true
+			continue
+		}
+		rules = append(rules, r)
+	}
+	s.settings.ChatRules = rules
+	if found {
+		s.isDirty = true
+	}
+	return found
+}
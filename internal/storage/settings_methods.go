@@ -0,0 +1,65 @@
+// Package storage содержит методы хранения настроек бота.
+package storage
+
+import "yougile_bot4/internal/models"
+
+// GetTargetColumn возвращает выбранную администратором колонку для новых задач
+// и флаг, задана ли она.
+func (s *Storage) GetTargetColumn() (models.TargetColumn, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.settings.TargetColumn == nil {
+		return models.TargetColumn{}, false
+	}
+	return *s.settings.TargetColumn, true
+}
+
+// SetTargetColumn сохраняет колонку для новых задач.
+// Данные будут записаны на диск при следующем вызове SaveData.
+func (s *Storage) SetTargetColumn(target models.TargetColumn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settings.TargetColumn = &target
+	s.isDirty = true
+}
+
+// GetStickerMap возвращает копию соответствия источников значений и стикеров доски.
+func (s *Storage) GetStickerMap() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make(map[string]string, len(s.settings.StickerMap))
+	for k, v := range s.settings.StickerMap {
+		result[k] = v
+	}
+	return result
+}
+
+// GetStickerCreateStates сообщает, разрешено ли добавлять стикерам недостающие состояния.
+func (s *Storage) GetStickerCreateStates() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.settings.StickerCreateStates
+}
+
+// SetStickerCreateStates разрешает или запрещает добавлять стикерам недостающие состояния.
+func (s *Storage) SetStickerCreateStates(allow bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settings.StickerCreateStates = allow
+	s.isDirty = true
+}
+
+// SetStickerMapping связывает источник значения со стикером; пустой stickerID удаляет связь.
+func (s *Storage) SetStickerMapping(source, stickerID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if stickerID == "" {
+		delete(s.settings.StickerMap, source)
+	} else {
+		if s.settings.StickerMap == nil {
+			s.settings.StickerMap = make(map[string]string)
+		}
+		s.settings.StickerMap[source] = stickerID
+	}
+	s.isDirty = true
+}
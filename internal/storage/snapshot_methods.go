@@ -0,0 +1,80 @@
+// Package storage содержит методы хранения снимков состояния задач Yougile.
+package storage
+
+import (
+	"time"
+
+	"yougile_bot4/internal/models"
+)
+
+// GetTaskSnapshot возвращает последний сохранённый снимок задачи key.
+func (s *Storage) GetTaskSnapshot(key string) (models.TaskSnapshot, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snap, ok := s.snapshots[key]
+	return snap, ok
+}
+
+// SetTaskSnapshot сохраняет снимок задачи (ключ — snap.Key).
+func (s *Storage) SetTaskSnapshot(snap models.TaskSnapshot) {
+	if snap.Key == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshots[snap.Key] = snap
+	s.isDirty = true
+}
+
+// MarkTaskSnapshotChecked запоминает время отдельного запроса задачи key.
+func (s *Storage) MarkTaskSnapshotChecked(key string, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if snap, ok := s.snapshots[key]; ok {
+		snap.CheckedAt = at
+		s.snapshots[key] = snap
+		s.isDirty = true
+	}
+}
+
+// RemoveTaskSnapshot удаляет снимок задачи (например, удалённой в Yougile).
+func (s *Storage) RemoveTaskSnapshot(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.snapshots[key]; ok {
+		delete(s.snapshots, key)
+		s.isDirty = true
+	}
+}
+
+// ListTaskSnapshots возвращает снимки незавершённых задач доски boardID.
+func (s *Storage) ListTaskSnapshots(boardID string) []models.TaskSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var result []models.TaskSnapshot
+	for _, snap := range s.snapshots {
+		if snap.BoardID == boardID && !snap.Done && !snap.Archived {
+			snap.Assigned = append([]string(nil), snap.Assigned...)
+			result = append(result, snap)
+		}
+	}
+	return result
+}
+
+// PruneTaskSnapshots удаляет снимки задач, не встречавшихся с момента before.
+// Возвращает число удалённых снимков.
+func (s *Storage) PruneTaskSnapshots(before time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	removed := 0
+	for key, snap := range s.snapshots {
+		if snap.SeenAt.Before(before) {
+			delete(s.snapshots, key)
+			removed++
+		}
+	}
+	if removed > 0 {
+		s.isDirty = true
+	}
+	return removed
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
@@ -33,13 +35,37 @@ type Storage struct {
 	templatesFile   string
 	lastScanned     int
 	lastScannedFile string
+	seededScopes    map[string]bool // доски, первый обход которых завершён (см. watcher.SeedKey)
+
+	apiCapabilities     models.APICapabilities // обнаруженные стратегии запросов к API
+	apiCapabilitiesFile string
+
+	outbox     []models.OutboxItem // отложенные операции с Yougile, в порядке поступления
+	outboxSeq  int64               // последний выданный номер операции
+	outboxFile string
+
+	settings     models.Settings // настройки, изменяемые администраторами через бота
+	settingsFile string
+
+	chatCursors   map[string]int64             // ID задачи → последнее обработанное сообщение чата
+	relayReplies  map[string]models.RelayReply // "chat:message" Telegram → задача
+	chatRelayFile string
+
+	snapshots     map[string]models.TaskSnapshot // ключ задачи → последнее увиденное состояние
+	snapshotsFile string
+
+	sendQueue     []models.OutgoingMessage // исходящие сообщения Telegram, в порядке поступления
+	sendSeq       int64                    // последний выданный номер сообщения
+	sendQueueFile string
 
 	metrics *metrics.Metrics // Метрики хранилища
 }
 
 // NewStorage создает новое хранилище и загружает данные из указанных файлов.
-// knownTasksFile, chatIDsFile, usersFile, tasksFile, templatesFile — пути к JSON файлам.
+// knownTasksFile, chatIDsFile, usersFile, tasksFile, templatesFile — пути к JSON файлам;
+// остальные файлы (очереди, настройки, снимки задач) хранятся рядом с knownTasksFile.
 func NewStorage(knownTasksFile, chatIDsFile, usersFile, tasksFile, templatesFile string, m *metrics.Metrics) (*Storage, error) {
+	dir := filepath.Dir(knownTasksFile)
 	s := &Storage{
 		knownTasks:      make(map[string]bool),
 		chatIDs:         make([]int64, 0),
@@ -52,7 +78,26 @@ func NewStorage(knownTasksFile, chatIDsFile, usersFile, tasksFile, templatesFile
 		usersFile:       usersFile,
 		templatesFile:   templatesFile,
 		metrics:         m,
-		lastScannedFile: "data/scan_state.json",
+		lastScannedFile: filepath.Join(dir, "scan_state.json"),
+		seededScopes:    make(map[string]bool),
+
+		apiCapabilities:     make(models.APICapabilities),
+		apiCapabilitiesFile: filepath.Join(dir, "api_capabilities.json"),
+
+		outbox:     make([]models.OutboxItem, 0),
+		outboxFile: filepath.Join(dir, "outbox.json"),
+
+		settingsFile: filepath.Join(dir, "settings.json"),
+
+		chatCursors:   make(map[string]int64),
+		relayReplies:  make(map[string]models.RelayReply),
+		chatRelayFile: filepath.Join(dir, "chat_relay.json"),
+
+		snapshots:     make(map[string]models.TaskSnapshot),
+		snapshotsFile: filepath.Join(dir, "task_snapshots.json"),
+
+		sendQueue:     make([]models.OutgoingMessage, 0),
+		sendQueueFile: filepath.Join(dir, "send_queue.json"),
 	}
 
 	if err := s.loadData(); err != nil {
@@ -91,11 +136,62 @@ func (s *Storage) loadData() error {
 		return err
 	}
 	// Load scan state if present
-	var scanState struct {
-		LastScanned int `json:"last_scanned"`
-	}
+	var scanState scanState
 	if err := s.loadJSON(s.lastScannedFile, &scanState); err == nil {
 		s.lastScanned = scanState.LastScanned
+		for _, key := range scanState.SeededScopes {
+			s.seededScopes[key] = true
+		}
+	}
+	if err := s.loadJSON(s.apiCapabilitiesFile, &s.apiCapabilities); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if s.apiCapabilities == nil {
+		s.apiCapabilities = make(models.APICapabilities)
+	}
+	var outbox struct {
+		Seq   int64               `json:"seq"`
+		Items []models.OutboxItem `json:"items"`
+	}
+	if err := s.loadJSON(s.outboxFile, &outbox); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	s.outboxSeq = outbox.Seq
+	if outbox.Items != nil {
+		s.outbox = outbox.Items
+	}
+	if err := s.loadJSON(s.settingsFile, &s.settings); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	var relay struct {
+		Cursors map[string]int64             `json:"cursors"`
+		Replies map[string]models.RelayReply `json:"replies"`
+	}
+	if err := s.loadJSON(s.chatRelayFile, &relay); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if relay.Cursors != nil {
+		s.chatCursors = relay.Cursors
+	}
+	if relay.Replies != nil {
+		s.relayReplies = relay.Replies
+	}
+	if err := s.loadJSON(s.snapshotsFile, &s.snapshots); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if s.snapshots == nil {
+		s.snapshots = make(map[string]models.TaskSnapshot)
+	}
+	var sendQueue struct {
+		Seq   int64                    `json:"seq"`
+		Items []models.OutgoingMessage `json:"items"`
+	}
+	if err := s.loadJSON(s.sendQueueFile, &sendQueue); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	s.sendSeq = sendQueue.Seq
+	if sendQueue.Items != nil {
+		s.sendQueue = sendQueue.Items
 	}
 	return nil
 }
@@ -150,15 +246,55 @@ func (s *Storage) SaveData() error {
 		return err
 	}
 
+	// Очередь отложенных операций: её потеря означает потерю заявок пользователей
+	if err := s.saveJSON(s.outboxFile, map[string]interface{}{"seq": s.outboxSeq, "items": s.outbox}); err != nil {
+		if s.metrics != nil {
+			s.metrics.IncAPIErrors()
+		}
+		return err
+	}
+
+	// Очередь исходящих сообщений: при потере пропадут уведомления
+	if err := s.saveJSON(s.sendQueueFile, map[string]interface{}{"seq": s.sendSeq, "items": s.sendQueue}); err != nil {
+		if s.metrics != nil {
+			s.metrics.IncAPIErrors()
+		}
+		return err
+	}
+
+	if err := s.saveJSON(s.settingsFile, s.settings); err != nil {
+		if s.metrics != nil {
+			s.metrics.IncAPIErrors()
+		}
+		return err
+	}
+
 	s.isDirty = false
 	if s.metrics != nil {
 		s.metrics.UpdateLatency(time.Since(start))
 	}
 	// Save scan state (best-effort)
-	_ = s.saveJSON(s.lastScannedFile, map[string]int{"last_scanned": s.lastScanned})
+	state := scanState{LastScanned: s.lastScanned}
+	for key := range s.seededScopes {
+		state.SeededScopes = append(state.SeededScopes, key)
+	}
+	sort.Strings(state.SeededScopes)
+	_ = s.saveJSON(s.lastScannedFile, state)
+	// Save API capabilities (best-effort: при потере клиент просто повторит опрос)
+	_ = s.saveJSON(s.apiCapabilitiesFile, s.apiCapabilities)
+	// Save chat relay state (best-effort: при потере комментарии перешлются повторно)
+	_ = s.saveJSON(s.chatRelayFile, map[string]interface{}{"cursors": s.chatCursors, "replies": s.relayReplies})
+	// Save task snapshots (best-effort: при потере изменения задач отслеживаются с нового снимка)
+	_ = s.saveJSON(s.snapshotsFile, s.snapshots)
 	return nil
 }
 
+// scanState — состояние обхода задач в scan_state.json.
+type scanState struct {
+	LastScanned  int      `json:"last_scanned"`
+	SeededScopes []string `json:"seeded_scopes,omitempty"`
+}
+
 // GetLastScanned возвращает последний пронумерованный ITS, который мы проверяли.
 func (s *Storage) GetLastScanned() int {
 	s.mu.RLock()
@@ -360,3 +496,24 @@ func (s *Storage) UpdateTask(task *models.Task) {
 	}
 	s.isDirty = true
 }
+
+// CompleteTask отмечает выполненной задачу с ID Yougile id (внешним или числовым).
+// Возвращает false, если задача не найдена или уже выполнена.
+func (s *Storage) CompleteTask(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.tasks {
+		if t.ExternalID != id && (t.ID == 0 || strconv.FormatInt(t.ID, 10) != id) {
+			continue
+		}
+		if t.Done {
+			return false
+		}
+		t.Done = true
+		t.Status = models.TaskStatusDone
+		t.UpdatedAt = time.Now()
+		s.isDirty = true
+		return true
+	}
+	return false
+}
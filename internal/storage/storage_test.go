@@ -3,6 +3,8 @@ package storage
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,6 +12,18 @@ import (
 	"yougile_bot4/internal/models"
 )
 
+// openTestStorage открывает хранилище, все файлы которого лежат в dir.
+// Повторный вызов с тем же dir перечитывает сохранённые данные.
+func openTestStorage(t *testing.T, dir string) *Storage {
+	t.Helper()
+	s, err := NewStorage(filepath.Join(dir, "known.json"), filepath.Join(dir, "chats.json"), filepath.Join(dir, "users.json"),
+		filepath.Join(dir, "tasks.json"), filepath.Join(dir, "templates.json"), metrics.NewMetrics())
+	if err != nil {
+		t.Fatalf("NewStorage failed: %v", err)
+	}
+	return s
+}
+
 func TestStorageSaveLoadTasks(t *testing.T) {
 	dir := "data/test_storage"
 	if err := os.RemoveAll(dir); err != nil {
@@ -67,3 +81,227 @@ func TestStorageSaveLoadTasks(t *testing.T) {
 		t.Fatalf("tasks not persisted, got: %+v", tasksLoaded)
 	}
 }
+
+func TestOutboxPersistsInOrder(t *testing.T) {
+	dir := t.TempDir()
+
+	s := openTestStorage(t, dir)
+	create := s.EnqueueOutbox(models.OutboxItem{Kind: models.OutboxCreateTask, Task: &models.Task{Title: "t"}})
+	s.EnqueueOutbox(models.OutboxItem{Kind: models.OutboxAddComment, TaskRef: create, Comment: &models.Comment{Text: "c"}})
+	if err := s.SaveData(); err != nil {
+		t.Fatalf("SaveData failed: %v", err)
+	}
+
+	s2 := openTestStorage(t, dir)
+	items := s2.GetOutbox()
+	if len(items) != 2 || items[0].Kind != models.OutboxCreateTask || items[1].TaskRef != create {
+		t.Fatalf("outbox not persisted in order: %+v", items)
+	}
+
+	s2.ResolveOutboxRef(create, "uuid-1")
+	s2.RemoveOutbox(create)
+	next := s2.EnqueueOutbox(models.OutboxItem{Kind: models.OutboxAddComment})
+	if next <= items[1].ID {
+		t.Fatalf("sequence must keep growing after reload, got %d", next)
+	}
+	items = s2.GetOutbox()
+	if len(items) != 2 || items[0].TaskID != "uuid-1" || items[0].TaskRef != 0 {
+		t.Fatalf("unexpected outbox after resolve: %+v", items)
+	}
+}
+
+func TestSettingsPersistLinksAndExecutor(t *testing.T) {
+	dir := t.TempDir()
+
+	s := openTestStorage(t, dir)
+	s.SetTargetColumn(models.TargetColumn{BoardID: "b1", ColumnID: "c1", ColumnTitle: "Новые"})
+	s.LinkYougileUser(models.UserLink{TelegramID: 42, YougileUserID: "u1", RealName: "Иванов Иван"})
+	s.SetDefaultExecutor(42)
+	if err := s.SaveData(); err != nil {
+		t.Fatalf("SaveData failed: %v", err)
+	}
+
+	s2 := openTestStorage(t, dir)
+	if tc, ok := s2.GetTargetColumn(); !ok || tc.ColumnID != "c1" {
+		t.Fatalf("target column not persisted: %+v", tc)
+	}
+	if link, ok := s2.GetDefaultExecutor(); !ok || link.YougileUserID != "u1" {
+		t.Fatalf("default executor not persisted: %+v", link)
+	}
+	if link, ok := s2.FindYougileLink("u1"); !ok || link.TelegramID != 42 {
+		t.Fatalf("link not found by Yougile ID: %+v", link)
+	}
+	if !s2.UnlinkYougileUser(42) {
+		t.Fatalf("UnlinkYougileUser returned false")
+	}
+	if _, ok := s2.GetDefaultExecutor(); ok {
+		t.Fatalf("default executor must be cleared with its link")
+	}
+}
+
+func TestBoardRoutesAndScopedKnownKeys(t *testing.T) {
+	dir := t.TempDir()
+
+	s := openTestStorage(t, dir)
+	s.SetBoardRoute(models.BoardRoute{ID: "hr", Title: "Кадры", BoardID: "b2", ChatIDs: []int64{7}})
+	s.SetBoardRoute(models.BoardRoute{ID: "it", Title: "ИТ", BoardID: "b3", ColumnID: "c3"})
+	s.SetBoardRoute(models.BoardRoute{ID: "hr", Title: "Отдел кадров", BoardID: "b2", ChatIDs: []int64{7, 8}})
+	s.AddKnownKey("legacy")
+	s.AddKnownBoardKey("b2", "t1")
+	if err := s.SaveData(); err != nil {
+		t.Fatalf("SaveData failed: %v", err)
+	}
+
+	s2 := openTestStorage(t, dir)
+	routes := s2.GetBoardRoutes()
+	if len(routes) != 2 || routes[0].Title != "Отдел кадров" || len(routes[0].ChatIDs) != 2 {
+		t.Fatalf("board routes not persisted: %+v", routes)
+	}
+	routes[0].ChatIDs[0] = 99
+	if r, _ := s2.GetBoardRoute("hr"); r.ChatIDs[0] != 7 {
+		t.Fatalf("GetBoardRoutes must return copies, got %+v", r)
+	}
+	if !s2.IsKnownBoardKey("b2", "t1") || s2.IsKnownBoardKey("b3", "t1") || s2.IsKnownKey("t1") {
+		t.Fatalf("known keys must be scoped by board")
+	}
+	if !s2.IsKnownBoardKey("b3", "legacy") {
+		t.Fatalf("legacy keys must stay known on every board")
+	}
+	if !s2.RemoveBoardRoute("hr") || s2.RemoveBoardRoute("hr") {
+		t.Fatalf("RemoveBoardRoute must remove the route once")
+	}
+	if _, ok := s2.GetBoardRoute("hr"); ok {
+		t.Fatalf("removed route still present")
+	}
+}
+
+func TestSeededScopesPersistAndAdopt(t *testing.T) {
+	dir := t.TempDir()
+
+	s := openTestStorage(t, dir)
+	if s.AdoptSeededScopes([]string{"b1/c1"}) {
+		t.Fatalf("fresh storage must not adopt boards as scanned")
+	}
+	s.AddKnownKey("legacy")
+	if !s.AdoptSeededScopes([]string{"b1/c1"}) || !s.IsScopeSeeded("b1/c1") || s.IsScopeSeeded("b2/") {
+		t.Fatalf("boards of data saved before seeding must be adopted")
+	}
+	s.MarkScopeSeeded("b2/")
+	if err := s.SaveData(); err != nil {
+		t.Fatalf("SaveData failed: %v", err)
+	}
+
+	s2 := openTestStorage(t, dir)
+	if !s2.IsScopeSeeded("b1/c1") || !s2.IsScopeSeeded("b2/") {
+		t.Fatalf("seeded scopes not persisted")
+	}
+	if s2.AdoptSeededScopes([]string{"b3/"}) || s2.IsScopeSeeded("b3/") {
+		t.Fatalf("new boards must not be adopted once seeding is recorded")
+	}
+}
+
+func TestTaskSnapshotsPersistAndPrune(t *testing.T) {
+	dir := t.TempDir()
+
+	now := time.Now()
+	s := openTestStorage(t, dir)
+	s.SetTaskSnapshot(models.TaskSnapshot{Key: "t1", BoardID: "b1", ColumnID: "c1", SeenAt: now})
+	s.SetTaskSnapshot(models.TaskSnapshot{Key: "t2", BoardID: "b1", Done: true, SeenAt: now})
+	s.SetTaskSnapshot(models.TaskSnapshot{Key: "t3", BoardID: "b1", SeenAt: now.Add(-48 * time.Hour)})
+	if err := s.SaveData(); err != nil {
+		t.Fatalf("SaveData failed: %v", err)
+	}
+
+	s2 := openTestStorage(t, dir)
+	if snap, ok := s2.GetTaskSnapshot("t1"); !ok || snap.ColumnID != "c1" {
+		t.Fatalf("snapshot not persisted: %+v", snap)
+	}
+	if open := s2.ListTaskSnapshots("b1"); len(open) != 2 {
+		t.Fatalf("expected two open snapshots, got %+v", open)
+	}
+	if n := s2.PruneTaskSnapshots(now.Add(-time.Hour)); n != 1 {
+		t.Fatalf("expected one pruned snapshot, got %d", n)
+	}
+	s2.MarkTaskSnapshotChecked("t1", now)
+	if snap, _ := s2.GetTaskSnapshot("t1"); !snap.CheckedAt.Equal(now) {
+		t.Fatalf("CheckedAt not updated: %+v", snap)
+	}
+	s2.RemoveTaskSnapshot("t1")
+	if _, ok := s2.GetTaskSnapshot("t1"); ok {
+		t.Fatalf("removed snapshot still present")
+	}
+}
+
+func TestChatRulesPersistAndRemove(t *testing.T) {
+	dir := t.TempDir()
+
+	done := false
+	s := openTestStorage(t, dir)
+	s.AddChatRule(models.ChatRule{ChatID: 10, Labels: []string{"Сантехника"}})
+	s.AddChatRule(models.ChatRule{ChatID: 20, Priorities: []int{1}})
+	s.AddChatRule(models.ChatRule{ChatID: 10, Buildings: []string{"Корпус 2"}, Done: &done})
+	if err := s.SaveData(); err != nil {
+		t.Fatalf("SaveData failed: %v", err)
+	}
+
+	s = openTestStorage(t, dir)
+	own := s.GetChatRulesFor(10)
+	if len(own) != 2 || own[1].Buildings[0] != "Корпус 2" || own[1].Done == nil || *own[1].Done {
+		t.Fatalf("unexpected rules after reload: %+v", own)
+	}
+	if n := s.RemoveChatRule(10, 0); n != 1 {
+		t.Fatalf("expected one removed rule, got %d", n)
+	}
+	if own := s.GetChatRulesFor(10); len(own) != 1 || own[0].Buildings[0] != "Корпус 2" {
+		t.Fatalf("expected the second rule to remain, got %+v", own)
+	}
+	if n := s.RemoveChatRule(10, 5); n != 0 {
+		t.Fatalf("expected nothing removed for a missing rule, got %d", n)
+	}
+	if n := s.RemoveChatRule(10, -1); n != 1 || len(s.GetChatRules()) != 1 {
+		t.Fatalf("expected only the other chat's rule to remain, got %+v", s.GetChatRules())
+	}
+}
+
+func TestSendQueuePersistsAndRemoveChat(t *testing.T) {
+	dir := t.TempDir()
+
+	s := openTestStorage(t, dir)
+	first := s.EnqueueMessage(models.OutgoingMessage{ChatID: -5, Text: "группа", Markup: []byte(`{"inline_keyboard":[]}`)})
+	s.EnqueueMessage(models.OutgoingMessage{ChatID: 7, Text: "комментарий", Relay: &models.RelayReply{TaskID: "t1"}})
+	s.EnqueueMessage(models.OutgoingMessage{ChatID: -5, Text: "ещё"})
+	// Очередь сохраняется сразу, без SaveData
+	if err := s.SaveSendQueue(); err != nil {
+		t.Fatalf("SaveSendQueue failed: %v", err)
+	}
+
+	s = openTestStorage(t, dir)
+	queue := s.GetSendQueue()
+	if len(queue) != 3 || queue[0].ID != first || !strings.Contains(string(queue[0].Markup), "inline_keyboard") || len(queue[2].Markup) != 0 ||
+		queue[1].Relay == nil || queue[1].Relay.TaskID != "t1" {
+		t.Fatalf("unexpected queue after reload: %+v", queue)
+	}
+	queue[0].Attempts = 2
+	s.UpdateMessage(queue[0])
+	if n := s.RemoveChatMessages(-5); n != 2 || s.SendQueueLen() != 1 {
+		t.Fatalf("expected two messages removed, got %d (left %d)", n, s.SendQueueLen())
+	}
+	if next := s.EnqueueMessage(models.OutgoingMessage{ChatID: 7}); next != first+3 {
+		t.Fatalf("expected sequence to continue after reload, got %d", next)
+	}
+
+	s.AddChatID(-5)
+	s.AddChatID(9)
+	s.SetBoardRoute(models.BoardRoute{ID: "it", BoardID: "b1", ChatIDs: []int64{-5, 9}})
+	s.AddChatRule(models.ChatRule{ChatID: -5, Priorities: []int{1}})
+	if !s.RemoveChat(-5) {
+		t.Fatalf("expected chat to be removed")
+	}
+	if s.RemoveChat(-5) {
+		t.Fatalf("expected nothing to remove the second time")
+	}
+	route, _ := s.GetBoardRoute("it")
+	if ids := s.GetChatIDs(); len(ids) != 1 || ids[0] != 9 || len(route.ChatIDs) != 1 || len(s.GetChatRules()) != 0 {
+		t.Fatalf("chat must disappear from all lists, got chats=%v route=%v rules=%v", ids, route.ChatIDs, s.GetChatRules())
+	}
+}
@@ -0,0 +1,88 @@
+// Package storage содержит методы хранения связей пользователей Telegram с сотрудниками Yougile.
+package storage
+
+import (
+	"sort"
+
+	"yougile_bot4/internal/models"
+)
+
+// LinkYougileUser связывает пользователя Telegram с сотрудником Yougile,
+// заменяя прежнюю связь этого пользователя.
+func (s *Storage) LinkYougileUser(link models.UserLink) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.settings.UserLinks == nil {
+		s.settings.UserLinks = make(map[int64]models.UserLink)
+	}
+	s.settings.UserLinks[link.TelegramID] = link
+	s.isDirty = true
+}
+
+// UnlinkYougileUser удаляет связь пользователя Telegram с сотрудником Yougile.
+// Если пользователь был исполнителем по умолчанию, назначение снимается.
+// Возвращает false, если связи не было.
+func (s *Storage) UnlinkYougileUser(telegramID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.settings.UserLinks[telegramID]; !ok {
+		return false
+	}
+	delete(s.settings.UserLinks, telegramID)
+	if s.settings.DefaultExecutor == telegramID {
+		s.settings.DefaultExecutor = 0
+	}
+	s.isDirty = true
+	return true
+}
+
+// GetYougileLink возвращает связь пользователя Telegram с сотрудником Yougile.
+func (s *Storage) GetYougileLink(telegramID int64) (models.UserLink, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	link, ok := s.settings.UserLinks[telegramID]
+	return link, ok
+}
+
+// FindYougileLink ищет связь по идентификатору сотрудника Yougile.
+func (s *Storage) FindYougileLink(yougileUserID string) (models.UserLink, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, link := range s.settings.UserLinks {
+		if link.YougileUserID == yougileUserID {
+			return link, true
+		}
+	}
+	return models.UserLink{}, false
+}
+
+// GetYougileLinks возвращает все связи, упорядоченные по Telegram ID.
+func (s *Storage) GetYougileLinks() []models.UserLink {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]models.UserLink, 0, len(s.settings.UserLinks))
+	for _, link := range s.settings.UserLinks {
+		result = append(result, link)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].TelegramID < result[j].TelegramID })
+	return result
+}
+
+// SetDefaultExecutor задаёт исполнителя новых задач по Telegram ID (0 — не назначать).
+func (s *Storage) SetDefaultExecutor(telegramID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settings.DefaultExecutor = telegramID
+	s.isDirty = true
+}
+
+// GetDefaultExecutor возвращает связь исполнителя новых задач и флаг, задан ли он.
+func (s *Storage) GetDefaultExecutor() (models.UserLink, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.settings.DefaultExecutor == 0 {
+		return models.UserLink{}, false
+	}
+	link, ok := s.settings.UserLinks[s.settings.DefaultExecutor]
+	return link, ok
+}
@@ -0,0 +1,247 @@
+// Package watcher содержит источники задач: постраничный опрос списков задач
+// отслеживаемых досок и перебор коротких ключей задач.
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"yougile_bot4/internal/api"
+	"yougile_bot4/internal/changes"
+	"yougile_bot4/internal/models"
+)
+
+const (
+	// maxVanishedChecks — сколько пропавших из списка задач запрашивается за один опрос доски.
+	maxVanishedChecks = 20
+	// ownedCheckInterval — как часто запрашиваются заявки, созданные ботом и ушедшие
+	// из отслеживаемой колонки: их авторы ждут сообщения о выполнении.
+	ownedCheckInterval = 10 * time.Minute
+	// keyIdleAfter — через сколько времени без находок перебор ключей замедляется.
+	keyIdleAfter = time.Minute
+)
+
+// SnapshotLister даёт доступ к снимкам задач доски для проверки пропавших задач.
+type SnapshotLister interface {
+	ListTaskSnapshots(boardID string) []models.TaskSnapshot
+	RemoveTaskSnapshot(key string)
+	MarkTaskSnapshotChecked(key string, at time.Time)
+}
+
+// ListSource постранично обходит задачи всех отслеживаемых досок (или колонок).
+type ListSource struct {
+	Client *api.Client
+	// Boards возвращает отслеживаемые доски на момент опроса.
+	Boards func() []models.BoardRoute
+	// PageSize — размер страницы списка задач.
+	PageSize int
+	// Snapshots — снимки задач для проверки пропавших из списка (nil — не проверять).
+	Snapshots SnapshotLister
+	// Fallback запускается, если основная доска вернула пустой список
+	// (например, перебор ключей ITS-N); nil — не запускается.
+	Fallback Source
+}
+
+// Name возвращает имя источника.
+func (s *ListSource) Name() string { return "list" }
+
+// Scan обходит доски по очереди. Ошибка одной доски не мешает проверке
+// остальных; отказ в авторизации прерывает обход сразу.
+func (s *ListSource) Scan(ctx context.Context, emit func(Found)) error {
+	var firstErr error
+	for _, board := range s.Boards() {
+		err := s.scanBoard(ctx, board, emit)
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, api.ErrUnauthorized) || ctx.Err() != nil {
+			return err
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// scanBoard обходит задачи одной доски и проверяет пропавшие из списка задачи.
+func (s *ListSource) scanBoard(ctx context.Context, board models.BoardRoute, emit func(Found)) error {
+	filter := api.TaskFilter{BoardID: board.BoardID, ColumnID: board.ColumnID, PageSize: s.PageSize}
+	seen := make(map[string]bool)
+
+	total, err := s.scanList(ctx, board, filter, seen, emit)
+	if err != nil {
+		return fmt.Errorf("ошибка получения задач доски %s: %w", board.BoardID, err)
+	}
+	log.Printf("watcher: доска %s: %d задач получено", board.BoardID, total)
+
+	// Fallback: if column-scoped fetch returned no tasks but a column is set,
+	// try fetching without column filter (some Yougile instances don't return tasks
+	// for column-scoped queries reliably).
+	if total == 0 && filter.ColumnID != "" {
+		filter.ColumnID = ""
+		broader, berr := s.scanList(ctx, board, filter, seen, emit)
+		if berr == nil {
+			log.Printf("watcher: fallback %d задач", broader)
+		}
+		// If still empty, probe numeric ITS keys to discover manual tasks
+		// (только для основной доски: номера ITS-N относятся к ней)
+		if broader == 0 && s.Fallback != nil && board.ID == "" {
+			if ferr := s.Fallback.Scan(ctx, emit); ferr != nil {
+				log.Printf("watcher: %s: %v", s.Fallback.Name(), ferr)
+			}
+		}
+	}
+
+	// Пустой список может быть сбоем API: пропавшие задачи проверяются, только
+	// если список что-то вернул
+	if len(seen) > 0 && s.Snapshots != nil {
+		s.checkVanished(ctx, board, seen, emit)
+	}
+	return nil
+}
+
+// scanList обходит все страницы списка задач по фильтру; ключи задач добавляются в seen.
+func (s *ListSource) scanList(ctx context.Context, board models.BoardRoute, filter api.TaskFilter, seen map[string]bool, emit func(Found)) (int, error) {
+	total := 0
+	it := s.Client.IterateTasks(ctx, filter)
+	for it.Next() {
+		task := it.Task()
+		total++
+		seen[changes.TaskKey(task)] = true
+		emit(Found{Board: board, Task: task})
+	}
+	return total, it.Err()
+}
+
+// checkVanished запрашивает по одной незавершённые задачи доски, которые
+// встречались в прошлых опросах, но пропали из списка: так замечаются перемещения
+// из отслеживаемой колонки, выполнение, архивирование и удаление. Заявки авторов,
+// уже ушедшие из колонки, запрашиваются раз в ownedCheckInterval.
+func (s *ListSource) checkVanished(ctx context.Context, board models.BoardRoute, seen map[string]bool, emit func(Found)) {
+	checked := 0
+	for _, snap := range s.Snapshots.ListTaskSnapshots(board.BoardID) {
+		if seen[snap.Key] {
+			continue
+		}
+		outside := board.ColumnID != "" && snap.ColumnID != board.ColumnID
+		if outside && (snap.RequesterID == 0 || time.Since(snap.CheckedAt) < ownedCheckInterval) {
+			continue
+		}
+		if checked >= maxVanishedChecks {
+			return
+		}
+		checked++
+		task, err := s.Client.FetchTask(ctx, snap.Key)
+		if errors.Is(err, api.ErrNotFound) {
+			s.Snapshots.RemoveTaskSnapshot(snap.Key)
+			continue
+		}
+		if err != nil {
+			log.Printf("watcher: ошибка получения задачи %s: %v", snap.Key, err)
+			if errors.Is(err, api.ErrUnauthorized) || ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+		emit(Found{Board: board, Task: *task})
+		if outside {
+			s.Snapshots.MarkTaskSnapshotChecked(snap.Key, time.Now())
+		}
+	}
+}
+
+// KeyProgress хранит последний проверенный номер короткого ключа.
+type KeyProgress interface {
+	GetLastScanned() int
+	SetLastScanned(v int)
+}
+
+// KeySource перебирает короткие ключи задач (ITS-1, ITS-2, ...) начиная с
+// последнего найденного: так находятся задачи, которых нет в списке.
+type KeySource struct {
+	// Label — имя источника (например, "numeric" или "fullscan").
+	Label    string
+	Client   *api.Client
+	Progress KeyProgress
+	// Board возвращает доску, к которой относятся ключи (nil — основная без доски).
+	Board func() models.BoardRoute
+	// Prefix — префикс ключей, например "ITS-".
+	Prefix string
+	// Range — сколько номеров проверяется за обход.
+	Range int
+	// Pace — пауза между запросами, пока находятся задачи (0 — без пауз);
+	// после минуты без находок пауза увеличивается до IdlePace.
+	Pace     time.Duration
+	IdlePace time.Duration
+}
+
+// Name возвращает имя источника.
+func (s *KeySource) Name() string {
+	if s.Label != "" {
+		return s.Label
+	}
+	return "keys"
+}
+
+// Scan проверяет Range номеров после последнего найденного.
+func (s *KeySource) Scan(ctx context.Context, emit func(Found)) error {
+	last := s.Progress.GetLastScanned()
+	if last < 0 {
+		last = 0
+	}
+	var board models.BoardRoute
+	if s.Board != nil {
+		board = s.Board()
+	}
+	log.Printf("watcher: %s: проверка %s%d..%s%d", s.Name(), s.Prefix, last+1, s.Prefix, last+s.Range)
+
+	pace := s.Pace
+	var idleSince time.Time
+	for n := last + 1; n <= last+s.Range; n++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		key := fmt.Sprintf("%s%d", s.Prefix, n)
+		task, err := s.Client.GetTaskByIDQuiet(ctx, key)
+		switch {
+		case errors.Is(err, api.ErrUnauthorized):
+			return err
+		case err == nil && task != nil:
+			if task.Key == "" {
+				task.Key = key
+			}
+			emit(Found{Board: board, Task: *task})
+			s.Progress.SetLastScanned(n)
+			idleSince = time.Time{}
+			pace = s.Pace
+		default:
+			if idleSince.IsZero() {
+				idleSince = time.Now()
+			} else if s.IdlePace > 0 && time.Since(idleSince) > keyIdleAfter {
+				pace = s.IdlePace
+			}
+		}
+		if pace > 0 {
+			if err := sleep(ctx, pace); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sleep ожидает d или отмены ctx (тогда возвращает ctx.Err()).
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,410 @@
+// Package watcher отслеживает задачи Yougile: опрашивает подключённые источники
+// (список задач досок, перебор коротких ключей, события вебхуков), один раз
+// определяет, новая ли задача, уведомляет о новых задачах и передаёт изменения
+// известных. Запуском, остановкой и состоянием управляют main и команды
+// администраторов бота.
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"yougile_bot4/internal/api"
+	"yougile_bot4/internal/changes"
+	"yougile_bot4/internal/models"
+)
+
+const (
+	// AuthPause — пауза плановых проверок после ответа 401 от Yougile,
+	// чтобы не нагружать API запросами с отозванным ключом.
+	AuthPause = 30 * time.Minute
+	// SnapshotTTL — сколько хранится снимок задачи, которая больше не встречается.
+	SnapshotTTL = 30 * 24 * time.Hour
+)
+
+// Store хранит известные задачи и снимки их состояния (реализуется storage.Storage).
+type Store interface {
+	changes.Store
+	IsKnownBoardKey(boardID, key string) bool
+	AddKnownBoardKey(boardID, key string)
+	PruneTaskSnapshots(before time.Time) int
+	// IsScopeSeeded сообщает, завершён ли первый обход доски (см. SeedKey).
+	IsScopeSeeded(key string) bool
+	MarkScopeSeeded(key string)
+}
+
+// Notifier получает результаты отслеживания (реализуется ботом).
+type Notifier interface {
+	// NotifyNewTask сообщает о новой незавершённой задаче.
+	NotifyNewTask(task models.Task)
+	// HandleTaskChanges обрабатывает изменения известных задач.
+	HandleTaskChanges(changes []models.TaskChange)
+	// ReportAPIError сообщает администраторам об отказе Yougile в доступе.
+	ReportAPIError(err error)
+}
+
+// Found — задача, найденная источником, и отслеживаемая доска, к которой она относится.
+type Found struct {
+	Board models.BoardRoute
+	Task  models.Task
+}
+
+// Source — источник задач. Scan передаёт найденные задачи в emit и возвращает
+// ошибку обращения к Yougile; при отмене ctx обход прекращается.
+type Source interface {
+	Name() string
+	Scan(ctx context.Context, emit func(Found)) error
+}
+
+// SourceStatus — состояние источника и итоги его последнего запуска.
+type SourceStatus struct {
+	Name string
+	// Job — разовый запуск (например, fullscan), а не плановый источник.
+	Job       bool
+	Running   bool
+	LastStart time.Time
+	LastEnd   time.Time
+	LastErr   string
+	Seen      int // задач найдено
+	New       int // из них новых
+	Notified  int // уведомлений отправлено
+}
+
+// Status — состояние наблюдателя.
+type Status struct {
+	Running     bool
+	Interval    time.Duration
+	PausedUntil time.Time
+	Sources     []SourceStatus
+}
+
+// Watcher периодически опрашивает плановые источники и выполняет разовые задания.
+type Watcher struct {
+	store    Store
+	notifier Notifier
+	interval time.Duration
+
+	// procMu делает проверку «новая ли задача» и сохранение снимка атомарными
+	// для всех источников, включая вебхуки
+	procMu sync.Mutex
+
+	mu          sync.Mutex
+	sources     []Source
+	status      map[string]*SourceStatus
+	jobs        map[string]context.CancelFunc
+	cancel      context.CancelFunc
+	done        chan struct{}
+	pausedUntil time.Time
+	wg          sync.WaitGroup
+}
+
+// New создаёт наблюдатель с интервалом плановых проверок interval.
+func New(store Store, notifier Notifier, interval time.Duration) *Watcher {
+	return &Watcher{
+		store:    store,
+		notifier: notifier,
+		interval: interval,
+		status:   make(map[string]*SourceStatus),
+		jobs:     make(map[string]context.CancelFunc),
+	}
+}
+
+// AddSource подключает плановый источник. Вызывается до Start.
+func (w *Watcher) AddSource(src Source) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.sources = append(w.sources, src)
+	w.status[src.Name()] = &SourceStatus{Name: src.Name()}
+}
+
+// Start запускает плановые проверки: первую сразу, затем каждые interval.
+func (w *Watcher) Start(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cancel != nil {
+		return fmt.Errorf("наблюдатель уже запущен")
+	}
+	ctx, cancel := context.WithCancel(api.Background(ctx))
+	w.cancel = cancel
+	w.done = make(chan struct{})
+	go w.loop(ctx, w.done)
+	return nil
+}
+
+// Stop останавливает плановые проверки и разовые задания и дожидается их завершения.
+func (w *Watcher) Stop() {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.cancel, w.done = nil, nil
+	for _, stop := range w.jobs {
+		stop()
+	}
+	w.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+	w.wg.Wait()
+}
+
+// Running сообщает, запущены ли плановые проверки.
+func (w *Watcher) Running() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.cancel != nil
+}
+
+// loop выполняет плановые проверки до отмены ctx.
+func (w *Watcher) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.mu.Lock()
+		paused := time.Now().Before(w.pausedUntil)
+		w.mu.Unlock()
+		if !paused {
+			if err := w.RunOnce(ctx); errors.Is(err, api.ErrUnauthorized) {
+				until := time.Now().Add(AuthPause)
+				w.mu.Lock()
+				w.pausedUntil = until
+				w.mu.Unlock()
+				log.Printf("watcher: ключ Yougile отклонён, проверки приостановлены до %s", until.Format("15:04:05"))
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RunOnce один раз опрашивает все плановые источники (например, по команде
+// /rescan). Ошибка одного источника не мешает остальным; отказ в авторизации
+// прерывает проверку сразу. Возвращает первую ошибку.
+func (w *Watcher) RunOnce(ctx context.Context) error {
+	w.mu.Lock()
+	sources := append([]Source(nil), w.sources...)
+	w.mu.Unlock()
+
+	var firstErr error
+	for _, src := range sources {
+		err := w.run(ctx, src, false)
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, api.ErrUnauthorized) || ctx.Err() != nil {
+			return err
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if n := w.store.PruneTaskSnapshots(time.Now().Add(-SnapshotTTL)); n > 0 {
+		log.Printf("watcher: удалено устаревших снимков задач: %d", n)
+	}
+	return firstErr
+}
+
+// StartJob запускает разовый обход источником src в фоне (например, fullscan).
+// Одновременно может работать только одно задание с тем же именем.
+func (w *Watcher) StartJob(ctx context.Context, src Source) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.jobs[src.Name()]; ok {
+		return fmt.Errorf("%s уже запущен", src.Name())
+	}
+	ctx, cancel := context.WithCancel(api.Background(ctx))
+	w.jobs[src.Name()] = cancel
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer cancel()
+		if err := w.run(ctx, src, true); err != nil && ctx.Err() == nil {
+			log.Printf("watcher: задание %s завершилось с ошибкой: %v", src.Name(), err)
+		}
+		w.mu.Lock()
+		delete(w.jobs, src.Name())
+		w.mu.Unlock()
+	}()
+	return nil
+}
+
+// StopJob прерывает разовое задание name.
+func (w *Watcher) StopJob(name string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	cancel, ok := w.jobs[name]
+	if !ok {
+		return fmt.Errorf("%s не запущен", name)
+	}
+	cancel()
+	delete(w.jobs, name)
+	return nil
+}
+
+// Status возвращает состояние наблюдателя и его источников.
+func (w *Watcher) Status() Status {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	st := Status{Running: w.cancel != nil, Interval: w.interval, PausedUntil: w.pausedUntil}
+	for _, s := range w.status {
+		st.Sources = append(st.Sources, *s)
+	}
+	sort.Slice(st.Sources, func(i, j int) bool {
+		if st.Sources[i].Job != st.Sources[j].Job {
+			return !st.Sources[i].Job
+		}
+		return st.Sources[i].Name < st.Sources[j].Name
+	})
+	return st
+}
+
+// run выполняет один обход источника src и записывает его итоги в состояние.
+func (w *Watcher) run(ctx context.Context, src Source, job bool) error {
+	name := src.Name()
+	w.mu.Lock()
+	st := &SourceStatus{Name: name, Job: job, Running: true, LastStart: time.Now()}
+	w.status[name] = st
+	w.mu.Unlock()
+
+	scanned := make(map[string]bool)
+	err := src.Scan(ctx, func(f Found) {
+		isNew, sent := w.Handle(f)
+		w.mu.Lock()
+		scanned[SeedKey(f.Board)] = true
+		st.Seen++
+		if isNew {
+			st.New++
+		}
+		if sent {
+			st.Notified++
+		}
+		w.mu.Unlock()
+	})
+	if errors.Is(err, api.ErrUnauthorized) {
+		w.notifier.ReportAPIError(err)
+	}
+	if err != nil && ctx.Err() == nil {
+		log.Printf("watcher: источник %s: %v", name, err)
+	}
+	// После первого полного планового обхода о новых задачах доски начинают
+	// приходить уведомления; разовые задания (fullscan) видят не все задачи доски
+	if err == nil && ctx.Err() == nil && !job {
+		for key := range scanned {
+			if !w.store.IsScopeSeeded(key) {
+				w.store.MarkScopeSeeded(key)
+				log.Printf("watcher: первый обход доски %s завершён, уведомления включены", key)
+			}
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if st.New > 0 {
+		log.Printf("watcher: источник %s: задач %d, новых %d, уведомлений %d", name, st.Seen, st.New, st.Notified)
+	}
+	st.Running = false
+	st.LastEnd = time.Now()
+	switch {
+	case ctx.Err() != nil:
+		st.LastErr = "остановлен"
+	case err != nil:
+		st.LastErr = err.Error()
+	}
+	return err
+}
+
+// Handle обрабатывает найденную задачу: запоминает её как известную на доске,
+// отслеживает изменения и уведомляет о новой незавершённой задаче. Пока первый
+// плановый обход доски не завершён, задачи только запоминаются: иначе новая доска
+// (или первый запуск) разослала бы уведомления обо всех открытых задачах.
+// Используется источниками и обработчиком вебхуков. Возвращает признаки новой
+// задачи и отправленного уведомления.
+func (w *Watcher) Handle(f Found) (bool, bool) {
+	task := f.Task
+	if task.BoardID == "" {
+		task.BoardID = f.Board.BoardID
+	}
+	ids := Identities(task)
+	if len(ids) == 0 {
+		return false, false
+	}
+	scope := Scope(f.Board)
+
+	w.procMu.Lock()
+	known := false
+	for _, id := range ids {
+		if w.store.IsKnownBoardKey(scope, id) {
+			known = true
+			break
+		}
+	}
+	if !known {
+		for _, id := range ids {
+			w.store.AddKnownBoardKey(scope, id)
+		}
+	}
+	found := changes.Track(w.store, task, time.Now())
+	seeded := w.store.IsScopeSeeded(SeedKey(f.Board))
+	w.procMu.Unlock()
+
+	w.notifier.HandleTaskChanges(found)
+	if known || task.Done || !seeded {
+		return !known, false
+	}
+	w.notifier.NotifyNewTask(task)
+	return true, true
+}
+
+// Track передаёт изменения задачи, у которой уже есть снимок (например, из
+// события вебхука о задаче вне отслеживаемых колонок).
+func (w *Watcher) Track(task models.Task) {
+	w.procMu.Lock()
+	if _, ok := w.store.GetTaskSnapshot(changes.TaskKey(task)); !ok {
+		w.procMu.Unlock()
+		return
+	}
+	found := changes.Track(w.store, task, time.Now())
+	w.procMu.Unlock()
+	w.notifier.HandleTaskChanges(found)
+}
+
+// Scope возвращает доску, в рамках которой запоминаются известные задачи:
+// для основной доски ключи хранятся без доски, как до появления нескольких досок.
+func Scope(board models.BoardRoute) string {
+	if board.ID == "" {
+		return ""
+	}
+	return board.BoardID
+}
+
+// SeedKey возвращает ключ отслеживаемой доски (или колонки) для учёта первого
+// обхода: новая колонка той же доски тоже обходится сначала без уведомлений.
+func SeedKey(board models.BoardRoute) string {
+	return board.BoardID + "/" + board.ColumnID
+}
+
+// Identities возвращает все идентификаторы задачи (ExternalID, короткий ключ,
+// числовой ID): задача известна, если известен любой из них, — так одна задача,
+// найденная разными источниками, не даёт повторного уведомления.
+func Identities(task models.Task) []string {
+	var ids []string
+	if task.ExternalID != "" {
+		ids = append(ids, task.ExternalID)
+	}
+	if task.Key != "" {
+		ids = append(ids, task.Key)
+	}
+	if task.ID != 0 {
+		ids = append(ids, strconv.FormatInt(task.ID, 10))
+	}
+	return ids
+}
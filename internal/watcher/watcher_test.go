@@ -0,0 +1,343 @@
+// Package watcher содержит тесты наблюдателя за задачами и его источников.
+package watcher
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"yougile_bot4/internal/api"
+	"yougile_bot4/internal/metrics"
+	"yougile_bot4/internal/models"
+)
+
+// memStore — хранилище известных задач и снимков в памяти для тестов.
+type memStore struct {
+	mu        sync.Mutex
+	known     map[string]bool
+	snapshots map[string]models.TaskSnapshot
+	seeded    map[string]bool
+	last      int
+}
+
+func newMemStore() *memStore {
+	return &memStore{known: make(map[string]bool), snapshots: make(map[string]models.TaskSnapshot), seeded: make(map[string]bool)}
+}
+
+// newSeededStore возвращает хранилище, в котором первый обход досок boards уже завершён.
+func newSeededStore(boards ...models.BoardRoute) *memStore {
+	m := newMemStore()
+	for _, b := range boards {
+		m.seeded[SeedKey(b)] = true
+	}
+	return m
+}
+
+func (m *memStore) GetTaskSnapshot(key string) (models.TaskSnapshot, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snap, ok := m.snapshots[key]
+	return snap, ok
+}
+
+func (m *memStore) SetTaskSnapshot(snap models.TaskSnapshot) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.snapshots[snap.Key] = snap
+}
+
+func (m *memStore) IsKnownBoardKey(boardID, key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.known[boardID+":"+key]
+}
+
+func (m *memStore) AddKnownBoardKey(boardID, key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.known[boardID+":"+key] = true
+}
+
+func (m *memStore) PruneTaskSnapshots(before time.Time) int { return 0 }
+
+func (m *memStore) IsScopeSeeded(key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.seeded[key]
+}
+
+func (m *memStore) MarkScopeSeeded(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.seeded[key] = true
+}
+
+func (m *memStore) GetLastScanned() int { return m.last }
+
+func (m *memStore) SetLastScanned(v int) { m.last = v }
+
+// fakeNotifier запоминает уведомления наблюдателя.
+type fakeNotifier struct {
+	mu      sync.Mutex
+	created []models.Task
+	changes []models.TaskChange
+	errs    []error
+}
+
+func (n *fakeNotifier) NotifyNewTask(task models.Task) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.created = append(n.created, task)
+}
+
+func (n *fakeNotifier) HandleTaskChanges(changes []models.TaskChange) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.changes = append(n.changes, changes...)
+}
+
+func (n *fakeNotifier) ReportAPIError(err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.errs = append(n.errs, err)
+}
+
+// fakeSource отдаёт заданные задачи; block — ждать отмены контекста после них.
+type fakeSource struct {
+	name  string
+	found []Found
+	err   error
+	block bool
+}
+
+func (s *fakeSource) Name() string { return s.name }
+
+func (s *fakeSource) Scan(ctx context.Context, emit func(Found)) error {
+	for _, f := range s.found {
+		emit(f)
+	}
+	if s.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return s.err
+}
+
+func TestHandleDedupsAcrossIdentities(t *testing.T) {
+	board := models.BoardRoute{ID: "", BoardID: "b1", ColumnID: "c1"}
+	other := models.BoardRoute{ID: "it", BoardID: "b2"}
+	store, notifier := newSeededStore(board, other), &fakeNotifier{}
+	w := New(store, notifier, time.Minute)
+
+	// Опрос списка видит задачу по ExternalID, перебор ключей — по короткому ключу
+	if isNew, sent := w.Handle(Found{Board: board, Task: models.Task{ExternalID: "t1", Key: "ITS-1", Title: "Принтер"}}); !isNew || !sent {
+		t.Fatalf("expected first sighting to notify, got new=%v sent=%v", isNew, sent)
+	}
+	if isNew, sent := w.Handle(Found{Board: board, Task: models.Task{Key: "ITS-1", Title: "Принтер"}}); isNew || sent {
+		t.Fatalf("same task found by key must not notify again, got new=%v sent=%v", isNew, sent)
+	}
+	if isNew, sent := w.Handle(Found{Board: board, Task: models.Task{ExternalID: "t2", Done: true}}); !isNew || sent {
+		t.Fatalf("done task must be remembered without notification, got new=%v sent=%v", isNew, sent)
+	}
+	if len(notifier.created) != 1 || notifier.created[0].ExternalID != "t1" {
+		t.Fatalf("expected one notification for t1, got %+v", notifier.created)
+	}
+
+	// Та же задача на другой отслеживаемой доске считается отдельно
+	if isNew, _ := w.Handle(Found{Board: other, Task: models.Task{ExternalID: "t1"}}); !isNew {
+		t.Fatalf("task must be new in another board scope")
+	}
+}
+
+func TestHandleForwardsChanges(t *testing.T) {
+	store, notifier := newMemStore(), &fakeNotifier{}
+	w := New(store, notifier, time.Minute)
+	board := models.BoardRoute{BoardID: "b1", ColumnID: "c1"}
+	task := models.Task{ExternalID: "t1", ColumnID: "c1", Title: "Протечка"}
+
+	w.Handle(Found{Board: board, Task: task})
+	task.ColumnID = "c2"
+	w.Track(task)
+	if len(notifier.changes) != 1 || notifier.changes[0].Kind != models.ChangeMoved {
+		t.Fatalf("expected a move change, got %+v", notifier.changes)
+	}
+
+	// Задача без снимка через Track не отслеживается
+	w.Track(models.Task{ExternalID: "unknown", ColumnID: "c2"})
+	if _, ok := store.GetTaskSnapshot("unknown"); ok {
+		t.Fatalf("Track must not create snapshots for unknown tasks")
+	}
+}
+
+func TestRunOnceRecordsStatus(t *testing.T) {
+	board := models.BoardRoute{BoardID: "b1"}
+	store, notifier := newSeededStore(board), &fakeNotifier{}
+	w := New(store, notifier, time.Minute)
+	w.AddSource(&fakeSource{name: "list", found: []Found{
+		{Board: board, Task: models.Task{ExternalID: "t1"}},
+		{Board: board, Task: models.Task{ExternalID: "t2", Done: true}},
+	}})
+	w.AddSource(&fakeSource{name: "broken", err: errors.New("сбой")})
+
+	if err := w.RunOnce(context.Background()); err == nil || err.Error() != "сбой" {
+		t.Fatalf("expected source error, got %v", err)
+	}
+	st := w.Status()
+	if len(st.Sources) != 2 || st.Sources[1].Name != "list" {
+		t.Fatalf("unexpected status %+v", st)
+	}
+	list := st.Sources[1]
+	if list.Seen != 2 || list.New != 2 || list.Notified != 1 || list.LastErr != "" || list.Running {
+		t.Fatalf("unexpected list status %+v", list)
+	}
+	if st.Sources[0].LastErr != "сбой" {
+		t.Fatalf("expected error in status, got %+v", st.Sources[0])
+	}
+
+	w.AddSource(&fakeSource{name: "auth", err: api.ErrUnauthorized})
+	if err := w.RunOnce(context.Background()); !errors.Is(err, api.ErrUnauthorized) {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+	if len(notifier.errs) != 1 {
+		t.Fatalf("expected 401 to be reported once, got %v", notifier.errs)
+	}
+}
+
+// TestFirstScanSeedsScope проверяет, что первый обход доски только запоминает
+// задачи, а о задачах, появившихся позже, приходят уведомления.
+func TestFirstScanSeedsScope(t *testing.T) {
+	store, notifier := newMemStore(), &fakeNotifier{}
+	w := New(store, notifier, time.Minute)
+	board := models.BoardRoute{BoardID: "b1", ColumnID: "c1"}
+	src := &fakeSource{name: "list", found: []Found{
+		{Board: board, Task: models.Task{ExternalID: "t1"}},
+		{Board: board, Task: models.Task{ExternalID: "t2"}},
+	}}
+	w.AddSource(src)
+
+	// Разовое задание доску не засевает
+	if err := w.run(context.Background(), &fakeSource{name: "fullscan", found: src.found}, true); err != nil {
+		t.Fatalf("job failed: %v", err)
+	}
+	if err := w.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+	if len(notifier.created) != 0 {
+		t.Fatalf("first scan must not notify about existing tasks, got %+v", notifier.created)
+	}
+	if _, ok := store.GetTaskSnapshot("t1"); !ok || !store.IsKnownBoardKey("", "t1") {
+		t.Fatalf("first scan must remember tasks and take snapshots")
+	}
+
+	src.found = append(src.found, Found{Board: board, Task: models.Task{ExternalID: "t3"}})
+	if err := w.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+	if len(notifier.created) != 1 || notifier.created[0].ExternalID != "t3" {
+		t.Fatalf("expected a notification for the new task only, got %+v", notifier.created)
+	}
+
+	// Новая колонка той же доски тоже сначала обходится без уведомлений
+	column := models.BoardRoute{ID: "aho", BoardID: "b1", ColumnID: "c2"}
+	if _, sent := w.Handle(Found{Board: column, Task: models.Task{ExternalID: "t4"}}); sent {
+		t.Fatalf("task of an unscanned column must not notify")
+	}
+}
+
+func TestAddedBoardSeededOnFirstScan(t *testing.T) {
+	primary := models.BoardRoute{BoardID: "b1"}
+	store, notifier := newSeededStore(primary), &fakeNotifier{}
+	w := New(store, notifier, time.Minute)
+	src := &fakeSource{name: "list", found: []Found{{Board: primary, Task: models.Task{ExternalID: "t1"}}}}
+	w.AddSource(src)
+	if err := w.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+
+	// Направление, добавленное командой /addboard: открытые задачи его доски уже есть
+	added := models.BoardRoute{ID: "it", BoardID: "b2"}
+	src.found = append(src.found, Found{Board: added, Task: models.Task{ExternalID: "it-1"}}, Found{Board: added, Task: models.Task{ExternalID: "it-2"}})
+	if err := w.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+	if len(notifier.created) != 1 || notifier.created[0].ExternalID != "t1" {
+		t.Fatalf("existing tasks of an added board must not be announced, got %+v", notifier.created)
+	}
+
+	src.found = append(src.found, Found{Board: added, Task: models.Task{ExternalID: "it-3"}})
+	if err := w.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+	if len(notifier.created) != 2 || notifier.created[1].ExternalID != "it-3" {
+		t.Fatalf("expected a notification for the new task of the added board, got %+v", notifier.created)
+	}
+}
+
+func TestJobsStartAndStop(t *testing.T) {
+	w := New(newMemStore(), &fakeNotifier{}, time.Minute)
+	src := &fakeSource{name: "fullscan", block: true}
+	if err := w.StartJob(context.Background(), src); err != nil {
+		t.Fatalf("StartJob failed: %v", err)
+	}
+	if err := w.StartJob(context.Background(), src); err == nil {
+		t.Fatalf("expected second start of the same job to fail")
+	}
+	if err := w.StopJob("fullscan"); err != nil {
+		t.Fatalf("StopJob failed: %v", err)
+	}
+	if err := w.StopJob("fullscan"); err == nil {
+		t.Fatalf("expected stopping a stopped job to fail")
+	}
+	w.Stop()
+	st := w.Status()
+	if len(st.Sources) != 1 || !st.Sources[0].Job || st.Sources[0].Running || st.Sources[0].LastErr != "остановлен" {
+		t.Fatalf("unexpected job status %+v", st.Sources)
+	}
+
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if !w.Running() {
+		t.Fatalf("expected watcher to be running")
+	}
+	w.Stop()
+	if w.Running() {
+		t.Fatalf("expected watcher to be stopped")
+	}
+}
+
+// roundTripFunc позволяет подменить транспорт клиента функцией.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestKeySourceProbesKeys(t *testing.T) {
+	client := api.NewClient("token", "", time.Second, &metrics.Metrics{})
+	client.SetRetryPolicy(1, time.Millisecond, time.Second)
+	client.SetTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		status, body := http.StatusNotFound, `{}`
+		if strings.HasSuffix(req.URL.Path, "/api-v2/tasks/ITS-3") {
+			status, body = http.StatusOK, `{"id":"t3","title":"Найдена перебором","columnId":"c1"}`
+		}
+		return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body)), Header: make(http.Header), Request: req}, nil
+	}))
+
+	store := newMemStore()
+	store.last = 1
+	src := &KeySource{Client: client, Progress: store, Prefix: "ITS-", Range: 4}
+	var found []Found
+	if err := src.Scan(context.Background(), func(f Found) { found = append(found, f) }); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if len(found) != 1 || found[0].Task.ExternalID != "t3" || found[0].Task.Key != "ITS-3" {
+		t.Fatalf("expected ITS-3 to be found with its key, got %+v", found)
+	}
+	if store.last != 3 {
+		t.Fatalf("expected progress to stop at the last found key, got %d", store.last)
+	}
+}
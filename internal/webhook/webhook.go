@@ -0,0 +1,222 @@
+// Package webhook содержит приёмник событий Yougile: встроенный HTTP-сервер,
+// проверку секрета подписки и разбор событий по задачам и комментариям.
+package webhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"yougile_bot4/internal/api"
+	"yougile_bot4/internal/models"
+)
+
+const (
+	// maxBodySize ограничивает размер тела события.
+	maxBodySize = 1 << 20
+	// queueSize — число событий, ожидающих обработки; при переполнении Yougile
+	// получает 503 и повторит вызов, а пропуски восполнит сверка опросом.
+	queueSize = 256
+	// shutdownTimeout — время на завершение HTTP-сервера после отмены контекста.
+	shutdownTimeout = 5 * time.Second
+)
+
+// Kind — тип события, значимый для бота.
+type Kind int
+
+const (
+	// KindOther — событие, которое бот не обрабатывает.
+	KindOther Kind = iota
+	// KindTaskCreated — создана задача.
+	KindTaskCreated
+	// KindTaskMoved — задача перемещена в другую колонку.
+	KindTaskMoved
+	// KindTaskCompleted — задача отмечена выполненной.
+	KindTaskCompleted
+	// KindTaskCommented — в чат задачи добавлено сообщение.
+	KindTaskCommented
+)
+
+// String возвращает имя типа события для журнала.
+func (k Kind) String() string {
+	switch k {
+	case KindTaskCreated:
+		return "created"
+	case KindTaskMoved:
+		return "moved"
+	case KindTaskCompleted:
+		return "completed"
+	case KindTaskCommented:
+		return "commented"
+	}
+	return "other"
+}
+
+// Event — событие Yougile в формате {"event": "task-created", "payload": {...}}.
+type Event struct {
+	Event      string          `json:"event"`
+	FromUserID string          `json:"fromUserId,omitempty"`
+	Payload    json.RawMessage `json:"payload"`
+	PrevData   json.RawMessage `json:"prevData,omitempty"`
+}
+
+// chatPayload — поля сообщения чата из события.
+type chatPayload struct {
+	ID     int64  `json:"id"`
+	ChatID string `json:"chatId"`
+	TaskID string `json:"taskId"`
+}
+
+// Kind определяет тип события. Выполнение задачи приходит как task-updated
+// с completed=true, если до изменения задача не была выполнена.
+func (e Event) Kind() Kind {
+	switch e.Event {
+	case "task-created":
+		return KindTaskCreated
+	case "task-moved":
+		return KindTaskMoved
+	case "task-updated":
+		cur, err := api.DecodeTask(e.Payload)
+		if err != nil || !cur.Done {
+			return KindOther
+		}
+		if len(e.PrevData) > 0 {
+			if prev, err := api.DecodeTask(e.PrevData); err == nil && prev.Done {
+				return KindOther
+			}
+		}
+		return KindTaskCompleted
+	case "chat_message-created":
+		return KindTaskCommented
+	}
+	return KindOther
+}
+
+// Task возвращает задачу из события по задаче (ID Yougile — в ExternalID).
+func (e Event) Task() (models.Task, error) {
+	task, err := api.DecodeTask(e.Payload)
+	if err != nil {
+		return models.Task{}, err
+	}
+	if task.ExternalID == "" {
+		return models.Task{}, errors.New("в событии нет ID задачи")
+	}
+	return task, nil
+}
+
+// ChatID возвращает ID чата из события о сообщении (для задачи — ID задачи).
+func (e Event) ChatID() string {
+	var p chatPayload
+	if err := json.Unmarshal(e.Payload, &p); err != nil {
+		return ""
+	}
+	if p.ChatID != "" {
+		return p.ChatID
+	}
+	return p.TaskID
+}
+
+// Receiver принимает события Yougile по HTTP и передаёт их обработчику по одному.
+type Receiver struct {
+	secret string
+	events chan Event
+}
+
+// NewReceiver создаёт приёмник; secret сверяется с параметром token адреса подписки
+// (или заголовком X-Webhook-Token). Пустой secret отключает проверку.
+func NewReceiver(secret string) *Receiver {
+	return &Receiver{secret: secret, events: make(chan Event, queueSize)}
+}
+
+// ServeHTTP проверяет и разбирает событие и ставит его в очередь обработки.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if !r.authorized(req) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxBodySize+1))
+	if err != nil || len(body) > maxBodySize {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var ev Event
+	if err := json.Unmarshal(body, &ev); err != nil || ev.Event == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	select {
+	case r.events <- ev:
+		w.WriteHeader(http.StatusOK)
+	default:
+		log.Printf("webhook: очередь событий переполнена, событие %s отклонено", ev.Event)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+}
+
+// authorized сверяет секрет запроса с ожидаемым за постоянное время.
+func (r *Receiver) authorized(req *http.Request) bool {
+	if r.secret == "" {
+		return true
+	}
+	token := req.URL.Query().Get("token")
+	if token == "" {
+		token = req.Header.Get("X-Webhook-Token")
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(r.secret)) == 1
+}
+
+// Run обрабатывает события из очереди функцией handle до отмены ctx.
+func (r *Receiver) Run(ctx context.Context, handle func(Event)) {
+	for {
+		select {
+		case ev := <-r.events:
+			handle(ev)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// ListenAndServe запускает HTTP-сервер приёмника на addr и обработку событий;
+// возвращается после отмены ctx или ошибки запуска сервера.
+func (r *Receiver) ListenAndServe(ctx context.Context, addr, path string, handle func(Event)) error {
+	if path == "" {
+		path = "/"
+	}
+	mux := http.NewServeMux()
+	mux.Handle(path, r)
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go r.Run(ctx, handle)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("webhook: ошибка остановки сервера: %v", err)
+		}
+	}()
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Path возвращает путь из адреса подписки для регистрации обработчика.
+func Path(hookURL string) string {
+	u, err := url.Parse(hookURL)
+	if err != nil || u.Path == "" {
+		return "/"
+	}
+	return u.Path
+}
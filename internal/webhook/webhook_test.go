@@ -0,0 +1,81 @@
+// Package webhook содержит тесты приёмника событий Yougile.
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReceiverVerifiesSecretAndQueuesEvents(t *testing.T) {
+	r := NewReceiver("s3cret")
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	body := `{"event":"task-created","payload":{"id":"t1","title":"Протечка","columnId":"c1","timestamp":1700000000000}}`
+	resp, err := http.Post(ts.URL+"/?token=wrong", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 for wrong secret, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Post(ts.URL+"/?token=s3cret", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	got := make(chan Event, 1)
+	go r.Run(ctx, func(ev Event) { got <- ev })
+	select {
+	case ev := <-got:
+		if ev.Kind() != KindTaskCreated {
+			t.Fatalf("expected created event, got %s", ev.Kind())
+		}
+		task, err := ev.Task()
+		if err != nil {
+			t.Fatalf("Task: %v", err)
+		}
+		if task.ExternalID != "t1" || task.ColumnID != "c1" || !task.CreatedAt.Equal(time.UnixMilli(1700000000000)) {
+			t.Fatalf("unexpected task %+v", task)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event was not delivered")
+	}
+}
+
+func TestEventKinds(t *testing.T) {
+	cases := []struct {
+		raw  Event
+		want Kind
+	}{
+		{Event{Event: "task-moved", Payload: []byte(`{"id":"t1"}`)}, KindTaskMoved},
+		{Event{Event: "task-updated", Payload: []byte(`{"id":"t1","completed":true}`), PrevData: []byte(`{"completed":false}`)}, KindTaskCompleted},
+		{Event{Event: "task-updated", Payload: []byte(`{"id":"t1","completed":true}`), PrevData: []byte(`{"completed":true}`)}, KindOther},
+		{Event{Event: "task-updated", Payload: []byte(`{"id":"t1","title":"x"}`)}, KindOther},
+		{Event{Event: "chat_message-created", Payload: []byte(`{"id":1,"chatId":"t1"}`)}, KindTaskCommented},
+		{Event{Event: "board-created", Payload: []byte(`{}`)}, KindOther},
+	}
+	for _, c := range cases {
+		if got := c.raw.Kind(); got != c.want {
+			t.Errorf("%s %s: expected %s, got %s", c.raw.Event, c.raw.Payload, c.want, got)
+		}
+	}
+	if id := (Event{Payload: []byte(`{"id":1,"chatId":"t1"}`)}).ChatID(); id != "t1" {
+		t.Fatalf("expected chat t1, got %q", id)
+	}
+	if p := Path("https://bot.example.com/yougile/hook?token=x"); p != "/yougile/hook" {
+		t.Fatalf("unexpected path %q", p)
+	}
+}
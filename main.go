@@ -3,11 +3,15 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -16,7 +20,10 @@ import (
 	"yougile_bot4/internal/logger"
 	"yougile_bot4/internal/metrics"
 	"yougile_bot4/internal/models"
+	"yougile_bot4/internal/notify"
 	"yougile_bot4/internal/storage"
+	"yougile_bot4/internal/watcher"
+	"yougile_bot4/internal/webhook"
 
 	"github.com/joho/godotenv"
 )
@@ -24,6 +31,13 @@ import (
 // defaultScanRange используется при поиске пронумерованных ITS-ключей
 var defaultScanRange = 20
 
+// numericScanEnabled разрешает перебор ITS-ключей, когда список задач пуст.
+// При работающих вебхуках перебор отключается: новые задачи приходят событиями.
+var numericScanEnabled = true
+
+// webhookEvents — события Yougile, на которые подписывается бот.
+var webhookEvents = []string{"task-.*", "chat_message-created"}
+
 func init() {
 	// Загружаем переменные из .env файла
 	if err := godotenv.Load(); err != nil {
@@ -48,7 +62,8 @@ func main() {
 	defer cancel()
 
 	// Проверяем наличие всех необходимых переменных окружения
-	requiredEnvVars := []string{"YOUGILE_TOKEN", "YOUGILE_BOARD", "TELEGRAM_TOKEN"}
+	// YOUGILE_BOARD не обязателен: доску и колонку можно выбрать в боте командой /projects
+	requiredEnvVars := []string{"YOUGILE_TOKEN", "TELEGRAM_TOKEN"}
 	for _, envVar := range requiredEnvVars {
 		if os.Getenv(envVar) == "" {
 			log.Fatalf("Отсутствует обязательная переменная окружения: %s", envVar)
@@ -78,7 +93,7 @@ func main() {
 		MaxLogAge:  30 * 24 * time.Hour, // Время хранения логов: 30 дней
 
 		// Настройки работы бота
-		TasksLimit:      100,              // Максимальное количество задач для получения
+		TasksLimit:      100,              // Размер страницы при обходе списка задач
 		CheckInterval:   1 * time.Minute,  // Интервал проверки новых задач
 		SaveInterval:    5 * time.Minute,  // Интервал сохранения данных
 		MinMsgLen:       10,               // Минимальная длина сообщения
@@ -89,6 +104,10 @@ func main() {
 		RetryCount:      3,
 		RetryWait:       500 * time.Millisecond,
 		MaxRetryElapsed: 10 * time.Second,
+		RateLimit:       50, // запросов в минуту, как у Yougile API
+		// Вебхуки Yougile (включаются заданием WEBHOOK_URL)
+		WebhookListen:     ":8080",
+		ReconcileInterval: 15 * time.Minute,
 	}
 
 	// Настройка логирования
@@ -119,6 +138,27 @@ func main() {
 		metrics,
 	)
 
+	// Запись обмена с Yougile в кассету (ключ API вырезается) или ответы из
+	// записанной кассеты без обращения к сети — для разбора проблем конкретной
+	// инсталляции и превращения их в регрессионные тесты.
+	if path := os.Getenv("YOUGILE_CASSETTE_REPLAY"); path != "" {
+		cassette, err := api.LoadCassette(path)
+		if err != nil {
+			log.Fatalf("Ошибка загрузки кассеты: %v", err)
+		}
+		yougileClient.SetTransport(api.NewReplayer(cassette))
+		log.Printf("Yougile: ответы воспроизводятся из кассеты %s (%d записей)", path, len(cassette.Interactions))
+	} else if path := os.Getenv("YOUGILE_CASSETTE_RECORD"); path != "" {
+		recorder := api.NewRecorder(path, nil, config.YougileToken)
+		defer func() {
+			if err := recorder.Close(); err != nil {
+				log.Printf("Ошибка закрытия кассеты: %v", err)
+			}
+		}()
+		yougileClient.SetTransport(recorder)
+		log.Printf("Yougile: обмен с API записывается в кассету %s", path)
+	}
+
 	// Override retry policy from environment if provided
 	if rc := os.Getenv("YOUGILE_RETRY_COUNT"); rc != "" {
 		if v, err := strconv.Atoi(rc); err == nil && v > 0 {
@@ -138,6 +178,18 @@ func main() {
 
 	yougileClient.SetRetryPolicy(config.RetryCount, config.RetryWait, config.MaxRetryElapsed)
 
+	// Ограничение частоты запросов к Yougile (в минуту); 0 отключает ограничение
+	if rl := os.Getenv("YOUGILE_RATE_LIMIT"); rl != "" {
+		if v, err := strconv.Atoi(rl); err == nil && v >= 0 {
+			config.RateLimit = v
+		}
+	}
+	yougileClient.SetRateLimit(config.RateLimit)
+
+	// Подключаем хранилище возможностей API: выбранный эндпоинт списка задач
+	// сохраняется между перезапусками и переопределяется только при ошибке.
+	yougileClient.SetCapabilityStore(store)
+
 	// If COLUMN_ID is provided in env, pass it to client so GetTasks can filter by column
 	if col := os.Getenv("COLUMN_ID"); col != "" {
 		yougileClient.SetColumnID(col)
@@ -150,16 +202,30 @@ func main() {
 		}
 	}
 
+	// Дополнительные отслеживаемые доски: YOUGILE_BOARDS="it=BOARD[/COLUMN][:Название];..."
+	// Доски, уже настроенные командой /addboard, не перезаписываются.
+	if spec := os.Getenv("YOUGILE_BOARDS"); spec != "" {
+		routes, err := parseBoardRoutes(spec)
+		if err != nil {
+			log.Fatalf("Ошибка разбора YOUGILE_BOARDS: %v", err)
+		}
+		for _, route := range routes {
+			if _, ok := store.GetBoardRoute(route.ID); !ok {
+				store.SetBoardRoute(route)
+			}
+		}
+	}
+
 	// Создание и запуск бота
 	boardID := config.YougileBoard
-	if boardID == "" {
-		log.Fatalf("Неверный формат ID доски: пустой YOUGILE_BOARD")
+	if _, ok := store.GetTargetColumn(); !ok && boardID == "" {
+		log.Printf("Доска Yougile не задана: новые задачи не отслеживаются, пока администратор не выберет колонку командой /projects")
 	}
 
 	telegramBot, err := bot.NewBot(
 		config.TelegramToken,
 		store,
-		config.YougileToken,
+		yougileClient,
 		boardID,
 		config.RegTimeout,
 		config.MinMsgLen,
@@ -169,11 +235,102 @@ func main() {
 		log.Fatalf("Ошибка создания бота: %v", err)
 	}
 
-	telegramBot.Start()
+	// Сообщения об изменениях задач (перемещение, выполнение, срок) в чаты досок
+	if v := strings.ToLower(os.Getenv("YOUGILE_CHANGE_NOTIFY")); v == "1" || v == "true" {
+		telegramBot.SetChangeNotifications(true)
+	}
+
+	// Шаблоны уведомлений: файлы <имя>.tmpl заменяют встроенные тексты; файл с
+	// ошибкой не применяется, остальные работают
+	messages, err := notify.Load(notify.DefaultDir)
+	if err != nil {
+		log.Printf("Ошибка загрузки шаблонов уведомлений: %v", err)
+	}
+	telegramBot.SetMessageTemplates(messages)
+
+	// Корневой контекст передаётся боту: его отмена прерывает запросы к Yougile
+	// из обработчиков команд и fullscan, включая ожидание между повторами.
+	telegramBot.Start(ctx)
+
+	// Любой ответ 401 на запрос с ключом бота означает отозванный или неверный
+	// YOUGILE_TOKEN: администраторы получают уведомление (не чаще раза в полчаса).
+	yougileClient.SetUnauthorizedHandler(telegramBot.ReportAPIError)
+	if err := yougileClient.CheckKey(api.Background(ctx)); err != nil {
+		log.Printf("Проверка ключа Yougile при запуске: %v", err)
+	}
+
+	// background отслеживает фоновые процессы, завершения которых ждём при остановке
+	var background sync.WaitGroup
+
+	// Приём событий Yougile через вебхуки; опрос остаётся сверкой на случай пропусков
+	if config.WebhookURL = os.Getenv("WEBHOOK_URL"); config.WebhookURL != "" {
+		if l := os.Getenv("WEBHOOK_LISTEN"); l != "" {
+			config.WebhookListen = l
+		}
+		if config.WebhookSecret = os.Getenv("WEBHOOK_SECRET"); config.WebhookSecret == "" {
+			config.WebhookSecret = randomSecret()
+		}
+		if rm := os.Getenv("WEBHOOK_RECONCILE_MIN"); rm != "" {
+			if v, err := strconv.Atoi(rm); err == nil && v > 0 {
+				config.ReconcileInterval = time.Duration(v) * time.Minute
+			}
+		}
+		config.CheckInterval = config.ReconcileInterval
+		numericScanEnabled = false
+	}
+
+	// Наблюдатель за задачами: плановый опрос досок и, при пустом списке задач
+	// основной доски, перебор ITS-ключей; /rescan, /fullscan и /watcher управляют им
+	taskWatcher := watcher.New(store, telegramBot, config.CheckInterval)
+	list := &watcher.ListSource{
+		Client:    yougileClient,
+		Boards:    telegramBot.WatchedBoards,
+		PageSize:  config.TasksLimit,
+		Snapshots: store,
+	}
+	if numericScanEnabled {
+		list.Fallback = &watcher.KeySource{
+			Label:    "numeric",
+			Client:   yougileClient,
+			Progress: store,
+			Board:    telegramBot.MainBoard,
+			Prefix:   "ITS-",
+			Range:    defaultScanRange,
+		}
+	}
+	taskWatcher.AddSource(list)
+	telegramBot.SetWatcher(taskWatcher)
+
+	// Доски, опрошенные до учёта первого обхода, уже пройдены: их новые задачи
+	// сразу идут в уведомления
+	var seedKeys []string
+	for _, board := range telegramBot.WatchedBoards() {
+		seedKeys = append(seedKeys, watcher.SeedKey(board))
+	}
+	if store.AdoptSeededScopes(seedKeys) {
+		log.Printf("watcher: отслеживаемые доски отмечены пройденными: %v", seedKeys)
+	}
+
+	if config.WebhookURL != "" {
+		receiver := webhook.NewReceiver(config.WebhookSecret)
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			handle := func(ev webhook.Event) {
+				handleWebhookEvent(api.Background(ctx), yougileClient, taskWatcher, telegramBot, ev)
+			}
+			if err := receiver.ListenAndServe(ctx, config.WebhookListen, webhook.Path(config.WebhookURL), handle); err != nil {
+				log.Printf("webhook: сервер остановлен с ошибкой: %v", err)
+			}
+		}()
+		go registerWebhooks(api.Background(ctx), yougileClient, telegramBot, webhookURLWithSecret(config.WebhookURL, config.WebhookSecret))
+	}
 
 	// Периодическое сохранение данных
 	saveTicker := time.NewTicker(config.SaveInterval)
+	background.Add(1)
 	go func() {
+		defer background.Done()
 		defer saveTicker.Stop()
 		for {
 			select {
@@ -187,23 +344,10 @@ func main() {
 		}
 	}()
 
-	// Проверка новых задач
-	checkTicker := time.NewTicker(config.CheckInterval)
-	go func() {
-		defer checkTicker.Stop()
-
-		// Выполняем первую проверку сразу
-		checkNewTasks(ctx, yougileClient, store, telegramBot, config.TasksLimit)
-
-		for {
-			select {
-			case <-checkTicker.C:
-				checkNewTasks(ctx, yougileClient, store, telegramBot, config.TasksLimit)
-			case <-ctx.Done():
-				return
-			}
-		}
-	}()
+	// Первая проверка выполняется сразу, затем каждые config.CheckInterval
+	if err := taskWatcher.Start(ctx); err != nil {
+		log.Printf("Ошибка запуска наблюдателя за задачами: %v", err)
+	}
 
 	// Настройка graceful shutdown:
 	// - Создаем канал для получения сигналов ОС
@@ -216,9 +360,10 @@ func main() {
 
 	// При получении сигнала завершения:
 	// 1. Логируем начало процесса завершения
-	// 2. Отменяем контекст для остановки фоновых процессов
-	// 3. Останавливаем все сервисы
-	// 4. Сохраняем данные перед выходом
+	// 2. Отменяем контекст — запросы к Yougile и ожидания между повторами прерываются
+	// 3. Дожидаемся остановки фоновых процессов
+	// 4. Сохраняем данные и останавливаем бота
+	// Всё это ограничено config.GracefulTimeout.
 	log.Println("Получен сигнал завершения, останавливаем работу...")
 
 	// Отменяем контекст
@@ -228,207 +373,157 @@ func main() {
 	shutdownTimer := time.NewTimer(config.GracefulTimeout)
 	defer shutdownTimer.Stop()
 
-	// Канал для ожидания завершения сохранения
-	done := make(chan bool)
+	// Канал для ожидания завершения остановки
+	done := make(chan bool, 1)
 
 	go func() {
+		taskWatcher.Stop()
+		background.Wait()
 		if err := store.SaveData(); err != nil {
 			log.Printf("Ошибка сохранения данных при завершении: %v", err)
 		}
+		telegramBot.Stop()
 		done <- true
 	}()
 
-	// Ждем либо завершения сохранения, либо таймаута
+	// Ждем либо завершения остановки, либо таймаута
 	select {
 	case <-done:
-		log.Println("Данные успешно сохранены")
+		log.Println("Данные сохранены, бот остановлен")
 	case <-shutdownTimer.C:
 		log.Println("Превышено время graceful shutdown")
 	}
-
-	telegramBot.Stop()
 }
 
-// checkNewTasks проверяет новые задачи на доске Yougile и отправляет уведомления
-// - получает список задач через API
-// - проверяет, не было ли уже уведомления о задаче
-// - отправляет уведомление только о новых незавершенных задачах
-// - сохраняет ID задачи в списке известных
-func checkNewTasks(ctx context.Context, client *api.Client, store *storage.Storage, bot *bot.Bot, limit int) {
-	// Получаем список последних задач с ограничением по количеству
-	select {
-	case <-ctx.Done():
-		return
-	default:
-		tasks, err := client.GetTasks(limit)
+// handleWebhookEvent обрабатывает событие Yougile: новые и перемещённые в целевую
+// колонку задачи идут в общий путь уведомлений, выполнение задачи останавливает
+// пересылку её комментариев, а новое сообщение в чате сразу пересылается автору.
+func handleWebhookEvent(ctx context.Context, client *api.Client, w *watcher.Watcher, bot *bot.Bot, ev webhook.Event) {
+	// Изменения уже известных задач (перемещение, выполнение, срок) отслеживаются
+	// по любому событию задачи; новые задачи получают снимок при опросе
+	if strings.HasPrefix(ev.Event, "task-") {
+		if task, err := ev.Task(); err == nil {
+			w.Track(task)
+		}
+	}
+
+	kind := ev.Kind()
+	switch kind {
+	case webhook.KindTaskCreated, webhook.KindTaskMoved:
+		task, err := ev.Task()
 		if err != nil {
-			log.Printf("Ошибка получения задач: %v", err)
+			log.Printf("webhook: некорректное событие %s: %v", ev.Event, err)
 			return
 		}
-
-		colEnv := os.Getenv("COLUMN_ID")
-		log.Printf("checkNewTasks: %d задач получено", len(tasks))
-
-		// Fallback: if column-scoped fetch returned no tasks but COLUMN_ID is set,
-		// try fetching without column filter (some Yougile instances don't return tasks
-		// for column-scoped queries reliably).
-		if len(tasks) == 0 && colEnv != "" {
-			// temporarily clear columnId on client
-			client.SetColumnID("")
-			broader, berr := client.GetTasks(limit)
-			// restore column ID from env for future runs
-			client.SetColumnID(colEnv)
-			if berr == nil {
-				log.Printf("checkNewTasks: fallback %d задач", len(broader))
-				tasks = broader
-			}
-			// If still empty, trigger a numeric ITS scan in background to discover manual tasks
-			if len(broader) == 0 {
-				go scanNumericKeys(client, store, bot, defaultScanRange)
-			}
+		board, ok := watchedBoardOf(ctx, client, bot.WatchedBoards(), task)
+		if !ok {
+			return
 		}
-
-		// Проверяем каждую задачу
-		newCount := 0
-		notifyCount := 0
-		for _, task := range tasks {
-			key := ""
-			if task.ExternalID != "" {
-				key = task.ExternalID
-			} else if task.Key != "" {
-				key = task.Key
-			} else if task.ID != 0 {
-				key = fmt.Sprintf("%d", task.ID)
-			}
-			if key == "" {
-				continue
-			}
-			known := store.IsKnownKey(key)
-			if !known {
-				store.AddKnownKey(key)
-				if task.ID != 0 {
-					store.AddKnownTask(task.ID)
-				}
-				newCount++
-				if !task.Done {
-					bot.SendNotification(formatTaskNotification(task))
-					notifyCount++
-				}
-			}
+		if isNew, _ := w.Handle(watcher.Found{Board: board, Task: task}); isNew {
+			log.Printf("webhook: новая задача %s (%s)", task.ExternalID, kind)
+		}
+	case webhook.KindTaskCompleted:
+		task, err := ev.Task()
+		if err != nil {
+			log.Printf("webhook: некорректное событие %s: %v", ev.Event, err)
+			return
 		}
-		if newCount > 0 {
-			log.Printf("checkNewTasks: новых задач %d, уведомлений %d", newCount, notifyCount)
+		bot.TaskCompleted(task.ExternalID)
+	case webhook.KindTaskCommented:
+		if chatID := ev.ChatID(); chatID != "" {
+			bot.RelayTaskComments(chatID)
 		}
 	}
-
-	// formatTaskNotification формирует текст уведомления о новой задаче
-	// Включает в уведомление:
-	// - Статус задачи (✅ - завершена, 🔵 - активна)
-	// - Название задачи
-	// - Приоритет (⚡️ - высокий, ⭐️ - средний, 📌 - обычный)
-	// - Срок выполнения (если установлен)
-	// - Исполнителя (если назначен)
-	// - Описание задачи (первые 200 символов)
 }
 
-func formatTaskNotification(task models.Task) string {
-	var status, priority string
-
-	// Определяем эмодзи статуса задачи
-	if task.Done {
-		status = "✅" // Задача завершена
-	} else {
-		status = "🔵" // Задача активна
-	}
-
-	// Определяем приоритет задачи и соответствующий эмодзи
-	switch task.Priority {
-	case 1:
-		priority = "⚡️ Высокий" // Высокий приоритет
-	case 2:
-		priority = "⭐️ Средний" // Средний приоритет
-	default:
-		priority = "📌 Обычный" // Обычный приоритет (или не указан)
-	}
-
-	// Добавляем информацию о сроке выполнения, если он установлен
-	var dueDate string
-	if !task.DueDate.IsZero() {
-		dueDate = fmt.Sprintf("\n📅 Срок: %s", task.DueDate.Format("02.01.2006"))
-	}
-
-	// Добавляем информацию об исполнителе, если он назначен
-	var assignee string
-	if task.Assignee != "" {
-		assignee = fmt.Sprintf("\n👤 Исполнитель: %s", task.Assignee)
-	}
-
-	// Формируем основной текст уведомления:
-	// - Статус и тип (новая задача)
-	// - Название задачи
-	// - Приоритет
-	// - Срок (если есть)
-	// - Исполнитель (если назначен)
-	msg := fmt.Sprintf("%s Новая задача\n"+
-		"📎 %s\n"+
-		"🏷 %s%s%s",
-		status, task.Title, priority, dueDate, assignee)
-
-	// Добавляем описание задачи, если оно есть
-	// Ограничиваем длину описания 200 символами для читаемости
-	if task.Description != "" {
-		descLen := len(task.Description)
-		if descLen > 200 {
-			descLen = 200
+// watchedBoardOf находит отслеживаемую доску, в колонке которой находится задача
+// (или в любой колонке доски, если колонка для неё не выбрана).
+func watchedBoardOf(ctx context.Context, client *api.Client, boards []models.BoardRoute, task models.Task) (models.BoardRoute, bool) {
+	if task.ColumnID == "" {
+		return models.BoardRoute{}, false
+	}
+	for _, board := range boards {
+		if board.ColumnID != "" && task.ColumnID == board.ColumnID {
+			return board, true
+		}
+	}
+	for _, board := range boards {
+		if board.ColumnID != "" || (task.BoardID != "" && task.BoardID != board.BoardID) {
+			continue
+		}
+		columns, err := client.ListColumns(ctx, board.BoardID)
+		if err != nil {
+			log.Printf("webhook: ошибка получения колонок доски %s: %v", board.BoardID, err)
+			continue
+		}
+		for _, col := range columns {
+			if col.ID == task.ColumnID {
+				return board, true
+			}
 		}
-		msg += fmt.Sprintf("\n\n📝 %s", task.Description[:descLen])
-		if len(task.Description) > 200 {
-			msg += "..." // Добавляем многоточие, если описание было обрезано
+	}
+	return models.BoardRoute{}, false
+}
+
+// registerWebhooks подписывает адрес hookURL на события бота. Ошибка не прерывает
+// работу: новые задачи продолжают находиться сверкой опросом.
+func registerWebhooks(ctx context.Context, client *api.Client, bot *bot.Bot, hookURL string) {
+	for _, event := range webhookEvents {
+		id, err := client.EnsureWebhook(ctx, hookURL, event)
+		if err != nil {
+			log.Printf("webhook: не удалось подписаться на %s: %v", event, err)
+			bot.ReportAPIError(err)
+			continue
 		}
+		log.Printf("webhook: подписка на %s активна (%s)", event, id)
+	}
+}
+
+// webhookURLWithSecret добавляет секрет в адрес подписки параметром token.
+func webhookURLWithSecret(hookURL, secret string) string {
+	sep := "?"
+	if strings.Contains(hookURL, "?") {
+		sep = "&"
 	}
+	return hookURL + sep + "token=" + secret
+}
 
-	return msg
+// randomSecret возвращает случайный секрет подписки, если он не задан в окружении.
+func randomSecret() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		log.Fatalf("Ошибка генерации секрета вебхука: %v", err)
+	}
+	return hex.EncodeToString(buf)
 }
 
-// scanNumericKeys выполняет быстрый пробег по пронумерованным коротким ключам ITS-N
-// начиная с последнего сохранённого в хранилище значения. Находит задачи через GetTaskByID
-// и уведомляет админов при обнаружении новых.
-func scanNumericKeys(client *api.Client, store *storage.Storage, bot *bot.Bot, rng int) {
-	last := store.GetLastScanned()
-	if last < 0 {
-		last = 0
-	}
-	maxScan := last + rng
-	log.Printf("scanNumericKeys: сканирование ITS-%d..ITS-%d", last+1, maxScan)
-	found := 0
-	notified := 0
-	for n := last + 1; n <= maxScan; n++ {
-		key := fmt.Sprintf("ITS-%d", n)
-		t, err := client.GetTaskByIDQuiet(key)
-		if err != nil || t == nil {
+// parseBoardRoutes разбирает список досок вида "id=BOARD[/COLUMN][:Название]",
+// разделённых точкой с запятой.
+func parseBoardRoutes(spec string) ([]models.BoardRoute, error) {
+	var routes []models.BoardRoute
+	for _, item := range strings.Split(spec, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
 			continue
 		}
-		taskKey := key
-		if t.Key != "" {
-			taskKey = t.Key
-		} else if t.ExternalID != "" {
-			taskKey = t.ExternalID
+		id, rest, ok := strings.Cut(item, "=")
+		id = strings.TrimSpace(id)
+		if !ok || id == "" {
+			return nil, fmt.Errorf("ожидается id=BOARD[/COLUMN][:Название], получено %q", item)
 		}
-		known := store.IsKnownKey(taskKey)
-		if !known {
-			store.AddKnownKey(taskKey)
-			if t.ID != 0 {
-				store.AddKnownTask(t.ID)
-			}
-			found++
-			if !t.Done {
-				bot.SendNotification(formatTaskNotification(*t))
-				notified++
-			}
+		if id == "-" || strings.ContainsAny(id, "|:") {
+			return nil, fmt.Errorf("недопустимое имя доски %q", id)
 		}
-		store.SetLastScanned(n)
-	}
-	if found > 0 {
-		log.Printf("scanNumericKeys: найдено новых задач %d, уведомлений %d", found, notified)
+		target, title, _ := strings.Cut(rest, ":")
+		boardID, columnID, _ := strings.Cut(strings.TrimSpace(target), "/")
+		if boardID == "" {
+			return nil, fmt.Errorf("не указана доска для %q", id)
+		}
+		title = strings.TrimSpace(title)
+		if title == "" {
+			title = id
+		}
+		routes = append(routes, models.BoardRoute{ID: id, Title: title, BoardID: boardID, ColumnID: columnID})
 	}
+	return routes, nil
 }
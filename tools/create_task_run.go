@@ -3,8 +3,10 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"time"
 
 	"yougile_bot4/internal/api"
@@ -19,6 +21,10 @@ func main() {
 		os.Exit(2)
 	}
 
+	// Ctrl+C прерывает запрос, в том числе ожидание между повторами
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
 	client := api.NewClient(token, board, 30*time.Second, nil)
 
 	// optional COLUMN_ID environment variable
@@ -34,7 +40,7 @@ func main() {
 	}
 	// ASSIGNED env is ignored for task creation per request
 
-	if err := client.CreateTask(t); err != nil {
+	if err := client.CreateTask(ctx, t); err != nil {
 		fmt.Printf("CreateTask failed: %v\n", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,152 @@
+// Command yougile_key помогает администратору выпустить ключ API Yougile для бота
+// по логину и паролю: выбрать компанию, посмотреть или отозвать существующие
+// ключи и создать новый ключ для YOUGILE_TOKEN.
+//
+// Логин и пароль берутся из YOUGILE_LOGIN и YOUGILE_PASSWORD или запрашиваются
+// в терминале; пароль при вводе не отображается.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"yougile_bot4/internal/api"
+	"yougile_bot4/internal/models"
+
+	"golang.org/x/term"
+)
+
+func main() {
+	company := flag.String("company", "", "название компании (по умолчанию — выбор из списка)")
+	list := flag.Bool("list", false, "только показать действующие ключи компании")
+	revoke := flag.String("revoke", "", "отозвать указанный ключ")
+	flag.Parse()
+
+	// Ctrl+C прерывает запрос, в том числе ожидание между повторами
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client := api.NewClient("", "", 30*time.Second, nil)
+	in := bufio.NewReader(os.Stdin)
+
+	if *revoke != "" {
+		if err := client.DeleteAuthKey(ctx, *revoke); err != nil {
+			fail(err)
+		}
+		fmt.Println("Ключ отозван.")
+		return
+	}
+
+	cr := api.Credentials{
+		Login:    envOrPrompt(in, "YOUGILE_LOGIN", "Логин Yougile: "),
+		Password: envOrPassword(in, "YOUGILE_PASSWORD", "Пароль: "),
+	}
+	companies, err := client.ListCompanies(ctx, cr, *company)
+	if err != nil {
+		fail(err)
+	}
+	chosen, err := chooseCompany(in, companies)
+	if err != nil {
+		fail(err)
+	}
+
+	keys, err := client.ListAuthKeys(ctx, cr, chosen.ID)
+	if err != nil {
+		fail(err)
+	}
+	fmt.Printf("Действующих ключей в компании «%s»: %d\n", chosen.Name, len(keys))
+	for _, k := range keys {
+		created := time.UnixMilli(int64(k.Timestamp)).Format("02.01.2006 15:04")
+		fmt.Printf("  %s  (создан %s)\n", k.Key, created)
+	}
+	if *list {
+		return
+	}
+	if len(keys) > 0 && !confirm(in, "Создать ещё один ключ? [y/N]: ") {
+		return
+	}
+
+	key, err := client.CreateAuthKey(ctx, cr, chosen.ID)
+	if err != nil {
+		fail(err)
+	}
+	fmt.Println("Новый ключ создан. Добавьте его в .env бота:")
+	fmt.Printf("YOUGILE_TOKEN=%s\n", key)
+	fmt.Println("Старый ключ, если он больше не нужен, отзовите: -revoke <ключ>.")
+}
+
+// chooseCompany выбирает компанию: единственную — сразу, иначе по номеру из списка.
+func chooseCompany(in *bufio.Reader, companies []models.Company) (models.Company, error) {
+	switch len(companies) {
+	case 0:
+		return models.Company{}, fmt.Errorf("компании не найдены")
+	case 1:
+		return companies[0], nil
+	}
+	for i, c := range companies {
+		admin := ""
+		if c.IsAdmin {
+			admin = " (администратор)"
+		}
+		fmt.Printf("%d. %s%s\n", i+1, c.Name, admin)
+	}
+	n, err := strconv.Atoi(prompt(in, "Номер компании: "))
+	if err != nil || n < 1 || n > len(companies) {
+		return models.Company{}, fmt.Errorf("неверный номер компании")
+	}
+	return companies[n-1], nil
+}
+
+// envOrPrompt возвращает значение переменной окружения name или запрашивает его.
+func envOrPrompt(in *bufio.Reader, name, question string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return prompt(in, question)
+}
+
+// envOrPassword возвращает значение переменной окружения name или запрашивает
+// его без отображения вводимых символов. Если ввод не из терминала (например,
+// передан через канал), строка читается как обычно.
+func envOrPassword(in *bufio.Reader, name, question string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return prompt(in, question)
+	}
+	fmt.Print(question)
+	password, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		fail(fmt.Errorf("ошибка чтения пароля: %w", err))
+	}
+	return strings.TrimSpace(string(password))
+}
+
+// prompt выводит вопрос и читает строку ответа.
+func prompt(in *bufio.Reader, question string) string {
+	fmt.Print(question)
+	line, _ := in.ReadString('\n')
+	return strings.TrimSpace(line)
+}
+
+// confirm задаёт вопрос с ответом да/нет.
+func confirm(in *bufio.Reader, question string) bool {
+	answer := strings.ToLower(prompt(in, question))
+	return answer == "y" || answer == "yes" || answer == "д" || answer == "да"
+}
+
+// fail печатает ошибку в stderr и завершает программу.
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "Ошибка: %v\n", err)
+	os.Exit(1)
+}